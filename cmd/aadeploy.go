@@ -18,8 +18,8 @@ var aaDeployCmd = &cobra.Command{
 
 		InitGlobalClient(globalOptNodeUrl)
 
-		if globalOptPrivateKey == "" {
-			log.Fatalf("--private-key is required for this command")
+		if !hasSigner() {
+			log.Fatalf("%s is required for this command", signerOptions)
 		}
 
 		// Check if SingletonFactory (EIP2470) contract deployed
@@ -72,7 +72,7 @@ func deployAASimpleAccountFactory() error {
 
 	log.Printf("deploying AA simple account factory contract")
 	contract := singletonFactoryAddr
-	tx, err := Transact(globalClient.RpcClient, globalClient.EthClient, hexToPrivateKey(globalOptPrivateKey), &contract, big.NewInt(0), nil, txInputData)
+	tx, err := Transact(globalClient.RpcClient, globalClient.EthClient, loadSigner(), &contract, big.NewInt(0), nil, txInputData)
 	if err != nil {
 		return err
 	}
@@ -114,7 +114,7 @@ func deployAASimpleAccount(accountOwner string) error {
 
 	log.Printf("deploying AA account contract")
 	contract := getAASimpleAccountFactoryAddress()
-	tx, err := Transact(globalClient.RpcClient, globalClient.EthClient, hexToPrivateKey(globalOptPrivateKey), &contract, big.NewInt(0), nil, txInputData)
+	tx, err := Transact(globalClient.RpcClient, globalClient.EthClient, loadSigner(), &contract, big.NewInt(0), nil, txInputData)
 	if err != nil {
 		return err
 	}
@@ -7,6 +7,7 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/spf13/cobra"
 	"github.com/stackup-wallet/stackup-bundler/pkg/userop"
+	"log"
 	"math/big"
 	"os"
 )
@@ -33,7 +34,7 @@ var aaSimpleAccountCmd = &cobra.Command{
 }
 
 func init() {
-	aaSimpleAccountCmd.PersistentFlags().StringVarP(&aaOwnerPrivateKey, "owner-private-key", "", "", "The private key of owner of AA simple account contract")
+	aaSimpleAccountCmd.PersistentFlags().StringVarP(&aaOwnerPrivateKey, "owner-private-key", "", "", "The private key of owner of AA simple account contract, the global signer (e.g. --private-key) is used if not given")
 	aaSimpleAccountCmd.PersistentFlags().StringVarP(&aaSender, "aa-sender", "", "", "The field sender in User Operation")
 	aaSimpleAccountCmd.PersistentFlags().StringVarP(&aaNonce, "aa-nonce", "", "", "The field nonce in User Operation")
 	aaSimpleAccountCmd.PersistentFlags().StringVarP(&aaInitCode, "aa-init-code", "", "", "The field initCode in User Operation")
@@ -45,6 +46,17 @@ func init() {
 	aaSimpleAccountCmd.PersistentFlags().StringVarP(&aaPaymasterAndData, "aa-paymaster-and-data", "", "", "The field paymasterAndData in User Operation")
 }
 
+// aaOwnerSigner returns the signer of --owner-private-key, or the global signer
+func aaOwnerSigner() Signer {
+	if aaOwnerPrivateKey != "" {
+		return newKeySigner(hexToPrivateKey(aaOwnerPrivateKey))
+	}
+	if !hasSigner() {
+		log.Fatalf("--owner-private-key or %s is required for this command", signerOptions)
+	}
+	return loadSigner()
+}
+
 func buildUserOpForEstimateGas(callData []byte) (userop.UserOperation, error) {
 	var err error
 	var uo = userop.UserOperation{
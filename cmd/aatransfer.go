@@ -13,7 +13,7 @@ var aaTransferUnit string
 func init() {
 	aaSimpleAccountCmd.AddCommand(aaTransferCmd)
 
-	aaTransferCmd.Flags().StringVarP(&aaTransferUnit, "unit", "u", "ether", "wei | gwei | ether, unit of amount")
+	aaTransferCmd.Flags().StringVarP(&aaTransferUnit, "unit", "u", "ether", "wei | gwei | ether, unit of amount, ether means the native currency of chain (its symbol is also accepted, e.g. POL)")
 }
 
 // aaTransferCmd represents the AA Simple Account transfer command
@@ -22,21 +22,18 @@ var aaTransferCmd = &cobra.Command{
 	Short: "Transfer AMOUNT of eth from AA-ACCOUNT-CONTRACT to TARGET-ADDRESS",
 	Args:  cobra.MinimumNArgs(2),
 	Run: func(cmd *cobra.Command, args []string) {
-		if aaOwnerPrivateKey == "" {
-			log.Fatalf("--owner-private-key is required for this command")
-		}
-		ownerPrivateKey := hexToPrivateKey(aaOwnerPrivateKey)
+		ownerSigner := aaOwnerSigner()
 
 		targetAddress := args[0]
 		transferAmt := args[1]
 		if !isValidEthAddress(targetAddress) {
 			log.Fatalf("%v is not a valid eth address", targetAddress)
 		}
+		InitGlobalClient(globalOptNodeUrl)
+
 		amount := decimal.RequireFromString(transferAmt)
 		amountInWei := unify2Wei(amount, aaTransferUnit)
 
-		InitGlobalClient(globalOptNodeUrl)
-
 		funcSignature := "function execute(address dest, uint256 value, bytes calldata func)"
 		inputArgData := []string{
 			targetAddress,
@@ -92,7 +89,7 @@ var aaTransferCmd = &cobra.Command{
 		userOpHash := uo.GetUserOpHash(aaEntryPoint, chainID)
 		log.Printf("userOpHash: %s", userOpHash)
 
-		sig, err := personalSign(userOpHash.Bytes(), ownerPrivateKey)
+		sig, err := personalSign(userOpHash.Bytes(), ownerSigner)
 		checkErr(err)
 		uo.Signature = sig
 
@@ -3,13 +3,20 @@ package cmd
 import (
 	"bufio"
 	"context"
+	"errors"
 	"fmt"
 	"log"
 	"math/big"
+	"net/http"
 	"os"
+	"regexp"
 	"sort"
+	"sync"
+	"time"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
 	"github.com/spf13/cobra"
 )
 
@@ -18,6 +25,10 @@ var balanceUnit string
 var balanceInputFile string
 var balanceOnlyOutputWhenPositive bool
 var balanceAddressesBatchNumber int64
+var balanceRpcBatchSize int
+var balanceConcurrency int
+var balanceNoMulticall bool
+var balanceTokens []string
 
 const sortNo = "no"
 const sortAsc = "asc"
@@ -29,10 +40,14 @@ const unitEther = "ether"
 
 func init() {
 	balanceCmd.Flags().StringVarP(&balanceSortOpt, "sort", "s", "no", "no | asc | desc, sort result")
-	balanceCmd.Flags().StringVarP(&balanceUnit, "unit", "u", "ether", "wei | gwei | ether, unit of balance")
+	balanceCmd.Flags().StringVarP(&balanceUnit, "unit", "u", "ether", "wei | gwei | ether, unit of balance, ether means the native currency of chain (its symbol is also accepted, e.g. POL)")
 	balanceCmd.Flags().StringVarP(&balanceInputFile, "input-file", "f", "", "read address from this file, file - means read stdin")
 	balanceCmd.Flags().BoolVarP(&balanceOnlyOutputWhenPositive, "only-positive", "", false, "only output addresses with positive balance")
 	balanceCmd.Flags().Int64VarP(&balanceAddressesBatchNumber, "batch", "", 10000, "the batch number when constructing Multicall arguments")
+	balanceCmd.Flags().IntVarP(&balanceRpcBatchSize, "rpc-batch", "", 100, "the number of eth_getBalance in one json-rpc batch request, it's used if Multicall is not deployed or --no-multicall is specified. The batch is split automatically if node rejects it")
+	balanceCmd.Flags().IntVarP(&balanceConcurrency, "concurrency", "", 4, "the number of concurrent json-rpc batch requests")
+	balanceCmd.Flags().BoolVarP(&balanceNoMulticall, "no-multicall", "", false, "query balance by json-rpc batch requests even if Multicall is deployed, e.g. the gas cap of eth_call in node rejects large Multicall batches")
+	balanceCmd.Flags().StringSliceVarP(&balanceTokens, "token", "", nil, "the ERC20 token contract addresses (comma separated), balances of tokens instead of native currency are queried and --unit is ignored. The default --batch is 1000 for tokens")
 }
 
 func validationBalanceCmdOpts() bool {
@@ -42,16 +57,37 @@ func validationBalanceCmdOpts() bool {
 		return false
 	}
 
-	if !contains([]string{unitWei, unitGwei, unitEther}, balanceUnit) {
+	if !isValidUnit(balanceUnit) {
 		log.Printf("invalid option for --unit: %v", balanceUnit)
 		return false
 	}
 
+	if balanceAddressesBatchNumber <= 0 || balanceRpcBatchSize <= 0 || balanceConcurrency <= 0 {
+		log.Printf("--batch, --rpc-batch and --concurrency must be positive")
+		return false
+	}
+
+	for _, token := range balanceTokens {
+		if !isValidEthAddress(token) {
+			log.Printf("invalid token address for --token: %v", token)
+			return false
+		}
+	}
+
 	return true
 }
 
 var addresses []string
 
+// BalanceOutput is the json output (--output json) of balance command, an array of BalanceOutput is printed
+type BalanceOutput struct {
+	Address    string `json:"address"`
+	Token      string `json:"token,omitempty"` // only for --token
+	Balance    string `json:"balance"`         // in unit specified by --unit, or in token unit for --token
+	Unit       string `json:"unit"`            // wei, gwei or symbol of native currency (e.g. ETH, POL), or symbol of token
+	BalanceWei string `json:"balanceWei"`      // in smallest unit of token for --token
+}
+
 var balanceCmd = &cobra.Command{
 	Use:   "balance <eth-address1> <eth-address2> ...",
 	Short: "Check eth balance for address",
@@ -92,14 +128,18 @@ var balanceCmd = &cobra.Command{
 		return nil
 	},
 	Run: func(cmd *cobra.Command, args []string) {
+		// the symbol of native currency may be known only after connected, so validate options after it
+		InitGlobalClient(globalOptNodeUrl)
+
 		if !validationBalanceCmdOpts() {
 			_ = cmd.Help()
 			os.Exit(1)
 		}
 
-		InitGlobalClient(globalOptNodeUrl)
-
-		ctx := context.Background()
+		if len(balanceTokens) > 0 {
+			queryAndPrintTokenBalances(cmd)
+			return
+		}
 
 		type kv struct {
 			addr    string
@@ -109,53 +149,39 @@ var balanceCmd = &cobra.Command{
 		var results []kv
 		var earlierOutput = false
 
-		if isMulticallDeployed(globalClient.EthClient) {
-			for i := 0; i < len(addresses); i += int(balanceAddressesBatchNumber) {
-				end := i + int(balanceAddressesBatchNumber)
-				if end > len(addresses) {
-					end = len(addresses)
-				}
-				batch := addresses[i:end]
-
-				balances, err := queryEthBalancesByMulticall(batch)
-				checkErr(err)
-
-				for index, balance := range balances {
-					addr := batch[index]
-
-					results = append(results, kv{addr, *balance})
-
-					// print output immediately if no sort demand
-					if balanceSortOpt == sortNo {
-						earlierOutput = true
-
-						if balanceOnlyOutputWhenPositive && balance.Sign() <= 0 {
-							// skip output when balance is zero or negative
-							continue
-						}
-						var output string
-						if globalOptTerseOutput {
-							output = fmt.Sprintf("%v %s\n", addr, wei2Other(bigIntToDecimal(balance), balanceUnit).String())
-						} else {
-							output = fmt.Sprintf("addr %v, balance %s %s\n", addr, wei2Other(bigIntToDecimal(balance), balanceUnit).String(), balanceUnit)
-						}
-						fmt.Print(output)
-					}
-				}
-			}
+		// the addresses are queried group by group, the balances of a group are printed once it's finished
+		var groupSize int
+		var queryBalances func(group []string) ([]*big.Int, error)
+		if !balanceNoMulticall && isMulticallDeployed(globalClient.EthClient) {
+			groupSize = int(balanceAddressesBatchNumber)
+			queryBalances = queryEthBalancesByMulticall
 		} else {
-			if len(addresses) > 1 {
-				log.Printf("Multicall contract is not deployed on chain %s, query balance one by one", globalChainId)
+			if !balanceNoMulticall && len(addresses) > 1 {
+				log.Printf("Multicall contract is not deployed on chain %s, query balance by json-rpc batch requests", globalChainId)
 			}
-			for _, addr := range addresses {
-				// check balance one by one
-				balance, err := globalClient.EthClient.BalanceAt(ctx, common.HexToAddress(addr), nil)
-				checkErr(err)
+			groupSize = balanceRpcBatchSize * balanceConcurrency
+			queryBalances = func(group []string) ([]*big.Int, error) {
+				return queryEthBalancesByRpcBatch(globalClient.RpcClient, group, balanceRpcBatchSize, balanceConcurrency)
+			}
+		}
+
+		for i := 0; i < len(addresses); i += groupSize {
+			end := i + groupSize
+			if end > len(addresses) {
+				end = len(addresses)
+			}
+			group := addresses[i:end]
+
+			balances, err := queryBalances(group)
+			checkErr(err)
+
+			for index, balance := range balances {
+				addr := group[index]
 
 				results = append(results, kv{addr, *balance})
 
 				// print output immediately if no sort demand
-				if balanceSortOpt == sortNo {
+				if balanceSortOpt == sortNo && !isJsonOutput() {
 					earlierOutput = true
 
 					if balanceOnlyOutputWhenPositive && balance.Sign() <= 0 {
@@ -166,7 +192,7 @@ var balanceCmd = &cobra.Command{
 					if globalOptTerseOutput {
 						output = fmt.Sprintf("%v %s\n", addr, wei2Other(bigIntToDecimal(balance), balanceUnit).String())
 					} else {
-						output = fmt.Sprintf("addr %v, balance %s %s\n", addr, wei2Other(bigIntToDecimal(balance), balanceUnit).String(), balanceUnit)
+						output = fmt.Sprintf("addr %v, balance %s %s\n", addr, wei2Other(bigIntToDecimal(balance), balanceUnit).String(), unitDisplayName(balanceUnit))
 					}
 					fmt.Print(output)
 				}
@@ -183,6 +209,23 @@ var balanceCmd = &cobra.Command{
 			})
 		}
 
+		if isJsonOutput() {
+			var balances = make([]BalanceOutput, 0, len(results))
+			for _, result := range results {
+				if balanceOnlyOutputWhenPositive && result.balance.Sign() <= 0 {
+					continue
+				}
+				balances = append(balances, BalanceOutput{
+					Address:    result.addr,
+					Balance:    wei2Other(bigIntToDecimal(&result.balance), balanceUnit).String(),
+					Unit:       unitDisplayName(balanceUnit),
+					BalanceWei: result.balance.String(),
+				})
+			}
+			printJson(balances)
+			return
+		}
+
 		if !earlierOutput {
 			for _, result := range results {
 				if balanceOnlyOutputWhenPositive && result.balance.Sign() <= 0 {
@@ -193,10 +236,140 @@ var balanceCmd = &cobra.Command{
 				if globalOptTerseOutput {
 					output = fmt.Sprintf("%v %s\n", result.addr, wei2Other(bigIntToDecimal(&result.balance), balanceUnit).String())
 				} else {
-					output = fmt.Sprintf("addr %v, balance %s %s\n", result.addr, wei2Other(bigIntToDecimal(&result.balance), balanceUnit).String(), balanceUnit)
+					output = fmt.Sprintf("addr %v, balance %s %s\n", result.addr, wei2Other(bigIntToDecimal(&result.balance), balanceUnit).String(), unitDisplayName(balanceUnit))
 				}
 				fmt.Print(output)
 			}
 		}
 	},
 }
+
+// Retry policy of failed eth_getBalance in json-rpc batch request
+const rpcBatchMaxRetries = 3
+
+var rpcBatchRetryDelay = time.Second
+
+// rpcBatchTooLargeRE matches the errors of json-rpc batch request rejected by node for its size
+var rpcBatchTooLargeRE = regexp.MustCompile(`(?i)batch (is )?too large|batch (size|limit)|max(imum)? batch|too many (requests|calls|items) in (a |the )?batch|response too large|request entity too large`)
+
+// isRpcBatchTooLargeError returns true if err means the json-rpc batch request is too large for node, e.g. HTTP 413
+func isRpcBatchTooLargeError(err error) bool {
+	var httpErr rpc.HTTPError
+	if errors.As(err, &httpErr) && httpErr.StatusCode == http.StatusRequestEntityTooLarge {
+		return true
+	}
+	return rpcBatchTooLargeRE.MatchString(err.Error())
+}
+
+// queryEthBalancesByRpcBatch queries balances by json-rpc batch requests of eth_getBalance, batchSize addresses
+// in a request, and at most concurrency requests are sent at the same time
+func queryEthBalancesByRpcBatch(rpcClient *rpc.Client, addresses []string, batchSize int, concurrency int) ([]*big.Int, error) {
+	var balances = make([]*big.Int, len(addresses))
+	var errs = make([]error, len(addresses))
+	var sem = make(chan struct{}, concurrency)
+	var wg sync.WaitGroup
+	for i := 0; i < len(addresses); i += batchSize {
+		end := i + batchSize
+		if end > len(addresses) {
+			end = len(addresses)
+		}
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(begin, end int) {
+			defer wg.Done()
+			defer func() { <-sem }()
+
+			batchBalances, err := getBalancesByRpcBatch(rpcClient, addresses[begin:end])
+			if err != nil {
+				errs[begin] = err
+				return
+			}
+			copy(balances[begin:end], batchBalances)
+		}(i, end)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		if err != nil {
+			return nil, err
+		}
+	}
+	return balances, nil
+}
+
+// getBalancesByRpcBatch queries balances in one json-rpc batch request, the failed items (or the whole request) are
+// retried with backoff. If node rejects the batch for its size, it's split into two halves.
+func getBalancesByRpcBatch(rpcClient *rpc.Client, addresses []string) ([]*big.Int, error) {
+	var balances = make([]*big.Int, len(addresses))
+	var pending []int // index of addresses not queried yet
+	for i := range addresses {
+		pending = append(pending, i)
+	}
+
+	var lastErr error
+	for retry := 0; retry <= rpcBatchMaxRetries; retry++ {
+		if retry > 0 {
+			log.Printf("eth_getBalance of %d addresses failed (%v), retry after %v", len(pending), lastErr, rpcBatchRetryDelay*time.Duration(retry))
+			time.Sleep(rpcBatchRetryDelay * time.Duration(retry))
+		}
+
+		var batch = make([]rpc.BatchElem, len(pending))
+		var results = make([]hexutil.Big, len(pending))
+		for i, index := range pending {
+			batch[i] = rpc.BatchElem{
+				Method: "eth_getBalance",
+				Args:   []interface{}{common.HexToAddress(addresses[index]), blockArg()},
+				Result: &results[i],
+			}
+		}
+
+		if err := rpcClient.BatchCallContext(context.Background(), batch); err != nil {
+			lastErr = err
+			if len(pending) > 1 && isRpcBatchTooLargeError(err) {
+				return splitBalancesRpcBatch(rpcClient, addresses, balances, pending)
+			}
+			continue
+		}
+
+		var failed []int
+		var tooLarge bool
+		for i, elem := range batch {
+			if elem.Error != nil {
+				lastErr = elem.Error
+				tooLarge = tooLarge || isRpcBatchTooLargeError(elem.Error)
+				failed = append(failed, pending[i])
+				continue
+			}
+			balances[pending[i]] = results[i].ToInt()
+		}
+		if tooLarge && len(pending) > 1 {
+			// the node rejects the batch as a whole (e.g. geth returns "batch too large" for the first item)
+			return splitBalancesRpcBatch(rpcClient, addresses, balances, pending)
+		}
+		pending = failed
+		if len(pending) == 0 {
+			return balances, nil
+		}
+	}
+	return nil, fmt.Errorf("eth_getBalance fail after %d retries: %w", rpcBatchMaxRetries, lastErr)
+}
+
+// splitBalancesRpcBatch queries the pending addresses by two smaller batches, the balances are filled into balances
+func splitBalancesRpcBatch(rpcClient *rpc.Client, addresses []string, balances []*big.Int, pending []int) ([]*big.Int, error) {
+	half := len(pending) / 2
+	log.Printf("json-rpc batch request of %d eth_getBalance failed, split it into %d and %d", len(pending), half, len(pending)-half)
+	for _, part := range [][]int{pending[:half], pending[half:]} {
+		var partAddresses []string
+		for _, index := range part {
+			partAddresses = append(partAddresses, addresses[index])
+		}
+		partBalances, err := getBalancesByRpcBatch(rpcClient, partAddresses)
+		if err != nil {
+			return nil, err
+		}
+		for i, index := range part {
+			balances[index] = partBalances[i]
+		}
+	}
+	return balances, nil
+}
@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"fmt"
+	"math/big"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// flakyBalanceEth is a stub of eth namespace, the balance of address is its last byte,
+// eth_getBalance of the addresses in failOnce fails on the first request
+type flakyBalanceEth struct {
+	mu       sync.Mutex
+	failOnce map[common.Address]bool
+}
+
+func (s *flakyBalanceEth) GetBalance(addr common.Address, block string) (*hexutil.Big, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.failOnce[addr] {
+		delete(s.failOnce, addr)
+		return nil, fmt.Errorf("header not found")
+	}
+	return (*hexutil.Big)(big.NewInt(int64(addr[19]))), nil
+}
+
+func TestQueryEthBalancesByRpcBatch(t *testing.T) {
+	rpcBatchRetryDelay = time.Millisecond
+	defer func() { rpcBatchRetryDelay = time.Second }()
+
+	var addresses []string
+	for i := 1; i <= 30; i++ {
+		addresses = append(addresses, common.BigToAddress(big.NewInt(int64(i))).Hex())
+	}
+	stub := &flakyBalanceEth{failOnce: map[common.Address]bool{
+		common.HexToAddress(addresses[2]):  true,
+		common.HexToAddress(addresses[17]): true,
+	}}
+
+	server, url := newStubRpcServer(t, map[string]any{"eth": stub})
+	server.SetBatchLimits(4, 1024*1024) // batch of 8 is rejected by server, it will be split
+	rpcClient, err := rpc.Dial(url)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	defer rpcClient.Close()
+
+	balances, err := queryEthBalancesByRpcBatch(rpcClient, addresses, 8, 3)
+	if err != nil {
+		t.Fatalf("queryEthBalancesByRpcBatch failed: %v", err)
+	}
+	for i, balance := range balances {
+		if balance.Int64() != int64(i+1) {
+			t.Fatalf("test %d: expected: %v, got: %v", i+1, i+1, balance)
+		}
+	}
+	if len(stub.failOnce) != 0 {
+		t.Fatalf("expected all failed addresses are retried, got: %v", stub.failOnce)
+	}
+}
+
+// brokenBalanceEth is a stub of eth namespace, eth_getBalance always fails with err
+type brokenBalanceEth struct {
+	mu    sync.Mutex
+	err   string
+	calls int
+}
+
+func (s *brokenBalanceEth) GetBalance(addr common.Address, block string) (*hexutil.Big, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.calls++
+	return nil, fmt.Errorf("%s", s.err)
+}
+
+func TestGetBalancesByRpcBatchNotSplit(t *testing.T) {
+	rpcBatchRetryDelay = time.Millisecond
+	defer func() { rpcBatchRetryDelay = time.Second }()
+
+	var addresses []string
+	for i := 1; i <= 8; i++ {
+		addresses = append(addresses, common.BigToAddress(big.NewInt(int64(i))).Hex())
+	}
+	stub := &brokenBalanceEth{err: "daily request count exceeded"}
+	rpcClient := dialStubRpc(t, map[string]any{"eth": stub})
+
+	// the errors not about batch size are retried on the whole batch, it's not split into single addresses
+	if _, err := getBalancesByRpcBatch(rpcClient, addresses); err == nil {
+		t.Fatalf("expect error")
+	}
+	if expected := len(addresses) * (rpcBatchMaxRetries + 1); stub.calls != expected {
+		t.Fatalf("expected: %v calls, got: %v", expected, stub.calls)
+	}
+}
+
+func TestIsRpcBatchTooLargeError(t *testing.T) {
+	tests := []struct {
+		err      error
+		expected bool
+	}{
+		{rpc.HTTPError{StatusCode: 413, Status: "413 Request Entity Too Large"}, true},
+		{fmt.Errorf("batch too large"), true},
+		{fmt.Errorf("batch size exceeds limit of 100"), true},
+		{rpc.HTTPError{StatusCode: 502, Status: "502 Bad Gateway"}, false},
+		{fmt.Errorf("header not found"), false},
+		{fmt.Errorf("too many requests"), false},
+	}
+
+	for i, tc := range tests {
+		if got := isRpcBatchTooLargeError(tc.err); got != tc.expected {
+			t.Fatalf("test %d: expected: %v, got: %v", i+1, tc.expected, got)
+		}
+	}
+}
@@ -2,19 +2,41 @@ package cmd
 
 import (
 	"log"
+	"os"
 
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/spf13/cobra"
 )
 
 // broadcastTxCmd represents the broadcastTx command
 var broadcastTxCmd = &cobra.Command{
-	Use:   "broadcast-tx <signed-raw-tx>",
-	Short: "Broadcast tx by rpc eth_sendRawTransaction",
+	Use:   "broadcast-tx <signed-raw-tx-or-file>",
+	Short: "Broadcast tx by rpc eth_sendRawTransaction, the signed tx file produced by tx sign is also accepted",
 	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
+		var signedTxHexStr = args[0]
+		if !isValidHexString(signedTxHexStr) && fileExists(signedTxHexStr) {
+			txFile, err := readTxFile(signedTxHexStr)
+			checkErr(err)
+			if txFile.RawTx == "" {
+				log.Fatalf("%s is not signed, please sign it by tx sign", signedTxHexStr)
+			}
+			signedTxHexStr = txFile.RawTx
+		}
+
 		InitGlobalClient(globalOptNodeUrl)
 
-		var signedTxHexStr = args[0]
+		if txFile, err := decodeSignedTx(signedTxHexStr); err != nil {
+			log.Printf("warning: decode tx failed: %v", err)
+		} else {
+			// chain id of tx without EIP155 replay protection is 0
+			if txFile.ChainId != "0" && txFile.ChainId != globalChainId {
+				log.Fatalf("the tx is for chain %s, but the connected chain is %s", txFile.ChainId, globalChainId)
+			}
+			printTxSummary(os.Stderr, txFile, GetFuncSig)
+		}
+
 		rpcReturnTx, err := SendRawTransaction(globalClient.RpcClient, signedTxHexStr)
 		if err != nil {
 			log.Fatalf("SendRawTransaction fail: %s", err)
@@ -22,11 +44,29 @@ var broadcastTxCmd = &cobra.Command{
 
 		log.Printf("tx %s is broadcasted", rpcReturnTx)
 
-		for k, v := range nodeUrlMap {
-			if v == globalOptNodeUrl {
-				log.Print(nodeTxExplorerUrlMap[k] + rpcReturnTx.String())
-				break
-			}
-		}
+		printTxExplorerUrl(rpcReturnTx.String())
 	},
 }
+
+// decodeSignedTx decodes signed raw tx, and recovers its sender
+func decodeSignedTx(signedTxHexStr string) (*TxFile, error) {
+	rawTx, err := hexutil.Decode(signedTxHexStr)
+	if err != nil {
+		return nil, err
+	}
+	var tx types.Transaction
+	if err := tx.UnmarshalBinary(rawTx); err != nil {
+		return nil, err
+	}
+	var signer types.Signer
+	if tx.Protected() {
+		signer = types.LatestSignerForChainID(tx.ChainId())
+	} else {
+		signer = types.HomesteadSigner{}
+	}
+	from, err := types.Sender(signer, &tx)
+	if err != nil {
+		return nil, err
+	}
+	return newTxFile(&tx, tx.ChainId(), from), nil
+}
@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"crypto/ecdsa"
 	"fmt"
 	"log"
 	"math/big"
@@ -11,12 +10,18 @@ import (
 	"github.com/spf13/cobra"
 )
 
-var buildRawTxFrom string
 var buildRawTxSignData string
 var buildRawTxHexValueInWei string
 
+// BuildRawTxOutput is the json output (--output json) of build-raw-tx command
+type BuildRawTxOutput struct {
+	RawTx  string `json:"rawTx"` // can be used by rpc eth_sendRawTransaction
+	TxHash string `json:"txHash"`
+	From   string `json:"from"`
+	Nonce  uint64 `json:"nonce"`
+}
+
 func init() {
-	buildRawTxCmd.Flags().StringVarP(&buildRawTxFrom, "from", "", "", "sender address. Required with --sign-data; ignored with --private-key (derived from the key).")
 	buildRawTxCmd.Flags().StringVarP(&buildRawTxSignData, "sign-data", "", "", "65 bytes signature in [R || S || V] format where V is 0 or 1. Required if --private-key is not set; the two are mutually exclusive.")
 	buildRawTxCmd.Flags().StringVarP(&buildRawTxHexValueInWei, "hex-value-in-wei", "", "", "tx value in wei, hex-encoded with 0x prefix (e.g. 0xde0b6b3a7640000 for 1 ether). Defaults to 0 if omitted.")
 }
@@ -34,23 +39,23 @@ Arguments:
 	Run: func(cmd *cobra.Command, args []string) {
 		InitGlobalClient(globalOptNodeUrl)
 
-		if globalOptPrivateKey == "" && buildRawTxSignData == "" {
-			log.Fatalf("exactly one of --private-key or --sign-data is required")
+		if !hasSigner() && buildRawTxSignData == "" {
+			log.Fatalf("exactly one of signer (%s) or --sign-data is required", signerOptions)
 		}
-		if globalOptPrivateKey != "" && buildRawTxSignData != "" {
-			log.Fatalf("--private-key and --sign-data are mutually exclusive, provide only one")
+		if hasSigner() && buildRawTxSignData != "" {
+			log.Fatalf("signer (%s) and --sign-data are mutually exclusive, provide only one", signerOptions)
 		}
 
-		var privateKey *ecdsa.PrivateKey
+		var signer Signer
 		var fromAddress common.Address
-		if globalOptPrivateKey != "" {
-			privateKey = hexToPrivateKey(globalOptPrivateKey)
-			fromAddress = extractAddressFromPrivateKey(privateKey)
+		if hasSigner() {
+			signer = loadSigner()
+			fromAddress = signer.Address()
 		} else {
-			if buildRawTxFrom == "" {
+			if globalOptFrom == "" {
 				log.Fatalf("--from is required when --sign-data is used")
 			}
-			fromAddress = common.HexToAddress(buildRawTxFrom)
+			fromAddress = common.HexToAddress(globalOptFrom)
 		}
 
 		if !isValidEthAddress(args[0]) {
@@ -78,12 +83,16 @@ Arguments:
 			value = parsed
 		}
 
-		signedTx, err := BuildSignedTx(globalClient.EthClient, privateKey, &fromAddress, &toAddress, value, nil, common.FromHex(hexData), common.FromHex(buildRawTxSignData))
+		signedTx, err := BuildSignedTx(globalClient.EthClient, signer, &fromAddress, &toAddress, value, nil, common.FromHex(hexData), common.FromHex(buildRawTxSignData))
 		checkErr(err)
 
 		rawTx, err := GenRawTx(signedTx)
 		checkErr(err)
 
+		if isJsonOutput() {
+			printJson(BuildRawTxOutput{RawTx: rawTx, TxHash: signedTx.Hash().Hex(), From: fromAddress.Hex(), Nonce: signedTx.Nonce()})
+			return
+		}
 		fmt.Printf("signed raw tx (can be used by rpc eth_sendRawTransaction) = %v\n", rawTx)
 	},
 }
@@ -4,6 +4,7 @@ import (
 	"log"
 	"os"
 
+	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/shopspring/decimal"
@@ -16,8 +17,9 @@ var callCmdTransferAmt string
 
 func init() {
 	callCmd.Flags().StringVarP(&callCmdABIFile, "abi-file", "", "", "the path of abi file, if this option specified, 'function signature' can be just function name")
-	callCmd.Flags().StringVarP(&callCmdTransferUnit, "unit", "u", "ether", "wei | gwei | ether, unit of amount")
+	callCmd.Flags().StringVarP(&callCmdTransferUnit, "unit", "u", "ether", "wei | gwei | ether, unit of amount, ether means the native currency of chain (its symbol is also accepted, e.g. POL)")
 	callCmd.Flags().StringVarP(&callCmdTransferAmt, "value", "", "0", "the amount you want to transfer when call contract, unit is ether and can be changed by --unit")
+	addOverrideFlags(callCmd)
 }
 
 var callCmd = &cobra.Command{
@@ -29,6 +31,10 @@ var callCmd = &cobra.Command{
 			_ = cmd.Help()
 			os.Exit(1)
 		}
+		checkErr(parseOverrides())
+		if hasOverrides() && !globalOptDryRun {
+			log.Fatalf("state and block overrides only work with --dry-run")
+		}
 
 		InitGlobalClient(globalOptNodeUrl)
 
@@ -52,6 +58,8 @@ var callCmd = &cobra.Command{
 			if err != nil {
 				log.Fatal(err)
 			}
+			setRevertErrorsABI(abiContent)
+			setTxEventsABI(abiContent)
 			funcName := funcSignature
 			funcSignature, err = extractFuncDefinition(string(abiContent), extractFuncName(funcName))
 			checkErr(err)
@@ -65,17 +73,34 @@ var callCmd = &cobra.Command{
 			log.Printf("input data = %v", hexutil.Encode(txInputData))
 		}
 
-		if globalOptPrivateKey == "" {
-			log.Fatalf("--private-key is required for call command")
+		if hasOverrides() {
+			// simulate the call by eth_call with overrides, the tx is not built since gas estimation ignores the overrides
+			var from common.Address
+			if hasSigner() {
+				from = loadSigner().Address()
+			} else if isValidEthAddress(globalOptFrom) {
+				from = common.HexToAddress(globalOptFrom)
+			} else {
+				log.Fatalf("%s or --from is required for call command with overrides", signerOptions)
+			}
+			var valueInWei = unify2Wei(decimal.RequireFromString(callCmdTransferAmt), callCmdTransferUnit)
+			var contract = common.HexToAddress(contractAddr)
+
+			output, err := CallMsg(globalClient.RpcClient, ethereum.CallMsg{From: from, To: &contract, Value: valueInWei.BigInt(), Data: txInputData})
+			checkErr(err)
+
+			printContractReturnData(funcSignature, output)
+		} else if !hasSigner() {
+			log.Fatalf("%s is required for call command", signerOptions)
 		} else {
 			var value = decimal.RequireFromString(callCmdTransferAmt)
 			var valueInWei = unify2Wei(value, callCmdTransferUnit)
 
 			var contract = common.HexToAddress(contractAddr)
-			tx, err := Transact(globalClient.RpcClient, globalClient.EthClient, hexToPrivateKey(globalOptPrivateKey), &contract, valueInWei.BigInt(), nil, txInputData)
+			out, err := TransactTx(globalClient.RpcClient, globalClient.EthClient, loadSigner(), &contract, valueInWei.BigInt(), nil, txInputData)
 			checkErr(err)
 
-			log.Printf("transaction %s finished", tx)
+			printTxOutput(out)
 		}
 
 	},
@@ -0,0 +1,238 @@
+package cmd
+
+import (
+	_ "embed"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// The bundled chain registry, it's a subset of https://chainlist.org/rpcs.json.
+// Run `ethutil chains refresh` to download the full list into the cache directory.
+//
+//go:embed chains.json
+var embeddedChainRegistry []byte
+
+const defaultChainRegistryUrl = "https://chainlist.org/rpcs.json"
+
+// How many rpc urls of registry are probed when the chain is selected by --chain
+const maxRegistryRpcUrls = 5
+
+// ChainData is an item of chain registry, the format is compatible with
+// https://chainlist.org/rpcs.json and https://chainid.network/chains.json
+type ChainData struct {
+	Name           string          `json:"name"`
+	Chain          string          `json:"chain"`
+	ChainId        uint64          `json:"chainId"`
+	ShortName      string          `json:"shortName"`
+	ChainSlug      string          `json:"chainSlug,omitempty"`
+	NativeCurrency NativeCurrency  `json:"nativeCurrency"`
+	Rpc            []RpcEndpoint   `json:"rpc"`
+	Explorers      []ChainExplorer `json:"explorers,omitempty"`
+}
+
+type NativeCurrency struct {
+	Name     string `json:"name"`
+	Symbol   string `json:"symbol"`
+	Decimals int32  `json:"decimals"`
+}
+
+type RpcEndpoint struct {
+	Url          string `json:"url"`
+	Tracking     string `json:"tracking,omitempty"`
+	IsOpenSource bool   `json:"isOpenSource,omitempty"`
+}
+
+// UnmarshalJSON accepts both a plain url string (chainid.network) and an object (chainlist.org)
+func (e *RpcEndpoint) UnmarshalJSON(data []byte) error {
+	var rpcUrl string
+	if err := json.Unmarshal(data, &rpcUrl); err == nil {
+		*e = RpcEndpoint{Url: rpcUrl}
+		return nil
+	}
+
+	type rpcEndpoint RpcEndpoint // avoid recursion
+	var endpoint rpcEndpoint
+	if err := json.Unmarshal(data, &endpoint); err != nil {
+		return err
+	}
+	*e = RpcEndpoint(endpoint)
+	return nil
+}
+
+type ChainExplorer struct {
+	Name     string `json:"name"`
+	Url      string `json:"url"`
+	Standard string `json:"standard,omitempty"`
+}
+
+// usableRpcUrls returns rpc urls which can be used without api key, at most limit urls are returned
+func (c *ChainData) usableRpcUrls(limit int) []string {
+	var urls []string
+	for _, endpoint := range c.Rpc {
+		if strings.Contains(endpoint.Url, "${") {
+			// filter out rpc contains '${', for example, https://mainnet.infura.io/v3/${INFURA_API_KEY}
+			continue
+		}
+		if !isHttpUrl(endpoint.Url) && !strings.HasPrefix(endpoint.Url, "wss://") {
+			continue
+		}
+		urls = append(urls, endpoint.Url)
+		if len(urls) >= limit {
+			break
+		}
+	}
+	return urls
+}
+
+// txExplorerUrl returns the url prefix of tx in block explorer, empty if no EIP3091 explorer is known
+func (c *ChainData) txExplorerUrl() string {
+	for _, explorer := range c.Explorers {
+		if explorer.Url != "" && (explorer.Standard == "" || explorer.Standard == "EIP3091") {
+			return strings.TrimSuffix(explorer.Url, "/") + "/tx/"
+		}
+	}
+	return ""
+}
+
+// chainRegistryCacheFile returns the path of registry downloaded by `chains refresh`
+func chainRegistryCacheFile() string {
+	cacheDir, err := os.UserCacheDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(cacheDir, "ethutil", "chains.json")
+}
+
+var chainRegistry []ChainData
+var chainRegistryOnce sync.Once
+
+// loadChainRegistry returns the cached registry if `chains refresh` was run, otherwise the bundled one
+func loadChainRegistry() []ChainData {
+	chainRegistryOnce.Do(func() {
+		if cacheFile := chainRegistryCacheFile(); cacheFile != "" && fileExists(cacheFile) {
+			content, err := os.ReadFile(cacheFile)
+			if err == nil {
+				chainRegistry, err = parseChainRegistry(content)
+			}
+			if err == nil {
+				return
+			}
+			log.Printf("warning: ignore chain registry %s: %v", cacheFile, err)
+		}
+
+		var err error
+		chainRegistry, err = parseChainRegistry(embeddedChainRegistry)
+		checkErr(err)
+	})
+	return chainRegistry
+}
+
+// parseChainRegistry parses registry content, chains without chain id are dropped
+func parseChainRegistry(content []byte) ([]ChainData, error) {
+	var chains []ChainData
+	if err := json.Unmarshal(content, &chains); err != nil {
+		return nil, fmt.Errorf("parse chain registry failed: %w", err)
+	}
+
+	var rc = make([]ChainData, 0, len(chains))
+	for _, chain := range chains {
+		if chain.ChainId > 0 {
+			rc = append(rc, chain)
+		}
+	}
+	sort.SliceStable(rc, func(i, j int) bool {
+		return rc[i].ChainId < rc[j].ChainId
+	})
+	return rc, nil
+}
+
+// lookupChain finds chain by chain id, short name or slug (case-insensitive)
+func lookupChain(chains []ChainData, nameOrId string) *ChainData {
+	if chainId, err := strconv.ParseUint(nameOrId, 10, 64); err == nil {
+		for i := range chains {
+			if chains[i].ChainId == chainId {
+				return &chains[i]
+			}
+		}
+		return nil
+	}
+
+	for i := range chains {
+		if strings.EqualFold(chains[i].ShortName, nameOrId) || (chains[i].ChainSlug != "" && strings.EqualFold(chains[i].ChainSlug, nameOrId)) {
+			return &chains[i]
+		}
+	}
+	return nil
+}
+
+// searchChains returns chains whose name, short name, slug or native currency symbol contains keyword
+func searchChains(chains []ChainData, keyword string) []ChainData {
+	keyword = strings.ToLower(keyword)
+	var rc []ChainData
+	for _, chain := range chains {
+		if strconv.FormatUint(chain.ChainId, 10) == keyword ||
+			strings.Contains(strings.ToLower(chain.Name), keyword) ||
+			strings.Contains(strings.ToLower(chain.ShortName), keyword) ||
+			strings.Contains(strings.ToLower(chain.ChainSlug), keyword) ||
+			strings.EqualFold(chain.NativeCurrency.Symbol, keyword) {
+			rc = append(rc, chain)
+		}
+	}
+	return rc
+}
+
+// chainProfileFromRegistry builds a chain profile for chains not configured in config file
+func chainProfileFromRegistry(chain *ChainData) *ChainProfile {
+	return &ChainProfile{
+		Rpc:            chain.usableRpcUrls(maxRegistryRpcUrls),
+		TxExplorerUrl:  chain.txExplorerUrl(),
+		NativeSymbol:   chain.NativeCurrency.Symbol,
+		NativeDecimals: chain.NativeCurrency.Decimals,
+	}
+}
+
+// downloadChainRegistry downloads registry from registryUrl, and saves it into cacheFile
+func downloadChainRegistry(registryUrl string, cacheFile string) (int, error) {
+	resp, err := http.Get(registryUrl)
+	if err != nil {
+		return 0, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return 0, fmt.Errorf("download %s failed: http status %s", registryUrl, resp.Status)
+	}
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return 0, err
+	}
+
+	chains, err := parseChainRegistry(body)
+	if err != nil {
+		return 0, err
+	}
+	if len(chains) == 0 {
+		return 0, fmt.Errorf("no chain found in %s", registryUrl)
+	}
+
+	// Only the fields we use are saved
+	content, err := json.MarshalIndent(chains, "", "  ")
+	if err != nil {
+		return 0, err
+	}
+	if err := os.MkdirAll(filepath.Dir(cacheFile), 0755); err != nil {
+		return 0, err
+	}
+	if err := os.WriteFile(cacheFile, content, 0644); err != nil {
+		return 0, err
+	}
+	return len(chains), nil
+}
@@ -0,0 +1,92 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestEmbeddedChainRegistry(t *testing.T) {
+	chains, err := parseChainRegistry(embeddedChainRegistry)
+	if err != nil {
+		t.Fatalf("parseChainRegistry failed: %v", err)
+	}
+
+	tests := []struct {
+		nameOrId string
+		chainId  uint64
+		symbol   string
+		explorer string
+	}{
+		{nameOrId: "1", chainId: 1, symbol: "ETH", explorer: "https://etherscan.io/tx/"},
+		{nameOrId: "arb1", chainId: 42161, symbol: "ETH", explorer: "https://arbiscan.io/tx/"},
+		{nameOrId: "Polygon", chainId: 137, symbol: "POL", explorer: "https://polygonscan.com/tx/"},
+		{nameOrId: "42220", chainId: 42220, symbol: "CELO", explorer: "https://celoscan.io/tx/"},
+	}
+
+	for i, tc := range tests {
+		chain := lookupChain(chains, tc.nameOrId)
+		if chain == nil {
+			t.Fatalf("test %d: chain %s not found", i+1, tc.nameOrId)
+		}
+		if chain.ChainId != tc.chainId || chain.NativeCurrency.Symbol != tc.symbol {
+			t.Fatalf("test %d: expected: %v %v, got: %v %v", i+1, tc.chainId, tc.symbol, chain.ChainId, chain.NativeCurrency.Symbol)
+		}
+		if chain.txExplorerUrl() != tc.explorer {
+			t.Fatalf("test %d: expected: %v, got: %v", i+1, tc.explorer, chain.txExplorerUrl())
+		}
+		if len(chain.usableRpcUrls(maxRegistryRpcUrls)) == 0 {
+			t.Fatalf("test %d: no usable rpc for chain %s", i+1, tc.nameOrId)
+		}
+	}
+
+	if lookupChain(chains, "999999999") != nil || lookupChain(chains, "no-such-chain") != nil {
+		t.Fatalf("expect nil for unknown chain")
+	}
+}
+
+func TestDownloadChainRegistry(t *testing.T) {
+	// format of https://chainid.network/chains.json, rpc is a list of string
+	content := `[
+  {"name": "Test Chain", "chain": "TST", "chainId": 31337, "shortName": "tst",
+   "nativeCurrency": {"name": "Test", "symbol": "TST", "decimals": 18},
+   "rpc": ["https://mainnet.infura.io/v3/${INFURA_API_KEY}", "https://rpc.test.example", "wss://ws.test.example"],
+   "explorers": [{"name": "testscan", "url": "https://testscan.example/", "standard": "EIP3091"}]},
+  {"name": "No Chain Id"}
+]`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		_, _ = w.Write([]byte(content))
+	}))
+	defer server.Close()
+
+	cacheFile := filepath.Join(t.TempDir(), "ethutil", "chains.json")
+	count, err := downloadChainRegistry(server.URL, cacheFile)
+	if err != nil {
+		t.Fatalf("downloadChainRegistry failed: %v", err)
+	}
+	if count != 1 {
+		t.Fatalf("expected: %v, got: %v", 1, count)
+	}
+
+	savedContent, err := os.ReadFile(cacheFile)
+	if err != nil {
+		t.Fatalf("read saved registry failed: %v", err)
+	}
+	saved, err := parseChainRegistry(savedContent)
+	if err != nil {
+		t.Fatalf("parse saved registry failed: %v", err)
+	}
+	chain := lookupChain(saved, "TST")
+	if chain == nil {
+		t.Fatalf("chain tst not found")
+	}
+	profile := chainProfileFromRegistry(chain)
+	if len(profile.Rpc) != 2 || profile.Rpc[0] != "https://rpc.test.example" {
+		t.Fatalf("unexpected rpc %v", profile.Rpc)
+	}
+	if profile.TxExplorerUrl != "https://testscan.example/tx/" {
+		t.Fatalf("unexpected explorer %v", profile.TxExplorerUrl)
+	}
+}
@@ -0,0 +1,87 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+
+	"github.com/spf13/cobra"
+)
+
+var chainsRefreshUrl string
+
+// chainsCmd represents the chains command
+var chainsCmd = &cobra.Command{
+	Use:   "chains",
+	Short: "List, search or refresh the chain registry, chains in registry can be used by --chain <chain-id-or-short-name>",
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = cmd.Help()
+		os.Exit(1)
+	},
+}
+
+var chainsListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List all chains in the chain registry",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		printChains(loadChainRegistry())
+	},
+}
+
+var chainsSearchCmd = &cobra.Command{
+	Use:   "search <keyword>",
+	Short: "Search chains by chain id, name, short name or native currency symbol",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		chains := searchChains(loadChainRegistry(), args[0])
+		if len(chains) == 0 && !isJsonOutput() {
+			log.Printf("no chain matches %s", args[0])
+			return
+		}
+		printChains(chains)
+	},
+}
+
+var chainsRefreshCmd = &cobra.Command{
+	Use:   "refresh",
+	Short: "Download the latest chain registry, it's used instead of the bundled one afterwards",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		cacheFile := chainRegistryCacheFile()
+		if cacheFile == "" {
+			log.Fatalf("can not determine the cache directory")
+		}
+
+		log.Printf("Downloading chain registry from %s", chainsRefreshUrl)
+		count, err := downloadChainRegistry(chainsRefreshUrl, cacheFile)
+		checkErr(err)
+		log.Printf("%d chains saved to %s", count, cacheFile)
+	},
+}
+
+func init() {
+	chainsRefreshCmd.Flags().StringVarP(&chainsRefreshUrl, "url", "", defaultChainRegistryUrl, "the url of chain registry, the format of https://chainid.network/chains.json is also supported")
+
+	chainsCmd.AddCommand(chainsListCmd)
+	chainsCmd.AddCommand(chainsSearchCmd)
+	chainsCmd.AddCommand(chainsRefreshCmd)
+}
+
+// printChains prints chains as a table, or as json array of ChainData if --output json
+func printChains(chains []ChainData) {
+	if isJsonOutput() {
+		if chains == nil {
+			chains = []ChainData{}
+		}
+		printJson(chains)
+		return
+	}
+
+	if !globalOptTerseOutput {
+		fmt.Printf("%-10s %-16s %-8s %s\n", "CHAIN ID", "SHORT NAME", "SYMBOL", "NAME")
+	}
+	for _, chain := range chains {
+		fmt.Printf("%-10d %-16s %-8s %s\n", chain.ChainId, chain.ShortName, chain.NativeCurrency.Symbol, chain.Name)
+	}
+}
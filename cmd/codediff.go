@@ -0,0 +1,272 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/cobra"
+)
+
+// codeDiffMaxShownBytes is the max bytes of a range shown in text output, the json output is not truncated
+const codeDiffMaxShownBytes = 32
+
+var codeDiffBinFile string
+var codeDiffSrcFile string
+var codeDiffStandardJsonFile string
+var codeDiffContractName string
+var codeDiffLibraries []string
+
+func init() {
+	codeDiffCmd.Flags().StringVarP(&codeDiffBinFile, "bin-file", "", "", "the path of runtime bytecode file of contract (not the creation bytecode), the immutables are guessed by zero PUSH32")
+	codeDiffCmd.Flags().StringVarP(&codeDiffSrcFile, "src-file", "", "", "the path of source file of contract, compile it by --solc (solcjs by default)")
+	codeDiffCmd.Flags().StringVarP(&codeDiffStandardJsonFile, "standard-json", "", "", "the path of solc standard json input file, compile it by --solc (solcjs by default), the compiler options override its settings")
+	codeDiffCmd.Flags().StringVarP(&codeDiffContractName, "contract-name", "", "", "the contract to compare, format: Name or file.sol:Name. If it's not specified, auto find the LAST contract in --src-file")
+	codeDiffCmd.Flags().StringArrayVarP(&codeDiffLibraries, "library", "", nil, "link library address into the placeholders of bytecode, format: <[file.sol:]Name>=<address>, can be repeated. The addresses of libraries not specified are ignored")
+	addCompilerFlags(codeDiffCmd)
+
+	getCodeCmd.AddCommand(codeDiffCmd)
+}
+
+// CodeDiffRange is a byte range [Start, End) of runtime bytecode
+type CodeDiffRange struct {
+	Start   int    `json:"start"`
+	End     int    `json:"end"`
+	OnChain string `json:"onChain"` // "0x" if it's beyond the on-chain code
+	Local   string `json:"local"`   // "0x" if it's beyond the local code
+}
+
+// CodeDiffOutput is the json output (--output json) of code diff command
+type CodeDiffOutput struct {
+	Address       string            `json:"address"`
+	Contract      string            `json:"contract,omitempty"` // file.sol:Name, empty if --bin-file
+	Match         bool              `json:"match"`              // the metadata is not compared
+	MetadataMatch bool              `json:"metadataMatch"`
+	OnChainSize   int               `json:"onChainSize"`
+	LocalSize     int               `json:"localSize"`
+	OnChainMeta   *BytecodeMetadata `json:"onChainMetadata,omitempty"`
+	LocalMeta     *BytecodeMetadata `json:"localMetadata,omitempty"`
+	Masked        []CodeDiffRange   `json:"masked"` // the immutables and unspecified libraries, they are ignored
+	Diffs         []CodeDiffRange   `json:"diffs"`
+}
+
+var codeDiffCmd = &cobra.Command{
+	Use:   "diff <address>",
+	Short: "Compare runtime bytecode of a contract on the blockchain with local compiled contract or bytecode file",
+	Long: "Compare runtime bytecode of a contract on the blockchain with local compiled contract or bytecode file. " +
+		"The metadata appended by compiler, the immutables and the addresses of libraries not specified by --library are ignored. " +
+		"The exit code is 1 if the bytecode does not match.",
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("requires address")
+		}
+		if !isValidEthAddress(args[0]) {
+			return fmt.Errorf("%v is not a valid eth address", args[0])
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		var sourceCount = 0
+		for _, file := range []string{codeDiffBinFile, codeDiffSrcFile, codeDiffStandardJsonFile} {
+			if file != "" {
+				sourceCount++
+			}
+		}
+		if sourceCount != 1 {
+			log.Fatalf("must specify one of --bin-file, --src-file and --standard-json")
+		}
+		libraries, err := parseLibraries(codeDiffLibraries)
+		checkErr(err)
+
+		var contractName string
+		var local []byte
+		var masks []solcLinkReference
+		if codeDiffBinFile != "" {
+			content, err := os.ReadFile(codeDiffBinFile)
+			checkErr(err)
+			linked, err := linkBytecode(remove0xPrefix(strings.TrimSpace(string(content))), nil, libraries)
+			checkErr(err)
+			local, err = hexutil.Decode("0x" + linked)
+			if err != nil {
+				log.Fatalf("%v is not a hex bytecode file", codeDiffBinFile)
+			}
+			masks = guessImmutableReferences(local)
+		} else {
+			contract, _, _, err := compileContract(compilerSettings(cmd), codeDiffSrcFile, codeDiffStandardJsonFile, codeDiffContractName)
+			checkErr(err)
+			contractName = contract.File + ":" + contract.Name
+			local, masks, err = linkRuntimeCode(contract, libraries)
+			checkErr(err)
+		}
+
+		InitGlobalClient(globalOptNodeUrl)
+		onChain, err := codeAt(globalClient.RpcClient, common.HexToAddress(args[0]), blockArg())
+		checkErr(err)
+		if len(onChain) == 0 {
+			log.Fatalf("no runtime bytecode found for %v", args[0])
+		}
+
+		out := compareRuntimeCode(onChain, local, masks)
+		out.Address = args[0]
+		out.Contract = contractName
+
+		if isJsonOutput() {
+			printJson(out)
+		} else {
+			printCodeDiff(out)
+		}
+		if !out.Match {
+			os.Exit(1)
+		}
+	},
+}
+
+// linkRuntimeCode links the libraries into runtime bytecode of contract, returns the code and the positions of
+// immutables and libraries not specified, which are filled by zero
+func linkRuntimeCode(contract *compiledContract, libraries map[string]common.Address) ([]byte, []solcLinkReference, error) {
+	if contract.DeployedBytecode == "" {
+		return nil, nil, fmt.Errorf("runtime bytecode of %s:%s is not found in output of compiler", contract.File, contract.Name)
+	}
+
+	var masks []solcLinkReference
+	var allLibraries = make(map[string]common.Address)
+	for name, addr := range libraries {
+		allLibraries[name] = addr
+	}
+	for file, libs := range contract.DeployedLinkReferences {
+		for lib, refs := range libs {
+			if _, ok := libraries[file+":"+lib]; ok {
+				continue
+			}
+			if _, ok := libraries[lib]; ok {
+				continue
+			}
+			log.Printf("library %s:%s is not specified by --library, its address is ignored", file, lib)
+			allLibraries[file+":"+lib] = common.Address{}
+			masks = append(masks, refs...)
+		}
+	}
+	for _, refs := range contract.ImmutableReferences {
+		masks = append(masks, refs...)
+	}
+
+	linked, err := linkBytecode(contract.DeployedBytecode, contract.DeployedLinkReferences, allLibraries)
+	if err != nil {
+		return nil, nil, err
+	}
+	code, err := hexutil.Decode("0x" + linked)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid runtime bytecode of %s:%s: %w", contract.File, contract.Name, err)
+	}
+	return code, masks, nil
+}
+
+// guessImmutableReferences guesses the positions of immutables in runtime bytecode without compiler output, solc
+// leaves PUSH32 with zero placeholder for immutables, which is filled when contract is deployed
+func guessImmutableReferences(code []byte) []solcLinkReference {
+	var masks []solcLinkReference
+	body, _ := splitMetadata(code)
+	for _, ins := range disassembleInstructions(body) {
+		if ins.Op == "PUSH32" && ins.PushData == hexutil.Encode(make([]byte, 32)) {
+			masks = append(masks, solcLinkReference{Start: ins.Pc + 1, Length: 32})
+		}
+	}
+	return masks
+}
+
+// compareRuntimeCode compares on-chain code with local code, the metadata of both are split off and compared
+// separately, and the bytes at masks are ignored
+func compareRuntimeCode(onChain []byte, local []byte, masks []solcLinkReference) *CodeDiffOutput {
+	var out = &CodeDiffOutput{OnChainSize: len(onChain), LocalSize: len(local), Masked: []CodeDiffRange{}, Diffs: []CodeDiffRange{}}
+
+	onChainBody, onChainMeta := splitMetadata(onChain)
+	localBody, localMeta := splitMetadata(local)
+	out.OnChainMeta = onChainMeta
+	out.LocalMeta = localMeta
+	out.MetadataMatch = (onChainMeta == nil && localMeta == nil) ||
+		(onChainMeta != nil && localMeta != nil && onChainMeta.Raw == localMeta.Raw)
+
+	newRange := func(start, end int) CodeDiffRange {
+		return CodeDiffRange{
+			Start:   start,
+			End:     end,
+			OnChain: hexutil.Encode(onChainBody[min(start, len(onChainBody)):min(end, len(onChainBody))]),
+			Local:   hexutil.Encode(localBody[min(start, len(localBody)):min(end, len(localBody))]),
+		}
+	}
+
+	var size = max(len(onChainBody), len(localBody))
+	var masked = make([]bool, size)
+	sort.Slice(masks, func(i, j int) bool { return masks[i].Start < masks[j].Start })
+	for _, mask := range masks {
+		if mask.Start < 0 || mask.Start+mask.Length > len(localBody) {
+			continue
+		}
+		for i := mask.Start; i < mask.Start+mask.Length; i++ {
+			masked[i] = true
+		}
+		out.Masked = append(out.Masked, newRange(mask.Start, mask.Start+mask.Length))
+	}
+
+	var start = -1
+	for i := 0; i <= size; i++ {
+		differ := i < size && (i >= len(onChainBody) || i >= len(localBody) || (!masked[i] && onChainBody[i] != localBody[i]))
+		if differ && start < 0 {
+			start = i
+		} else if !differ && start >= 0 {
+			out.Diffs = append(out.Diffs, newRange(start, i))
+			start = -1
+		}
+	}
+	out.Match = len(out.Diffs) == 0
+	return out
+}
+
+// printCodeDiff prints the result of code diff
+func printCodeDiff(out *CodeDiffOutput) {
+	var local = out.Contract
+	if local == "" {
+		local = codeDiffBinFile
+	}
+	if globalOptTerseOutput {
+		if out.Match {
+			fmt.Println("match")
+		} else {
+			fmt.Println("mismatch")
+		}
+		return
+	}
+
+	fmt.Printf("code size: on-chain %d bytes, local %d bytes\n", out.OnChainSize, out.LocalSize)
+	for _, r := range out.Masked {
+		fmt.Printf("ignored 0x%04x-0x%04x (immutable or library): %s\n", r.Start, r.End, truncateString(r.OnChain, 2+codeDiffMaxShownBytes*2))
+	}
+	if out.OnChainMeta == nil && out.LocalMeta == nil {
+		fmt.Printf("metadata: not found\n")
+	} else if out.MetadataMatch {
+		fmt.Printf("metadata: match\n")
+	} else {
+		describe := func(m *BytecodeMetadata) string {
+			if m == nil {
+				return "not found"
+			}
+			return m.String()
+		}
+		fmt.Printf("metadata: mismatch, the sources may differ in comments or file paths, or the compiler settings differ\n")
+		fmt.Printf("  on-chain: %s\n", describe(out.OnChainMeta))
+		fmt.Printf("  local: %s\n", describe(out.LocalMeta))
+	}
+
+	if out.Match {
+		fmt.Printf("runtime bytecode of %s matches %s\n", out.Address, local)
+		return
+	}
+	fmt.Printf("runtime bytecode of %s does not match %s, %d ranges differ:\n", out.Address, local, len(out.Diffs))
+	for _, r := range out.Diffs {
+		fmt.Printf("  0x%04x-0x%04x on-chain %s, local %s\n", r.Start, r.End, truncateString(r.OnChain, 2+codeDiffMaxShownBytes*2), truncateString(r.Local, 2+codeDiffMaxShownBytes*2))
+	}
+}
@@ -0,0 +1,90 @@
+package cmd
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestCompareRuntimeCode(t *testing.T) {
+	var metadata1 = "a2646970667358221220" + strings.Repeat("11", 32) + "64736f6c63430008140033"
+	var metadata2 = "a2646970667358221220" + strings.Repeat("22", 32) + "64736f6c63430008140033"
+
+	tests := []struct {
+		onChain       string
+		local         string
+		masks         []solcLinkReference
+		metadataMatch bool
+		masked        []CodeDiffRange
+		diffs         []CodeDiffRange
+	}{
+		// identical
+		{"0x6080604052" + metadata1, "0x6080604052" + metadata1, nil, true,
+			[]CodeDiffRange{}, []CodeDiffRange{}},
+		// only metadata differs
+		{"0x6080604052" + metadata1, "0x6080604052" + metadata2, nil, false,
+			[]CodeDiffRange{}, []CodeDiffRange{}},
+		// immutable is masked
+		{"0x7f" + strings.Repeat("ab", 32) + "00", "0x7f" + strings.Repeat("00", 32) + "00", []solcLinkReference{{Start: 1, Length: 32}}, true,
+			[]CodeDiffRange{{Start: 1, End: 33, OnChain: "0x" + strings.Repeat("ab", 32), Local: "0x" + strings.Repeat("00", 32)}}, []CodeDiffRange{}},
+		// differ in two ranges
+		{"0x600160020100", "0x600360040100", nil, true,
+			[]CodeDiffRange{}, []CodeDiffRange{{Start: 1, End: 2, OnChain: "0x01", Local: "0x03"}, {Start: 3, End: 4, OnChain: "0x02", Local: "0x04"}}},
+		// on-chain code is longer
+		{"0x6001600201", "0x600160", nil, true,
+			[]CodeDiffRange{}, []CodeDiffRange{{Start: 3, End: 5, OnChain: "0x0201", Local: "0x"}}},
+		// local code has no metadata
+		{"0x6080604052" + metadata1, "0x6080604052", nil, false,
+			[]CodeDiffRange{}, []CodeDiffRange{}},
+	}
+
+	for i, tc := range tests {
+		out := compareRuntimeCode(common.FromHex(tc.onChain), common.FromHex(tc.local), tc.masks)
+		if out.MetadataMatch != tc.metadataMatch || out.Match != (len(tc.diffs) == 0) ||
+			!reflect.DeepEqual(out.Masked, tc.masked) || !reflect.DeepEqual(out.Diffs, tc.diffs) {
+			t.Fatalf("test %d: expected: metadataMatch %v, masked %+v, diffs %+v, got: %+v", i+1, tc.metadataMatch, tc.masked, tc.diffs, out)
+		}
+	}
+}
+
+func TestGuessImmutableReferences(t *testing.T) {
+	code := common.FromHex("0x6080" + "7f" + strings.Repeat("00", 32) + "7f" + strings.Repeat("00", 31) + "01" + "7f" + strings.Repeat("00", 32))
+	expected := []solcLinkReference{{Start: 3, Length: 32}, {Start: 69, Length: 32}}
+	if got := guessImmutableReferences(code); !reflect.DeepEqual(got, expected) {
+		t.Fatalf("expected: %+v, got: %+v", expected, got)
+	}
+}
+
+func TestLinkRuntimeCode(t *testing.T) {
+	var libAddr = common.HexToAddress("0x1111111111111111111111111111111111111111")
+	var contract = &compiledContract{
+		File: "a.sol",
+		Name: "A",
+		// PUSH20 <Math> PUSH20 <Util> PUSH32 <immutable>
+		DeployedBytecode: "73" + "__$" + strings.Repeat("a", 34) + "$__" + "73" + "__$" + strings.Repeat("b", 34) + "$__" + "7f" + strings.Repeat("00", 32),
+		DeployedLinkReferences: map[string]map[string][]solcLinkReference{
+			"math.sol": {"Math": {{Start: 1, Length: 20}}},
+			"util.sol": {"Util": {{Start: 22, Length: 20}}},
+		},
+		ImmutableReferences: map[string][]solcLinkReference{"12": {{Start: 43, Length: 32}}},
+	}
+
+	code, masks, err := linkRuntimeCode(contract, map[string]common.Address{"Math": libAddr})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expectedCode := common.FromHex("0x73" + common.Bytes2Hex(libAddr.Bytes()) + "73" + strings.Repeat("00", 20) + "7f" + strings.Repeat("00", 32))
+	if !reflect.DeepEqual(code, expectedCode) {
+		t.Fatalf("expected: %x, got: %x", expectedCode, code)
+	}
+	expectedMasks := []solcLinkReference{{Start: 22, Length: 20}, {Start: 43, Length: 32}}
+	if !reflect.DeepEqual(masks, expectedMasks) {
+		t.Fatalf("expected: %+v, got: %+v", expectedMasks, masks)
+	}
+
+	if _, _, err := linkRuntimeCode(&compiledContract{File: "a.sol", Name: "A"}, nil); err == nil {
+		t.Fatalf("expect error if runtime bytecode is not found")
+	}
+}
@@ -10,7 +10,10 @@ import (
 	"log"
 	"math/big"
 	"net/http"
+	"os"
 	"regexp"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/ethereum/go-ethereum"
@@ -97,7 +100,12 @@ func isValidEthAddress(v string) bool {
 
 // isContractAddress returns true if address is a valid eth contract address.
 func isContractAddress(client *ethclient.Client, address common.Address) (bool, error) {
-	bytecode, err := client.CodeAt(context.Background(), address, nil) // nil is latest block
+	return isContractAddressAt(client, address, "latest")
+}
+
+// isContractAddressAt is same as isContractAddress, but checks the code at block (see blockArg).
+func isContractAddressAt(client *ethclient.Client, address common.Address, block interface{}) (bool, error) {
+	bytecode, err := codeAt(client.Client(), address, block)
 	if err != nil {
 		return false, err
 	}
@@ -201,32 +209,75 @@ func hexToPublicKey(publicKeyHex string) (*ecdsa.PublicKey, error) {
 }
 
 // wei2Other converts wei to other unit (specified by targetUnit).
+// Unit ether means the native currency of current chain, its decimals may not be 18.
 func wei2Other(sourceAmtInWei decimal.Decimal, targetUnit string) decimal.Decimal {
 	decimal.DivisionPrecision = 18
 	if targetUnit == unitWei {
 		return sourceAmtInWei
 	} else if targetUnit == unitGwei {
 		return sourceAmtInWei.Div(decimal.NewFromBigInt(big.NewInt(1), 9))
-	} else if targetUnit == unitEther {
-		return sourceAmtInWei.Div(decimal.NewFromBigInt(big.NewInt(1), 18))
+	} else if normalizeUnit(targetUnit) == unitEther {
+		return sourceAmtInWei.Div(decimal.NewFromBigInt(big.NewInt(1), currentNativeCurrency().Decimals))
 	} else {
 		panic(fmt.Sprintf("unrecognized unit %v", targetUnit))
 	}
 }
 
 // unify2Wei converts any unit (specified by sourceUnit) to wei.
+// Unit ether means the native currency of current chain, its decimals may not be 18.
 func unify2Wei(sourceAmt decimal.Decimal, sourceUnit string) decimal.Decimal {
 	if sourceUnit == unitWei {
 		return sourceAmt
 	} else if sourceUnit == unitGwei {
 		return sourceAmt.Mul(decimal.NewFromBigInt(big.NewInt(1), 9))
-	} else if sourceUnit == unitEther {
-		return sourceAmt.Mul(decimal.NewFromBigInt(big.NewInt(1), 18))
+	} else if normalizeUnit(sourceUnit) == unitEther {
+		return sourceAmt.Mul(decimal.NewFromBigInt(big.NewInt(1), currentNativeCurrency().Decimals))
 	} else {
 		panic(fmt.Sprintf("unrecognized unit %v", sourceUnit))
 	}
 }
 
+// normalizeUnit converts the symbol of native currency (case-insensitive, e.g. POL) to unitEther, other units are kept
+func normalizeUnit(unit string) string {
+	if strings.EqualFold(unit, currentNativeCurrency().Symbol) {
+		return unitEther
+	}
+	return unit
+}
+
+// isValidUnit returns true if unit is wei, gwei, ether or the symbol of native currency
+func isValidUnit(unit string) bool {
+	return contains([]string{unitWei, unitGwei, unitEther}, normalizeUnit(unit))
+}
+
+// unitDisplayName returns the symbol of native currency for unit ether, e.g. "0.5 POL" instead of "0.5 ether"
+func unitDisplayName(unit string) string {
+	if normalizeUnit(unit) == unitEther {
+		return currentNativeCurrency().Symbol
+	}
+	return unit
+}
+
+// currentNativeCurrency returns the native currency of current chain. The chain profile is preferred,
+// then the chain registry of the connected chain. ETH with 18 decimals is returned if it's unknown.
+func currentNativeCurrency() NativeCurrency {
+	var currency = NativeCurrency{Name: "Ether", Symbol: "ETH", Decimals: 18}
+	if globalChainId != "" {
+		if chain := lookupChain(loadChainRegistry(), globalChainId); chain != nil && chain.NativeCurrency.Symbol != "" {
+			currency = chain.NativeCurrency
+		}
+	}
+	if globalChainProfile != nil {
+		if globalChainProfile.NativeSymbol != "" {
+			currency.Symbol = globalChainProfile.NativeSymbol
+		}
+		if globalChainProfile.NativeDecimals > 0 {
+			currency.Decimals = globalChainProfile.NativeDecimals
+		}
+	}
+	return currency
+}
+
 // extractAddressFromPrivateKey extracts address from ecdsa.PrivateKey.
 func extractAddressFromPrivateKey(privateKey *ecdsa.PrivateKey) common.Address {
 	publicKey := privateKey.Public()
@@ -349,18 +400,11 @@ func SendRawTransaction(rpcClient *rpc.Client, signedTxHexStr string) (*common.H
 }
 
 // BuildTx builds transaction
-func BuildTx(client *ethclient.Client, privateKey *ecdsa.PrivateKey, fromAddress, /* fromAddress is only needed when privateKey is nil */
+func BuildTx(client *ethclient.Client, account common.Address,
 	toAddress *common.Address, amount, gasPrice *big.Int, data []byte,
 ) (*types.Transaction, error) {
 	var nonce uint64
 	var err error
-	var account common.Address
-
-	if privateKey != nil {
-		account = extractAddressFromPrivateKey(privateKey)
-	} else {
-		account = *fromAddress
-	}
 
 	if globalOptNonce < 0 {
 		nonce, err = client.PendingNonceAt(context.Background(), account)
@@ -380,7 +424,7 @@ func BuildTx(client *ethclient.Client, privateKey *ecdsa.PrivateKey, fromAddress
 			Data:  data,
 		})
 		if err != nil {
-			return nil, fmt.Errorf("EstimateGas fail: %w", err)
+			return nil, fmt.Errorf("EstimateGas fail: %w", wrapRevertError(err))
 		}
 		gasLimit = estimateGasLimit
 	}
@@ -388,36 +432,9 @@ func BuildTx(client *ethclient.Client, privateKey *ecdsa.PrivateKey, fromAddress
 	var tx *types.Transaction
 
 	if globalOptTxType == txTypeEip1559 {
-		var maxFeePerGasEstimate = new(big.Int)
-		var maxPriorityFeePerGasEstimate = new(big.Int)
-		var err error
-		if globalOptMaxPriorityFeePerGas == "" || globalOptMaxFeePerGas == "" {
-			maxFeePerGasEstimate, maxPriorityFeePerGasEstimate, err = getEIP1559GasPriceByFeeHistory(client)
-			if err != nil {
-				return nil, fmt.Errorf("getEIP1559GasPriceByFeeHistory fail: %w", err)
-			}
-		}
-
-		var maxPriorityFeePerGas *big.Int
-		if globalOptMaxPriorityFeePerGas == "" {
-			// Use estimate value
-			maxPriorityFeePerGas = maxPriorityFeePerGasEstimate
-		} else {
-			// Use the value set by the user
-			maxPriorityFeePerGasDecimal, _ := decimal.NewFromString(globalOptMaxPriorityFeePerGas)
-			// convert from gwei to wei
-			maxPriorityFeePerGas = maxPriorityFeePerGasDecimal.Mul(decimal.RequireFromString("1000000000")).BigInt()
-		}
-
-		var maxFeePerGas *big.Int
-		if globalOptMaxFeePerGas == "" {
-			// Use estimate value
-			maxFeePerGas = maxFeePerGasEstimate
-		} else {
-			// Use the value set by the user
-			maxFeePerGasDecimal, _ := decimal.NewFromString(globalOptMaxFeePerGas)
-			// convert from gwei to wei
-			maxFeePerGas = maxFeePerGasDecimal.Mul(decimal.RequireFromString("1000000000")).BigInt()
+		maxFeePerGas, maxPriorityFeePerGas, err := getEIP1559Fees(client)
+		if err != nil {
+			return nil, err
 		}
 
 		tx = types.NewTx(&types.DynamicFeeTx{
@@ -449,12 +466,63 @@ func BuildTx(client *ethclient.Client, privateKey *ecdsa.PrivateKey, fromAddress
 	return tx, err
 }
 
+// getEIP1559Fees returns maxFeePerGas and maxPriorityFeePerGas, the values of --max-fee-per-gas and
+// --max-priority-fee-per-gas are used if they are given, otherwise they are estimated by fee history
+func getEIP1559Fees(client *ethclient.Client) (*big.Int, *big.Int, error) {
+	var maxFeePerGasEstimate = new(big.Int)
+	var maxPriorityFeePerGasEstimate = new(big.Int)
+	var err error
+	if globalOptMaxPriorityFeePerGas == "" || globalOptMaxFeePerGas == "" {
+		maxFeePerGasEstimate, maxPriorityFeePerGasEstimate, err = getEIP1559GasPriceByFeeHistory(client)
+		if err != nil {
+			return nil, nil, fmt.Errorf("getEIP1559GasPriceByFeeHistory fail: %w", err)
+		}
+	}
+
+	var maxPriorityFeePerGas *big.Int
+	if globalOptMaxPriorityFeePerGas == "" {
+		// Use estimate value
+		maxPriorityFeePerGas = maxPriorityFeePerGasEstimate
+	} else {
+		// Use the value set by the user
+		maxPriorityFeePerGasDecimal, _ := decimal.NewFromString(globalOptMaxPriorityFeePerGas)
+		// convert from gwei to wei
+		maxPriorityFeePerGas = maxPriorityFeePerGasDecimal.Mul(decimal.RequireFromString("1000000000")).BigInt()
+	}
+
+	var maxFeePerGas *big.Int
+	if globalOptMaxFeePerGas == "" {
+		// Use estimate value
+		maxFeePerGas = maxFeePerGasEstimate
+	} else {
+		// Use the value set by the user
+		maxFeePerGasDecimal, _ := decimal.NewFromString(globalOptMaxFeePerGas)
+		// convert from gwei to wei
+		maxFeePerGas = maxFeePerGasDecimal.Mul(decimal.RequireFromString("1000000000")).BigInt()
+	}
+	return maxFeePerGas, maxPriorityFeePerGas, nil
+}
+
+// printPreHash prints the hash before ecdsa sign, it goes to stderr in json output mode
+func printPreHash(preHash common.Hash) {
+	if isJsonOutput() {
+		log.Printf("hash before ecdsa sign (hex) = %x", preHash.Bytes())
+		return
+	}
+	fmt.Printf("hash before ecdsa sign (hex) = %x\n", preHash.Bytes())
+}
+
 // BuildSignedTx builds signed transaction
 func BuildSignedTx(
-	client *ethclient.Client, privateKey *ecdsa.PrivateKey, fromAddress, /* fromAddress is only needed when privateKey is nil */
+	client *ethclient.Client, signer Signer, fromAddress, /* fromAddress is only needed when signer is nil */
 	toAddress *common.Address, amount, gasPrice *big.Int, data []byte, sigData []byte,
 ) (*types.Transaction, error) {
-	tx, err := BuildTx(client, privateKey, fromAddress, toAddress, amount, gasPrice, data)
+	if signer != nil {
+		var account = signer.Address()
+		fromAddress = &account
+	}
+
+	tx, err := BuildTx(client, *fromAddress, toAddress, amount, gasPrice, data)
 	if err != nil {
 		return nil, fmt.Errorf("BuildTx fail: %w", err)
 	}
@@ -464,23 +532,20 @@ func BuildSignedTx(
 		return nil, fmt.Errorf("NetworkID fail: %w", err)
 	}
 
-	signer := types.NewLondonSigner(chainID)
+	txSigner := types.NewLondonSigner(chainID)
 
-	preHash := signer.Hash(tx)
+	preHash := txSigner.Hash(tx)
 	if globalOptShowPreHash {
-		fmt.Printf("hash before ecdsa sign (hex) = %x\n", preHash.Bytes())
+		printPreHash(preHash)
 	}
 
-	// If sigData is not provided, signs the transaction using the private key
+	// If sigData is not provided, signs the transaction by signer
 	if len(sigData) == 0 {
-		sigData, err = crypto.Sign(preHash[:], privateKey)
-		if err != nil {
-			return nil, err
-		}
+		return signer.SignTx(tx, chainID)
 	}
 
 	// attach sigData to tx
-	signedTx, err := tx.WithSignature(signer, sigData)
+	signedTx, err := tx.WithSignature(txSigner, sigData)
 	if err != nil {
 		return nil, fmt.Errorf("WithSignature fail: %w", err)
 	}
@@ -488,19 +553,66 @@ func BuildSignedTx(
 	return signedTx, nil
 }
 
+// TxOutput is the result of a sent transaction, it's the json output (--output json) of commands
+// transfer, call, deploy, deploy-erc20, erc20 (approve/transfer/transferFrom/mint) and drop-tx.
+type TxOutput struct {
+	TxHash          string            `json:"txHash"`
+	From            string            `json:"from"`
+	To              string            `json:"to,omitempty"` // empty means contract creation
+	Value           string            `json:"value"`        // in wei
+	Nonce           uint64            `json:"nonce"`
+	Broadcasted     bool              `json:"broadcasted"`      // false if --dry-run
+	Status          string            `json:"status,omitempty"` // success | pending (--not-check), empty if not broadcasted
+	BlockNumber     string            `json:"blockNumber,omitempty"`
+	GasUsed         uint64            `json:"gasUsed,omitempty"`
+	ContractAddress string            `json:"contractAddress,omitempty"` // only for contract creation
+	ExplorerUrl     string            `json:"explorerUrl,omitempty"`
+	Receipt         *ReceiptOutput    `json:"receipt,omitempty"`      // only if --show-receipt
+	Simulation      *SimulationOutput `json:"simulation,omitempty"`   // only if --simulate
+	Verification    *VerifyOutput     `json:"verification,omitempty"` // only if deploy --verify
+}
+
 // Transact invokes the (paid) contract method.
-func Transact(rpcClient *rpc.Client, client *ethclient.Client, privateKey *ecdsa.PrivateKey, toAddress *common.Address, amount *big.Int, gasPrice *big.Int, data []byte) (string, error) {
-	fromAddress := extractAddressFromPrivateKey(privateKey)
+func Transact(rpcClient *rpc.Client, client *ethclient.Client, signer Signer, toAddress *common.Address, amount *big.Int, gasPrice *big.Int, data []byte) (string, error) {
+	out, err := TransactTx(rpcClient, client, signer, toAddress, amount, gasPrice, data)
+	if err != nil {
+		return "", err
+	}
+	return out.TxHash, nil
+}
+
+// TransactTx is same as Transact, but returns more details of the transaction.
+func TransactTx(rpcClient *rpc.Client, client *ethclient.Client, signer Signer, toAddress *common.Address, amount *big.Int, gasPrice *big.Int, data []byte) (*TxOutput, error) {
+	fromAddress := signer.Address()
 
-	signedTx, err := BuildSignedTx(client, privateKey, &fromAddress, toAddress, amount, gasPrice, data, nil)
+	signedTx, err := BuildSignedTx(client, signer, &fromAddress, toAddress, amount, gasPrice, data, nil)
 	if err != nil {
-		return "", fmt.Errorf("BuildSignedTx fail: %w", err)
+		if globalOptSimulate && globalOptGasLimit == 0 {
+			// the gas estimation may fail because the tx reverts, the simulation reports why
+			msg := ethereum.CallMsg{From: fromAddress, To: toAddress, Value: amount, Data: data}
+			if simulation, err := simulateTx(rpcClient, msg, txEventDecoder); err == nil {
+				printSimulation(os.Stderr, simulation)
+			}
+		}
+		return nil, fmt.Errorf("BuildSignedTx fail: %w", err)
+	}
+
+	var out = &TxOutput{
+		TxHash: signedTx.Hash().String(),
+		From:   fromAddress.Hex(),
+		Value:  signedTx.Value().String(),
+		Nonce:  signedTx.Nonce(),
+	}
+	if toAddress != nil {
+		out.To = toAddress.Hex()
+	} else {
+		out.ContractAddress = crypto.CreateAddress(fromAddress, signedTx.Nonce()).Hex()
 	}
 
 	if globalOptShowRawTx {
 		rawTx, err := GenRawTx(signedTx)
 		if err != nil {
-			return "", fmt.Errorf("GenRawTx fail: %w", err)
+			return nil, fmt.Errorf("GenRawTx fail: %w", err)
 		}
 		log.Printf("raw tx = %v", rawTx)
 	}
@@ -517,53 +629,110 @@ func Transact(rpcClient *rpc.Client, client *ethclient.Client, privateKey *ecdsa
 		}
 		gas, err := client.EstimateGas(context.Background(), msg)
 		if err != nil {
-			return "", fmt.Errorf("EstimateGas fail: %w", err)
+			return nil, fmt.Errorf("EstimateGas fail: %w", wrapRevertError(err))
 		}
 		log.Printf("estimate gas = %v", gas)
 	}
 
+	if globalOptSimulate {
+		out.Simulation, err = simulateTx(rpcClient, txCallMsg(signedTx, fromAddress), txEventDecoder)
+		if err != nil {
+			return nil, fmt.Errorf("simulateTx fail: %w", err)
+		}
+		if !isJsonOutput() {
+			printSimulation(os.Stderr, out.Simulation)
+		}
+		if !out.Simulation.Success {
+			if !globalOptForce {
+				return nil, fmt.Errorf("simulation of tx failed: %s, use --force to broadcast it anyway", out.Simulation.RevertReason)
+			}
+			log.Printf("warning: simulation of tx failed, broadcast it because of --force")
+		}
+	}
+
 	if globalOptDryRun {
 		// return tx directly, do not broadcast it
-		return signedTx.Hash().String(), nil
+		return out, nil
 	}
 
 	rpcReturnTx, err := SendSignedTx(rpcClient, signedTx)
 	if err != nil {
-		return "", fmt.Errorf("SendSignedTx fail: %w", err)
+		return nil, fmt.Errorf("SendSignedTx fail: %w", err)
 	}
 
 	if signedTx.Hash() != *rpcReturnTx {
 		log.Printf("warning: tx not same. the computed tx is %v, but rpc eth_sendRawTransaction return tx %v, use the later", signedTx.Hash(), rpcReturnTx)
 	}
+	out.TxHash = rpcReturnTx.String()
+	out.Broadcasted = true
+	out.ExplorerUrl = txExplorerUrl(out.TxHash)
 
 	if transferNotCheck {
-		return rpcReturnTx.String(), nil
+		out.Status = "pending"
+		return out, nil
 	}
 
 	rp, err := getTxReceipt(client, *rpcReturnTx, 0)
 	if err != nil {
-		return "", fmt.Errorf("getTxReceipt fail: %w", err)
+		return nil, fmt.Errorf("getTxReceipt fail: %w", err)
 	}
 
 	if !globalOptTerseOutput {
-		// show tx explorer url only when globalOptNodeUrl in map nodeUrlMap
-		for k, v := range nodeUrlMap {
-			if v == globalOptNodeUrl {
-				log.Print(nodeTxExplorerUrlMap[k] + rpcReturnTx.String())
-				break
-			}
+		printTxExplorerUrl(rpcReturnTx.String())
+	}
+
+	if globalOptShowReceipt {
+		out.Receipt = buildReceiptOutput(rp, fromAddress, toAddress, txEventDecoder)
+		if !isJsonOutput() {
+			printReceipt(os.Stderr, out.Receipt)
 		}
 	}
 
 	if rp.Status != types.ReceiptStatusSuccessful {
-		return "", fmt.Errorf("tx %v minted, but status is failed, please check it in block explorer", rpcReturnTx.String())
+		if reason := replayTxRevertReason(rpcClient, signedTx, fromAddress, rp.BlockNumber); reason != "" {
+			return nil, fmt.Errorf("tx %v minted, but status is failed, revert reason (approximated by replaying it on the state of parent block %v): %s", rpcReturnTx.String(), new(big.Int).Sub(rp.BlockNumber, big.NewInt(1)), reason)
+		}
+		return nil, fmt.Errorf("tx %v minted, but status is failed, please check it in block explorer", rpcReturnTx.String())
 	}
+	out.Status = "success"
+	out.BlockNumber = rp.BlockNumber.String()
+	out.GasUsed = rp.GasUsed
 
 	if toAddress == nil {
-		log.Printf("the new contract deployed at %v", crypto.CreateAddress(fromAddress, signedTx.Nonce()))
+		log.Printf("the new contract deployed at %v", out.ContractAddress)
 	}
 
-	return rpcReturnTx.String(), nil
+	return out, nil
+}
+
+// printTxOutput prints the result of a sent transaction
+func printTxOutput(out *TxOutput) {
+	if isJsonOutput() {
+		printJson(out)
+		return
+	}
+	log.Printf("transaction %s finished", out.TxHash)
+}
+
+// printTxExplorerUrl prints the tx url in block explorer, only when the explorer of current chain is known
+func printTxExplorerUrl(txHash string) {
+	if explorerUrl := txExplorerUrl(txHash); explorerUrl != "" {
+		log.Print(explorerUrl)
+	}
+}
+
+// txExplorerUrl returns the url of tx in block explorer, the explorer of chain profile is preferred,
+// then the explorer in chain registry of the connected chain. Empty string is returned if it's unknown.
+func txExplorerUrl(txHash string) string {
+	if globalChainProfile != nil && globalChainProfile.TxExplorerUrl != "" {
+		return globalChainProfile.TxExplorerUrl + txHash
+	}
+	if globalChainId != "" {
+		if chain := lookupChain(loadChainRegistry(), globalChainId); chain != nil && chain.txExplorerUrl() != "" {
+			return chain.txExplorerUrl() + txHash
+		}
+	}
+	return ""
 }
 
 // getEIP1559GasPrice returns maxFeePerGasEstimate and maxPriorityFeePerGasEstimate
@@ -701,18 +870,72 @@ func getEIP1559GasPriceByFeeHistory(client *ethclient.Client) (*big.Int, *big.In
 
 // Call invokes the (constant) contract method.
 func Call(rpcClient *rpc.Client, toAddress common.Address, data []byte) ([]byte, error) {
+	return CallAt(rpcClient, toAddress, data, "latest")
+}
+
+// CallAt is same as Call, but executes it at block (see blockArg), it's used by commands reading state of --block.
+func CallAt(rpcClient *rpc.Client, toAddress common.Address, data []byte, block interface{}) ([]byte, error) {
 	opts := new(bind.CallOpts)
 	msg := ethereum.CallMsg{From: opts.From, To: &toAddress, Data: data}
 
+	return CallMsgAt(rpcClient, msg, block)
+}
+
+// CallMsg executes msg by eth_call at the latest block, the state and block overrides are passed if specified.
+func CallMsg(rpcClient *rpc.Client, msg ethereum.CallMsg) ([]byte, error) {
+	return CallMsgAt(rpcClient, msg, "latest")
+}
+
+// CallMsgAt is same as CallMsg, but executes msg at block (see blockArg).
+func CallMsgAt(rpcClient *rpc.Client, msg ethereum.CallMsg, block interface{}) ([]byte, error) {
+	var args = []interface{}{toCallArg(msg), block}
+	if hasOverrides() {
+		args = append(args, callStateOverrides)
+		if callBlockOverrides != nil {
+			args = append(args, callBlockOverrides)
+		}
+	}
+
 	var result hexutil.Bytes
-	err := rpcClient.CallContext(context.Background(), &result, "eth_call", toCallArg(msg), "latest")
+	err := rpcClient.CallContext(context.Background(), &result, "eth_call", args...)
 	if err != nil {
-		return nil, err
+		return nil, wrapRevertError(err)
 	}
 
 	return result, nil
 }
 
+// codeAt returns the code of address at block, e.g. "latest" or blockArg() of --block.
+func codeAt(rpcClient *rpc.Client, address common.Address, block interface{}) ([]byte, error) {
+	var result hexutil.Bytes
+	err := rpcClient.CallContext(context.Background(), &result, "eth_getCode", address, block)
+	return result, err
+}
+
+// parseBlockOpt parses --block, it accepts a block number (decimal or hex), a block hash or a block tag.
+func parseBlockOpt(block string) (rpc.BlockNumberOrHash, error) {
+	if number, err := strconv.ParseInt(block, 10, 64); err == nil && number >= 0 {
+		return rpc.BlockNumberOrHashWithNumber(rpc.BlockNumber(number)), nil
+	}
+
+	var bnh rpc.BlockNumberOrHash
+	if err := bnh.UnmarshalJSON([]byte(strconv.Quote(block))); err != nil {
+		return bnh, err
+	}
+	return bnh, nil
+}
+
+// blockArg returns the block parameter of json-rpc (e.g. eth_call, eth_getBalance) by --block, it's only passed by
+// the commands reading state (query, erc20 read methods, balance, code, storage, proxy and multicall), the commands
+// sending tx always read the latest state.
+// A block hash is passed in the object form of EIP-1898, which is not supported by very old nodes.
+func blockArg() interface{} {
+	if number, ok := globalBlock.Number(); ok {
+		return number.String()
+	}
+	return globalBlock
+}
+
 // toCallArg build call argument
 //
 // Similar with func toCallArg in https://github.com/ethereum/go-ethereum/blob/14eb8967be7acc54c5dc9a416151ac45c01251b6/ethclient/ethclient.go#L642
@@ -921,6 +1144,91 @@ func GetFuncSigFrom4Byte(funcHash string) ([]string, error) {
 	return rc, nil
 }
 
+// GetEventSig recover event signature from topic0 (32 bytes hash)
+func GetEventSig(topic0 string) ([]string, error) {
+	sigs, err := GetEventSigFromOpenchain(topic0)
+	if err != nil || len(sigs) == 0 {
+		sigs, err = GetEventSigFrom4Byte(topic0)
+	}
+	return sigs, err
+}
+
+// GetEventSigFromOpenchain recover event signature from topic0 by openchain API
+// For example:
+//
+//	param: "0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+//	return: ["Transfer(address,address,uint256)"]
+//
+// $ curl -X 'GET' 'https://api.openchain.xyz/signature-database/v1/lookup?event=0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef&filter=true'
+// {"ok":true,"result":{"event":{"0xddf252ad...":[{"name":"Transfer(address,address,uint256)","filtered":false}]},"function":{}}}
+func GetEventSigFromOpenchain(topic0 string) ([]string, error) {
+	var url = fmt.Sprintf("https://api.openchain.xyz/signature-database/v1/lookup?event=%s&filter=true", topic0)
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	type eventSig struct {
+		Name     string `json:"name"`
+		Filtered bool   `json:"filtered"`
+	}
+	type respMsg struct {
+		Ok     bool `json:"ok"`
+		Result struct {
+			Event map[string][]eventSig `json:"event"`
+		} `json:"result"`
+	}
+	var data respMsg
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, err
+	}
+
+	var rc []string
+	for _, data := range data.Result.Event[topic0] {
+		rc = append(rc, data.Name)
+	}
+
+	return rc, nil
+}
+
+// GetEventSigFrom4Byte recover event signature from topic0 by 4byte API
+// $ curl -X 'GET' 'https://www.4byte.directory/api/v1/event-signatures/?hex_signature=0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef'
+// See https://www.4byte.directory/docs/
+func GetEventSigFrom4Byte(topic0 string) ([]string, error) {
+	var url = fmt.Sprintf("https://www.4byte.directory/api/v1/event-signatures/?hex_signature=%s", topic0)
+	resp, err := http.Get(url)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	type respMsg struct {
+		Count  uint64 `json:"count"`
+		Result []struct {
+			TextSignature string `json:"text_signature"`
+		} `json:"results"`
+	}
+	var data respMsg
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, err
+	}
+
+	var rc []string
+	for _, data := range data.Result {
+		rc = append(rc, data.TextSignature)
+	}
+	return rc, nil
+}
+
 // MnemonicToPrivateKey generate private key from mnemonic words
 func MnemonicToPrivateKey(mnemonic string, derivationPath string) (*ecdsa.PrivateKey, error) {
 	// Generate a Bip32 HD wallet for the mnemonic and a user supplied password
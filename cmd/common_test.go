@@ -1,8 +1,13 @@
 package cmd
 
 import (
-	"github.com/shopspring/decimal"
+	"encoding/json"
 	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/shopspring/decimal"
 )
 
 func TestWei2Other(t *testing.T) {
@@ -35,3 +40,118 @@ func TestWei2Other(t *testing.T) {
 		}
 	}
 }
+
+func TestNativeCurrencyUnit(t *testing.T) {
+	savedProfile := globalChainProfile
+	defer func() { globalChainProfile = savedProfile }()
+	globalChainProfile = &ChainProfile{NativeSymbol: "HBAR", NativeDecimals: 8}
+
+	tests := []struct {
+		amount      string
+		unit        string
+		amountInWei string
+		displayName string
+	}{
+		{amount: "3", unit: "ether", amountInWei: "300000000", displayName: "HBAR"},
+		{amount: "0.5", unit: "hbar", amountInWei: "50000000", displayName: "HBAR"},
+		{amount: "2", unit: "gwei", amountInWei: "2000000000", displayName: "gwei"},
+		{amount: "7", unit: "wei", amountInWei: "7", displayName: "wei"},
+	}
+
+	for i, tc := range tests {
+		if !isValidUnit(tc.unit) {
+			t.Fatalf("test %d: %v should be valid unit", i+1, tc.unit)
+		}
+		got := unify2Wei(decimal.RequireFromString(tc.amount), tc.unit)
+		if got.String() != tc.amountInWei {
+			t.Fatalf("test %d: expected: %v, got: %v", i+1, tc.amountInWei, got)
+		}
+		back := wei2Other(got, tc.unit)
+		if !back.Equal(decimal.RequireFromString(tc.amount)) {
+			t.Fatalf("test %d: expected: %v, got: %v", i+1, tc.amount, back)
+		}
+		if unitDisplayName(tc.unit) != tc.displayName {
+			t.Fatalf("test %d: expected: %v, got: %v", i+1, tc.displayName, unitDisplayName(tc.unit))
+		}
+	}
+
+	if isValidUnit("pol") {
+		t.Fatalf("pol should be invalid unit on this chain")
+	}
+}
+
+func TestParseBlockOpt(t *testing.T) {
+	defer func() { globalBlock = rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber) }()
+
+	tests := []struct {
+		block    string
+		expected string // json of block parameter in json-rpc
+	}{
+		{"latest", `"latest"`},
+		{"pending", `"pending"`},
+		{"safe", `"safe"`},
+		{"finalized", `"finalized"`},
+		{"earliest", `"earliest"`},
+		{"17000000", `"0x1036640"`},
+		{"0x1036640", `"0x1036640"`},
+		{"0x88e96d4537bea4d9c05d12549907b32561d3bf31f45aae734cdc119f13406cb6", `{"blockHash":"0x88e96d4537bea4d9c05d12549907b32561d3bf31f45aae734cdc119f13406cb6"}`},
+	}
+
+	for i, tc := range tests {
+		var err error
+		globalBlock, err = parseBlockOpt(tc.block)
+		if err != nil {
+			t.Fatalf("test %d: parseBlockOpt failed: %v", i+1, err)
+		}
+		got, _ := json.Marshal(blockArg())
+		if string(got) != tc.expected {
+			t.Fatalf("test %d: expected: %v, got: %v", i+1, tc.expected, string(got))
+		}
+	}
+
+	for _, block := range []string{"", "-1", "yesterday", "0x12zz"} {
+		if _, err := parseBlockOpt(block); err == nil {
+			t.Fatalf("expect error for --block %q", block)
+		}
+	}
+}
+
+// blockRecordingEth is a stub of eth namespace, it records the block parameter of eth_call and eth_getCode
+type blockRecordingEth struct {
+	blocks []string
+}
+
+func (s *blockRecordingEth) Call(args map[string]interface{}, block string) (hexutil.Bytes, error) {
+	s.blocks = append(s.blocks, block)
+	return hexutil.Bytes{}, nil
+}
+
+func (s *blockRecordingEth) GetCode(address common.Address, block string) (hexutil.Bytes, error) {
+	s.blocks = append(s.blocks, block)
+	return hexutil.Bytes{0x60}, nil
+}
+
+func TestCallBlock(t *testing.T) {
+	defer func() { globalBlock = rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber) }()
+	globalBlock = rpc.BlockNumberOrHashWithNumber(16)
+
+	stub := &blockRecordingEth{}
+	rpcClient := dialStubRpc(t, map[string]any{"eth": stub})
+	to := common.HexToAddress("0xdAC17F958D2ee523a2206206994597C13D831ec7")
+
+	// the helpers used by commands sending tx read the latest state, --block is passed explicitly by read commands
+	_, _ = Call(rpcClient, to, []byte{1})
+	_, _ = codeAt(rpcClient, to, "latest")
+	_, _ = CallAt(rpcClient, to, []byte{1}, blockArg())
+	_, _ = codeAt(rpcClient, to, blockArg())
+
+	expected := []string{"latest", "latest", "0x10", "0x10"}
+	if len(stub.blocks) != len(expected) {
+		t.Fatalf("expected: %v, got: %v", expected, stub.blocks)
+	}
+	for i := range expected {
+		if stub.blocks[i] != expected[i] {
+			t.Fatalf("test %d: expected: %v, got: %v", i+1, expected[i], stub.blocks[i])
+		}
+	}
+}
@@ -8,7 +8,6 @@ import (
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/crypto"
-	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/spf13/cobra"
 )
 
@@ -43,6 +42,15 @@ func validationComputeContractAddrCmdOpts() bool {
 	return true
 }
 
+// ContractAddrOutput is the json output (--output json) of compute-contract-addr command
+type ContractAddrOutput struct {
+	Deployer        string  `json:"deployer"`
+	Nonce           *uint64 `json:"nonce,omitempty"`    // only for CREATE
+	Salt            string  `json:"salt,omitempty"`     // only for CREATE2
+	InitCode        string  `json:"initCode,omitempty"` // only for CREATE2
+	ContractAddress string  `json:"contractAddress"`
+}
+
 var computeContractAddrCmd = &cobra.Command{
 	Use:   "compute-contract-addr <deployer-address>",
 	Short: "Compute contract address before deployment",
@@ -70,15 +78,19 @@ var computeContractAddrCmd = &cobra.Command{
 			var nonce uint64
 			if globalOptNonce < 0 {
 				// get nonce online
-				client, err := ethclient.Dial(globalOptNodeUrl)
-				checkErr(err)
+				InitGlobalClient(globalOptNodeUrl)
 
-				nonce, err = client.PendingNonceAt(context.TODO(), common.HexToAddress(deployerAddr))
+				var err error
+				nonce, err = globalClient.EthClient.PendingNonceAt(context.TODO(), common.HexToAddress(deployerAddr))
 				checkErr(err)
 			} else {
 				nonce = uint64(globalOptNonce)
 			}
 			contractAddr := crypto.CreateAddress(common.HexToAddress(deployerAddr), nonce)
+			if isJsonOutput() {
+				printJson(ContractAddrOutput{Deployer: deployerAddr, Nonce: &nonce, ContractAddress: contractAddr.Hex()})
+				return
+			}
 			fmt.Printf("deployer address %v\nnonce %v\ncontract address %v\n",
 				deployerAddr,
 				globalOptNonce,
@@ -87,6 +99,10 @@ var computeContractAddrCmd = &cobra.Command{
 			var salt32 [32]byte
 			copy(salt32[:], common.FromHex(computeContractAddrSalt))
 			contractAddr := crypto.CreateAddress2(common.HexToAddress(deployerAddr), salt32, crypto.Keccak256(common.FromHex(computeContractAddrInitCode)))
+			if isJsonOutput() {
+				printJson(ContractAddrOutput{Deployer: deployerAddr, Salt: computeContractAddrSalt, InitCode: computeContractAddrInitCode, ContractAddress: contractAddr.Hex()})
+				return
+			}
 			fmt.Printf("deployer address %v\nsalt %v\ninit code %v\ncontract address %v\n",
 				deployerAddr,
 				computeContractAddrSalt,
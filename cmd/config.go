@@ -0,0 +1,314 @@
+package cmd
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/BurntSushi/toml"
+	"github.com/spf13/cobra"
+)
+
+// ChainProfile is a named chain, it comes from the built-in list or from config file.
+//
+// An example of config file (~/.config/ethutil/config.toml or ./ethutil.toml):
+//
+//	default-chain = "arbitrum-staging"
+//
+//	[chains.arbitrum-staging]
+//	rpc = ["https://sepolia-rollup.arbitrum.io/rpc"]
+//	tx-explorer-url = "https://sepolia.arbiscan.io/tx/"
+//	api-url = "https://api-sepolia.arbiscan.io/api"
+//	api-key = "${ARBISCAN_API_KEY}"
+//	tx-type = "eip1559"
+//	unit = "ether"
+//	signer = "env:STAGING_PRIVATE_KEY" # or "keystore:/path/to/keystore"
+//	native-symbol = "ETH"
+//	native-decimals = 18
+//
+// Environment variables in the form ${NAME} are expanded in string values, other `$` are kept as is.
+type ChainProfile struct {
+	Rpc           []string `toml:"rpc"`
+	TxExplorerUrl string   `toml:"tx-explorer-url"`
+	ApiUrl        string   `toml:"api-url"`
+	ApiKey        string   `toml:"api-key"`
+	TxType        string   `toml:"tx-type"`
+	Unit          string   `toml:"unit"`
+	Signer        string   `toml:"signer"`
+	// The native currency, it comes from chain registry if not set
+	NativeSymbol   string `toml:"native-symbol"`
+	NativeDecimals int32  `toml:"native-decimals"`
+}
+
+// Config is the content of config file
+type Config struct {
+	DefaultChain string                   `toml:"default-chain"`
+	Chains       map[string]*ChainProfile `toml:"chains"`
+}
+
+const localConfigFileName = "ethutil.toml"
+
+// configEnvRE matches the environment variable references ${NAME} in values of config file
+var configEnvRE = regexp.MustCompile(`\$\{([A-Za-z_][A-Za-z0-9_]*)\}`)
+
+// builtinChainProfiles are always available, config file can override them
+var builtinChainProfiles = map[string]ChainProfile{
+	nodeMainnet: {
+		Rpc:           []string{"wss://mainnet.infura.io/ws/v3/21a9f5ba4bce425795cac796a66d7472"}, // please replace infura project id
+		TxExplorerUrl: "https://etherscan.io/tx/",
+		ApiUrl:        "https://api.etherscan.io/api",
+	},
+	nodeSepolia: {
+		Rpc:           []string{"wss://sepolia.infura.io/ws/v3/21a9f5ba4bce425795cac796a66d7472"}, // please replace infura project id
+		TxExplorerUrl: "https://sepolia.etherscan.io/tx/",
+		ApiUrl:        "https://api-sepolia.etherscan.io/api",
+	},
+	nodeSokol: {
+		Rpc:           []string{"https://sokol.poa.network"},
+		TxExplorerUrl: "https://blockscout.com/poa/sokol/tx/",
+		ApiUrl:        "https://blockscout.com/poa/sokol/api",
+		NativeSymbol:  "SPOA",
+	},
+	nodeBsc: {
+		Rpc:           []string{"https://bsc-dataseed1.binance.org"},
+		TxExplorerUrl: "https://bscscan.com/tx/",
+		ApiUrl:        "https://api.bscscan.com/api",
+		NativeSymbol:  "BNB",
+	},
+}
+
+// globalConfigFilePath returns the path of user level config file
+func globalConfigFilePath() string {
+	configHome := os.Getenv("XDG_CONFIG_HOME")
+	if configHome == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return ""
+		}
+		configHome = filepath.Join(home, ".config")
+	}
+	return filepath.Join(configHome, "ethutil", "config.toml")
+}
+
+// loadConfig builds the final config: built-in profiles, then the user level config file,
+// then the project-local ./ethutil.toml, then the file given by --config (must exist).
+func loadConfig(explicitFile string) (*Config, error) {
+	var cfg = &Config{Chains: make(map[string]*ChainProfile)}
+	for name, profile := range builtinChainProfiles {
+		p := profile
+		cfg.Chains[name] = &p
+	}
+
+	for _, file := range []string{globalConfigFilePath(), localConfigFileName} {
+		if file == "" || !fileExists(file) {
+			continue
+		}
+		if err := mergeConfigFile(cfg, file); err != nil {
+			return nil, err
+		}
+	}
+
+	if explicitFile != "" {
+		if !fileExists(explicitFile) {
+			return nil, fmt.Errorf("config file %s not found", explicitFile)
+		}
+		if err := mergeConfigFile(cfg, explicitFile); err != nil {
+			return nil, err
+		}
+	}
+
+	return cfg, nil
+}
+
+// expandConfigEnv replaces ${NAME} in value by environment variable NAME, other `$` (e.g. in passwords) are kept
+func expandConfigEnv(value string) string {
+	return configEnvRE.ReplaceAllStringFunc(value, func(ref string) string {
+		return os.Getenv(configEnvRE.FindStringSubmatch(ref)[1])
+	})
+}
+
+// expandEnv expands ${NAME} in string values of profile
+func (p *ChainProfile) expandEnv() {
+	for i := range p.Rpc {
+		p.Rpc[i] = expandConfigEnv(p.Rpc[i])
+	}
+	for _, field := range []*string{&p.TxExplorerUrl, &p.ApiUrl, &p.ApiKey, &p.TxType, &p.Unit, &p.Signer, &p.NativeSymbol} {
+		*field = expandConfigEnv(*field)
+	}
+}
+
+// mergeConfigFile parses file and merges it into cfg, non-empty fields in file win
+func mergeConfigFile(cfg *Config, file string) error {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return fmt.Errorf("read config file %s failed: %w", file, err)
+	}
+
+	var fileCfg Config
+	if _, err := toml.Decode(string(content), &fileCfg); err != nil {
+		return fmt.Errorf("parse config file %s failed: %w", file, err)
+	}
+
+	if fileCfg.DefaultChain != "" {
+		cfg.DefaultChain = expandConfigEnv(fileCfg.DefaultChain)
+	}
+	for name, profile := range fileCfg.Chains {
+		if profile == nil {
+			continue
+		}
+		profile.expandEnv()
+		existing, ok := cfg.Chains[name]
+		if !ok {
+			cfg.Chains[name] = profile
+			continue
+		}
+		mergeChainProfile(existing, profile)
+	}
+	return nil
+}
+
+// mergeChainProfile copies non-empty fields of src into dst
+func mergeChainProfile(dst *ChainProfile, src *ChainProfile) {
+	if len(src.Rpc) > 0 {
+		dst.Rpc = src.Rpc
+	}
+	if src.TxExplorerUrl != "" {
+		dst.TxExplorerUrl = src.TxExplorerUrl
+	}
+	if src.ApiUrl != "" {
+		dst.ApiUrl = src.ApiUrl
+	}
+	if src.ApiKey != "" {
+		dst.ApiKey = src.ApiKey
+	}
+	if src.TxType != "" {
+		dst.TxType = src.TxType
+	}
+	if src.Unit != "" {
+		dst.Unit = src.Unit
+	}
+	if src.Signer != "" {
+		dst.Signer = src.Signer
+	}
+	if src.NativeSymbol != "" {
+		dst.NativeSymbol = src.NativeSymbol
+	}
+	if src.NativeDecimals > 0 {
+		dst.NativeDecimals = src.NativeDecimals
+	}
+}
+
+// profileNames returns all chain profile names, used in help and error messages
+func (c *Config) profileNames() []string {
+	var names []string
+	for name := range c.Chains {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// resolveSignerSpec resolves the signer field of chain profile to a private key hex string.
+// Supported forms:
+//
+//	env:NAME     read private key from environment variable NAME
+//	file:PATH    read private key from file PATH
+//	0x...        the private key itself (not recommended)
+//
+// The forms keystore:PATH (same as --keystore PATH) and external:URL (same as --external-signer URL)
+// are handled by applyProfileDefaults.
+func resolveSignerSpec(spec string) (string, error) {
+	switch {
+	case strings.HasPrefix(spec, "env:"):
+		name := strings.TrimPrefix(spec, "env:")
+		value := os.Getenv(name)
+		if value == "" {
+			return "", fmt.Errorf("environment variable %s is empty", name)
+		}
+		return strings.TrimSpace(value), nil
+	case strings.HasPrefix(spec, "file:"):
+		content, err := os.ReadFile(strings.TrimPrefix(spec, "file:"))
+		if err != nil {
+			return "", fmt.Errorf("read signer file failed: %w", err)
+		}
+		return strings.TrimSpace(string(content)), nil
+	default:
+		if !isValidHexString(spec) || len(remove0xPrefix(spec)) != 64 {
+			return "", fmt.Errorf("invalid signer %q, expect env:NAME, file:PATH or a hex private key", spec)
+		}
+		return spec, nil
+	}
+}
+
+// profileSignerSpec is the signer field (env:NAME, file:PATH or a hex private key) of chain profile.
+// It is resolved lazily by resolveProfileSigner, so commands which don't sign never read the key.
+var profileSignerSpec string
+
+// resolveProfileSigner resolves profileSignerSpec to --private-key if no signer is given in command line.
+func resolveProfileSigner() {
+	if profileSignerSpec == "" {
+		return
+	}
+	spec := profileSignerSpec
+	profileSignerSpec = "" // resolve only once
+	if globalOptPrivateKey != "" || globalOptKeystore != "" || globalOptMnemonic != "" || globalOptExternalSigner != "" {
+		return
+	}
+	// Not fatal here, commands which require signing will complain about missing key
+	privateKey, err := resolveSignerSpec(spec)
+	if err != nil {
+		log.Printf("warning: resolve signer of chain %s failed: %v", globalOptChain, err)
+		return
+	}
+	globalOptPrivateKey = privateKey
+}
+
+type unitOption struct {
+	cmd   *cobra.Command
+	value *string
+}
+
+// unitOptions returns all --unit options which default value can be set by chain profile
+func unitOptions() []unitOption {
+	return []unitOption{
+		{balanceCmd, &balanceUnit},
+		{transferCmd, &transferUnit},
+		{callCmd, &callCmdTransferUnit},
+		{deployCmd, &deployValueUnit},
+		{aaTransferCmd, &aaTransferUnit},
+	}
+}
+
+// applyProfileDefaults uses default values from chain profile for options not given in command line
+func applyProfileDefaults(profile *ChainProfile) {
+	if profile.TxType != "" && !rootCmd.PersistentFlags().Changed("tx-type") {
+		globalOptTxType = profile.TxType
+	}
+
+	if strings.HasPrefix(profile.Signer, "keystore:") {
+		if !hasSigner() {
+			globalOptKeystore = strings.TrimPrefix(profile.Signer, "keystore:")
+		}
+	} else if strings.HasPrefix(profile.Signer, "external:") {
+		if !hasSigner() {
+			globalOptExternalSigner = strings.TrimPrefix(profile.Signer, "external:")
+		}
+	} else if profile.Signer != "" && !hasSigner() {
+		profileSignerSpec = profile.Signer
+	}
+
+	if profile.Unit != "" {
+		if !isValidUnit(profile.Unit) {
+			log.Fatalf("invalid unit %v in chain profile %s", profile.Unit, globalOptChain)
+		}
+		for _, unitOpt := range unitOptions() {
+			if !unitOpt.cmd.Flags().Changed("unit") {
+				*unitOpt.value = profile.Unit
+			}
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestMergeConfigFile(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	t.Setenv("TEST_ARBISCAN_KEY", "abc123")
+
+	content := `
+default-chain = "arbitrum-staging"
+
+[chains.arbitrum-staging]
+rpc = ["https://rpc1.example.com", "https://rpc2.example.com"]
+tx-explorer-url = "https://sepolia.arbiscan.io/tx/"
+api-url = "https://api-sepolia.arbiscan.io/api"
+api-key = "${TEST_ARBISCAN_KEY}"
+signer = "keystore:/keys/$USER/pa$$word.json"
+tx-type = "eip155"
+unit = "gwei"
+
+[chains.mainnet]
+rpc = ["https://eth.example.com"]
+`
+	file := filepath.Join(t.TempDir(), "config.toml")
+	if err := os.WriteFile(file, []byte(content), 0600); err != nil {
+		t.Fatalf("write config failed: %v", err)
+	}
+
+	cfg, err := loadConfig(file)
+	if err != nil {
+		t.Fatalf("loadConfig failed: %v", err)
+	}
+
+	if cfg.DefaultChain != "arbitrum-staging" {
+		t.Fatalf("unexpected default chain %q", cfg.DefaultChain)
+	}
+
+	staging, ok := cfg.Chains["arbitrum-staging"]
+	if !ok {
+		t.Fatalf("profile arbitrum-staging not found")
+	}
+	if len(staging.Rpc) != 2 || staging.Rpc[1] != "https://rpc2.example.com" {
+		t.Fatalf("unexpected rpc %v", staging.Rpc)
+	}
+	if staging.ApiKey != "abc123" {
+		t.Fatalf("env var not expanded, got %q", staging.ApiKey)
+	}
+	if staging.Signer != "keystore:/keys/$USER/pa$$word.json" {
+		t.Fatalf("only ${NAME} should be expanded, got %q", staging.Signer)
+	}
+	if staging.TxType != txTypeEip155 || staging.Unit != unitGwei {
+		t.Fatalf("unexpected defaults %q %q", staging.TxType, staging.Unit)
+	}
+
+	// built-in profile is overridden field by field
+	mainnet := cfg.Chains[nodeMainnet]
+	if mainnet.Rpc[0] != "https://eth.example.com" {
+		t.Fatalf("rpc of mainnet not overridden, got %v", mainnet.Rpc)
+	}
+	if mainnet.TxExplorerUrl != "https://etherscan.io/tx/" {
+		t.Fatalf("explorer of mainnet should be kept, got %q", mainnet.TxExplorerUrl)
+	}
+
+	// built-in profiles are not modified
+	if builtinChainProfiles[nodeMainnet].Rpc[0] == "https://eth.example.com" {
+		t.Fatalf("built-in profile modified")
+	}
+}
+
+func TestLoadConfigMissingExplicitFile(t *testing.T) {
+	t.Setenv("XDG_CONFIG_HOME", t.TempDir())
+	if _, err := loadConfig(filepath.Join(t.TempDir(), "not-exist.toml")); err == nil {
+		t.Fatalf("expect error for missing config file")
+	}
+}
+
+func TestResolveSignerSpec(t *testing.T) {
+	const key = "0x4f66baf5a1c3a91b6cf8173cdb60d12496e1f572cee6f9f86bc507d87a9790d7"
+	t.Setenv("TEST_SIGNER_KEY", key+"\n")
+
+	keyFile := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(keyFile, []byte(key+"\n"), 0600); err != nil {
+		t.Fatalf("write key file failed: %v", err)
+	}
+
+	tests := []struct {
+		spec    string
+		want    string
+		wantErr bool
+	}{
+		{spec: "env:TEST_SIGNER_KEY", want: key},
+		{spec: "env:TEST_SIGNER_KEY_NOT_EXIST", wantErr: true},
+		{spec: "file:" + keyFile, want: key},
+		{spec: key, want: key},
+		{spec: "not-a-key", wantErr: true},
+	}
+
+	for i, tc := range tests {
+		got, err := resolveSignerSpec(tc.spec)
+		if tc.wantErr {
+			if err == nil {
+				t.Fatalf("test %d: expect error, got %q", i+1, got)
+			}
+			continue
+		}
+		if err != nil {
+			t.Fatalf("test %d: unexpected error %v", i+1, err)
+		}
+		if got != tc.want {
+			t.Fatalf("test %d: expected: %v, got: %v", i+1, tc.want, got)
+		}
+	}
+}
+
+func TestApplyProfileSignerLazily(t *testing.T) {
+	defer func() { globalOptPrivateKey, profileSignerSpec = "", "" }()
+
+	key := "0x4c0883a69102937d6231471b5dbb6204fe5129617082792ae468d01a3f362318"
+	applyProfileDefaults(&ChainProfile{Signer: "env:TEST_LAZY_SIGNER_KEY"})
+	if globalOptPrivateKey != "" || profileSignerSpec != "env:TEST_LAZY_SIGNER_KEY" {
+		t.Fatalf("signer should not be resolved before use, got key %q, spec %q", globalOptPrivateKey, profileSignerSpec)
+	}
+
+	t.Setenv("TEST_LAZY_SIGNER_KEY", key)
+	if !hasSigner() || globalOptPrivateKey != key {
+		t.Fatalf("expected: %v, got: %v", key, globalOptPrivateKey)
+	}
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+const create2FactoryEip2470 = "eip2470"
+const create2FactoryArachnid = "arachnid"
+const create2FactoryCreateX = "createx"
+
+// Arachnid's deterministic deployment proxy, see https://github.com/Arachnid/deterministic-deployment-proxy
+var arachnidFactoryAddr = common.HexToAddress("0x4e59b44847b379578588920cA78FbF26c0B4956C")
+
+// CreateX factory, see https://github.com/pcaversaccio/createx
+var createXFactoryAddr = common.HexToAddress("0xba5Ed099633D3B313e4D5F7bdc1305d3c28ba5Ed")
+
+// create2Factory is a factory contract which deploys contracts by CREATE2
+type create2Factory struct {
+	name    string // eip2470 | arachnid | createx, or the address of a custom factory
+	address common.Address
+}
+
+// parseCreate2Factory parses --factory, a custom factory must have the same interface as arachnid's proxy
+// (calldata is salt followed by init code)
+func parseCreate2Factory(factory string) (*create2Factory, error) {
+	switch strings.ToLower(factory) {
+	case create2FactoryEip2470:
+		return &create2Factory{name: create2FactoryEip2470, address: singletonFactoryAddr}, nil
+	case create2FactoryArachnid:
+		return &create2Factory{name: create2FactoryArachnid, address: arachnidFactoryAddr}, nil
+	case create2FactoryCreateX:
+		return &create2Factory{name: create2FactoryCreateX, address: createXFactoryAddr}, nil
+	}
+	if !isValidEthAddress(factory) {
+		return nil, fmt.Errorf("invalid --factory %s, it must be eip2470, arachnid, createx or an address", factory)
+	}
+	return &create2Factory{name: factory, address: common.HexToAddress(factory)}, nil
+}
+
+// calldata returns the input data of tx to the factory
+func (f *create2Factory) calldata(salt common.Hash, initCode []byte) ([]byte, error) {
+	switch f.name {
+	case create2FactoryEip2470:
+		return buildTxInputData("deploy(bytes,bytes32)", []string{"0x" + common.Bytes2Hex(initCode), salt.Hex()})
+	case create2FactoryCreateX:
+		return buildTxInputData("deployCreate2(bytes32,bytes)", []string{salt.Hex(), "0x" + common.Bytes2Hex(initCode)})
+	}
+	return append(salt.Bytes(), initCode...), nil
+}
+
+// computeAddress returns the address of contract deployed by the factory, the salt of CreateX is guarded by
+// sender and chainId
+func (f *create2Factory) computeAddress(salt common.Hash, initCode []byte, sender common.Address, chainId *big.Int) (common.Address, error) {
+	if f.name == create2FactoryCreateX {
+		guardedSalt, err := createXGuardedSalt(salt, sender, chainId)
+		if err != nil {
+			return common.Address{}, err
+		}
+		salt = guardedSalt
+	}
+	return crypto.CreateAddress2(f.address, salt, crypto.Keccak256(initCode)), nil
+}
+
+// createXGuardedSalt computes the salt used by CreateX, the first 20 bytes of salt can be the sender (permissioned
+// deploy protection) or zero, and the 21st byte (0x01 or 0x00) enables or disables the cross-chain redeploy
+// protection. See _guard in https://github.com/pcaversaccio/createx/blob/main/src/CreateX.sol
+func createXGuardedSalt(salt common.Hash, sender common.Address, chainId *big.Int) (common.Hash, error) {
+	saltSender := common.BytesToAddress(salt[:20])
+	flag := salt[20]
+
+	switch {
+	case saltSender == sender && flag == 0x01:
+		var encoded []byte
+		encoded = append(encoded, common.LeftPadBytes(sender.Bytes(), 32)...)
+		encoded = append(encoded, common.LeftPadBytes(chainId.Bytes(), 32)...)
+		encoded = append(encoded, salt.Bytes()...)
+		return crypto.Keccak256Hash(encoded), nil
+	case saltSender == sender && flag == 0x00:
+		return crypto.Keccak256Hash(common.LeftPadBytes(sender.Bytes(), 32), salt.Bytes()), nil
+	case saltSender == sender:
+		return common.Hash{}, fmt.Errorf("invalid salt of createx, the 21st byte must be 0x00 or 0x01 if the first 20 bytes are the sender")
+	case saltSender == (common.Address{}) && flag == 0x01:
+		return crypto.Keccak256Hash(common.LeftPadBytes(chainId.Bytes(), 32), salt.Bytes()), nil
+	case saltSender == (common.Address{}) && flag != 0x00:
+		return common.Hash{}, fmt.Errorf("invalid salt of createx, the 21st byte must be 0x00 or 0x01 if the first 20 bytes are zero")
+	}
+	return crypto.Keccak256Hash(salt.Bytes()), nil
+}
+
+// prepareCreate2 checks the factory is deployed, and returns the predicted address and calldata of factory. The
+// address is verified by eth_call to the factory. alreadyDeployed is true if the predicted address already has code.
+func prepareCreate2(rpcClient *rpc.Client, factory *create2Factory, salt common.Hash, initCode []byte, sender common.Address, value *big.Int, chainId *big.Int) (contractAddr common.Address, calldata []byte, alreadyDeployed bool, err error) {
+	factoryCode, err := codeAt(rpcClient, factory.address, "latest")
+	if err != nil {
+		return common.Address{}, nil, false, err
+	}
+	if len(factoryCode) == 0 {
+		return common.Address{}, nil, false, fmt.Errorf("create2 factory %s (%s) is not deployed on this chain", factory.name, factory.address.Hex())
+	}
+
+	contractAddr, err = factory.computeAddress(salt, initCode, sender, chainId)
+	if err != nil {
+		return common.Address{}, nil, false, err
+	}
+	calldata, err = factory.calldata(salt, initCode)
+	if err != nil {
+		return common.Address{}, nil, false, err
+	}
+
+	code, err := codeAt(rpcClient, contractAddr, "latest")
+	if err != nil {
+		return common.Address{}, nil, false, err
+	}
+	if len(code) > 0 {
+		return contractAddr, calldata, true, nil
+	}
+
+	// the factories return the address of new contract, eip2470 and createx return abi encoded address, arachnid
+	// returns the 20 bytes address
+	output, err := CallMsg(rpcClient, ethereum.CallMsg{From: sender, To: &factory.address, Value: value, Data: calldata})
+	if err != nil {
+		return common.Address{}, nil, false, fmt.Errorf("call create2 factory fail: %w", err)
+	}
+	if returnedAddr := common.BytesToAddress(output); returnedAddr != contractAddr {
+		return common.Address{}, nil, false, fmt.Errorf("the address returned by create2 factory is %s, but %s is expected", returnedAddr.Hex(), contractAddr.Hex())
+	}
+	return contractAddr, calldata, false, nil
+}
@@ -0,0 +1,135 @@
+package cmd
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestCreate2FactoryComputeAddress(t *testing.T) {
+	// the examples of EIP-1014
+	tests := []struct {
+		factory  string
+		salt     string
+		initCode string
+		expected string
+	}{
+		{"0x0000000000000000000000000000000000000000", "0x0000000000000000000000000000000000000000000000000000000000000000", "0x00", "0x4D1A2e2bB4F88F0250f26Ffff098B0b30B26BF38"},
+		{"0xdeadbeef00000000000000000000000000000000", "0x0000000000000000000000000000000000000000000000000000000000000000", "0x00", "0xB928f69Bb1D91Cd65274e3c79d8986362984fDA3"},
+		{"0xdeadbeef00000000000000000000000000000000", "0x000000000000000000000000feed000000000000000000000000000000000000", "0x00", "0xD04116cDd17beBE565EB2422F2497E06cC1C9833"},
+	}
+
+	for i, tc := range tests {
+		factory, err := parseCreate2Factory(tc.factory)
+		if err != nil {
+			t.Fatalf("test %d: parseCreate2Factory failed: %v", i+1, err)
+		}
+		got, err := factory.computeAddress(common.HexToHash(tc.salt), common.FromHex(tc.initCode), common.Address{}, big.NewInt(1))
+		if err != nil || got.Hex() != tc.expected {
+			t.Fatalf("test %d: expected: %v, got: %v (%v)", i+1, tc.expected, got.Hex(), err)
+		}
+	}
+
+	if _, err := parseCreate2Factory("unknown"); err == nil {
+		t.Fatalf("expect error for unknown factory")
+	}
+}
+
+func TestCreateXGuardedSalt(t *testing.T) {
+	sender := common.HexToAddress("0x8F36975cdeA2e6E64f85719788C8EFBBe89DFBbb")
+	chainId := big.NewInt(10)
+	abiEncodeHash := func(types []string, values []string) common.Hash {
+		encoded, err := encodeParameters(types, values)
+		if err != nil {
+			t.Fatalf("encodeParameters failed: %v", err)
+		}
+		return crypto.Keccak256Hash(encoded)
+	}
+
+	senderProtected := "0x8f36975cdea2e6e64f85719788c8efbbe89dfbbb01000000000000000000abcd"
+	senderOnly := "0x8f36975cdea2e6e64f85719788c8efbbe89dfbbb00000000000000000000abcd"
+	chainProtected := "0x000000000000000000000000000000000000000001000000000000000000abcd"
+	random := "0x1234567890123456789012345678901234567890ff000000000000000000abcd"
+
+	tests := []struct {
+		salt     string
+		expected common.Hash // zero if error is expected
+	}{
+		{senderProtected, abiEncodeHash([]string{"address", "uint256", "bytes32"}, []string{sender.Hex(), "10", senderProtected})},
+		{senderOnly, abiEncodeHash([]string{"bytes32", "bytes32"}, []string{common.BytesToHash(sender.Bytes()).Hex(), senderOnly})},
+		{chainProtected, abiEncodeHash([]string{"uint256", "bytes32"}, []string{"10", chainProtected})},
+		{random, abiEncodeHash([]string{"bytes32"}, []string{random})},
+		{"0x8f36975cdea2e6e64f85719788c8efbbe89dfbbb02000000000000000000abcd", common.Hash{}},
+		{"0x000000000000000000000000000000000000000002000000000000000000abcd", common.Hash{}},
+	}
+
+	for i, tc := range tests {
+		got, err := createXGuardedSalt(common.HexToHash(tc.salt), sender, chainId)
+		if tc.expected == (common.Hash{}) {
+			if err == nil {
+				t.Fatalf("test %d: expect error, got: %v", i+1, got.Hex())
+			}
+			continue
+		}
+		if err != nil || got != tc.expected {
+			t.Fatalf("test %d: expected: %v, got: %v (%v)", i+1, tc.expected.Hex(), got.Hex(), err)
+		}
+	}
+}
+
+// stubCreate2Eth is a stub of eth namespace, it simulates arachnid's deterministic deployment proxy
+type stubCreate2Eth struct {
+	factoryDeployed bool
+	deployed        map[common.Address]bool
+	wrongAddress    bool // the factory returns a wrong address
+}
+
+func (s *stubCreate2Eth) GetCode(addr common.Address, block string) (hexutil.Bytes, error) {
+	if (addr == arachnidFactoryAddr && s.factoryDeployed) || s.deployed[addr] {
+		return hexutil.Bytes{0x60, 0x80}, nil
+	}
+	return hexutil.Bytes{}, nil
+}
+
+func (s *stubCreate2Eth) Call(args map[string]interface{}, block string) (hexutil.Bytes, error) {
+	data := hexutil.MustDecode(args["data"].(string))
+	if s.wrongAddress {
+		return common.Address{}.Bytes(), nil
+	}
+	return crypto.CreateAddress2(arachnidFactoryAddr, common.BytesToHash(data[:32]), crypto.Keccak256(data[32:])).Bytes(), nil
+}
+
+func TestPrepareCreate2(t *testing.T) {
+	salt := common.HexToHash("0x01")
+	initCode := common.FromHex("0x6080604052348015600f57600080fd5b50603f80601d6000396000f3fe")
+	expectedAddr := crypto.CreateAddress2(arachnidFactoryAddr, salt, crypto.Keccak256(initCode))
+	factory, _ := parseCreate2Factory(create2FactoryArachnid)
+
+	tests := []struct {
+		stub            *stubCreate2Eth
+		expectErr       bool
+		alreadyDeployed bool
+	}{
+		{&stubCreate2Eth{factoryDeployed: true}, false, false},
+		{&stubCreate2Eth{factoryDeployed: true, deployed: map[common.Address]bool{expectedAddr: true}}, false, true},
+		{&stubCreate2Eth{factoryDeployed: false}, true, false},
+		{&stubCreate2Eth{factoryDeployed: true, wrongAddress: true}, true, false},
+	}
+
+	for i, tc := range tests {
+		rpcClient := dialStubRpc(t, map[string]any{"eth": tc.stub})
+		contractAddr, calldata, alreadyDeployed, err := prepareCreate2(rpcClient, factory, salt, initCode, common.Address{}, big.NewInt(0), big.NewInt(1))
+		if tc.expectErr {
+			if err == nil {
+				t.Fatalf("test %d: expect error", i+1)
+			}
+			continue
+		}
+		if err != nil || contractAddr != expectedAddr || alreadyDeployed != tc.alreadyDeployed || hexutil.Encode(calldata) != hexutil.Encode(append(salt.Bytes(), initCode...)) {
+			t.Fatalf("test %d: expected: %v (already deployed %v), got: %v (already deployed %v) (%v)", i+1, expectedAddr.Hex(), tc.alreadyDeployed, contractAddr.Hex(), alreadyDeployed, err)
+		}
+	}
+}
@@ -3,10 +3,12 @@ package cmd
 import (
 	"encoding/hex"
 	"fmt"
+	"log"
 	"strconv"
 	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/rlp"
@@ -42,14 +44,24 @@ var decodeTxCmd = &cobra.Command{
 			InitGlobalClient(globalOptNodeUrl)
 			rawTx, err := GetRawTx(globalClient.RpcClient, "0x"+rawTxHexData)
 			if err != nil {
-				fmt.Printf("get raw tx failed: %v\n", err)
-				return
+				log.Fatalf("get raw tx failed: %v", err)
 			}
 
-			fmt.Printf("rax tx = %s\n", rawTx)
+			if isJsonOutput() {
+				log.Printf("raw tx = %s", rawTx)
+			} else {
+				fmt.Printf("rax tx = %s\n", rawTx)
+			}
 			rawTxHexData = rawTx[2:] // remove leading 0x
 		}
 
+		if isJsonOutput() {
+			out, err := buildDecodedTxOutput(rawTxHexData)
+			checkErr(err)
+			printJson(out)
+			return
+		}
+
 		var firstHex = rawTxHexData[0:2]
 		transactionType, err := strconv.ParseInt(firstHex, 16, 64)
 		checkErr(err)
@@ -62,6 +74,136 @@ var decodeTxCmd = &cobra.Command{
 	},
 }
 
+// DecodedTxOutput is the json output (--output json) of decode-tx command.
+// All quantities are decimal strings, all binary data are 0x prefixed hex strings.
+type DecodedTxOutput struct {
+	Type                 string                 `json:"type"` // pre-eip155 | eip155 | eip2930 | eip1559 | eip4844 | eip7702
+	TypeNumber           uint8                  `json:"typeNumber"`
+	ChainId              *string                `json:"chainId"` // null for pre-eip155 tx
+	Nonce                uint64                 `json:"nonce"`
+	GasPrice             string                 `json:"gasPrice,omitempty"` // only for legacy and eip2930 tx
+	MaxPriorityFeePerGas string                 `json:"maxPriorityFeePerGas,omitempty"`
+	MaxFeePerGas         string                 `json:"maxFeePerGas,omitempty"`
+	GasLimit             uint64                 `json:"gasLimit"`
+	To                   *string                `json:"to"` // null means contract creation
+	Value                string                 `json:"value"`
+	Data                 string                 `json:"data"`
+	AccessList           types.AccessList       `json:"accessList,omitempty"`
+	AuthorizationList    []DecodedAuthorization `json:"authorizationList,omitempty"`
+	V                    string                 `json:"v"` // yParity for typed tx
+	R                    string                 `json:"r"`
+	S                    string                 `json:"s"`
+	Hash                 string                 `json:"hash"`
+	PreHash              string                 `json:"preHash"` // hash before ecdsa sign
+	SenderPublicKey      string                 `json:"senderPublicKey"`
+	Sender               string                 `json:"sender"`
+}
+
+// DecodedAuthorization is an item of EIP-7702 authorization list in DecodedTxOutput
+type DecodedAuthorization struct {
+	ChainId   string `json:"chainId"`
+	Address   string `json:"address"`
+	Nonce     uint64 `json:"nonce"`
+	YParity   uint8  `json:"yParity"`
+	R         string `json:"r"`
+	S         string `json:"s"`
+	Authority string `json:"authority,omitempty"` // derived from signature, empty if invalid
+}
+
+var txTypeNames = map[uint8]string{
+	types.AccessListTxType: "eip2930",
+	types.DynamicFeeTxType: "eip1559",
+	types.BlobTxType:       "eip4844",
+	types.SetCodeTxType:    "eip7702",
+}
+
+// buildDecodedTxOutput decodes raw tx (hex without 0x prefix) into DecodedTxOutput
+func buildDecodedTxOutput(rawTxHexData string) (*DecodedTxOutput, error) {
+	rawTxBytes, err := hex.DecodeString(rawTxHexData)
+	if err != nil {
+		return nil, fmt.Errorf("invalid hex: %w", err)
+	}
+	var tx types.Transaction
+	if err := tx.UnmarshalBinary(rawTxBytes); err != nil {
+		return nil, fmt.Errorf("decode failed, may not a valid eth raw transaction: %w", err)
+	}
+
+	var out = &DecodedTxOutput{
+		TypeNumber: tx.Type(),
+		Nonce:      tx.Nonce(),
+		GasLimit:   tx.Gas(),
+		Value:      tx.Value().String(),
+		Data:       hexutil.Encode(tx.Data()),
+		AccessList: tx.AccessList(),
+		Hash:       tx.Hash().Hex(),
+	}
+
+	var signer types.Signer
+	if tx.Type() == types.LegacyTxType && !tx.Protected() {
+		out.Type = "pre-eip155"
+		signer = types.HomesteadSigner{}
+	} else {
+		if tx.Type() == types.LegacyTxType {
+			out.Type = "eip155"
+		} else if name, ok := txTypeNames[tx.Type()]; ok {
+			out.Type = name
+		} else {
+			return nil, fmt.Errorf("not implemented for this transaction type %v", tx.Type())
+		}
+		chainId := tx.ChainId().String()
+		out.ChainId = &chainId
+		signer = types.LatestSignerForChainID(tx.ChainId())
+	}
+
+	if tx.Type() == types.LegacyTxType || tx.Type() == types.AccessListTxType {
+		out.GasPrice = tx.GasPrice().String()
+	} else {
+		out.MaxPriorityFeePerGas = tx.GasTipCap().String()
+		out.MaxFeePerGas = tx.GasFeeCap().String()
+	}
+	if tx.To() != nil {
+		to := tx.To().Hex()
+		out.To = &to
+	}
+
+	for _, auth := range tx.SetCodeAuthorizations() {
+		decodedAuth := DecodedAuthorization{
+			ChainId: auth.ChainID.String(),
+			Address: auth.Address.Hex(),
+			Nonce:   auth.Nonce,
+			YParity: auth.V,
+			R:       fmt.Sprintf("0x%064x", auth.R.ToBig()),
+			S:       fmt.Sprintf("0x%064x", auth.S.ToBig()),
+		}
+		if authority, err := auth.Authority(); err == nil {
+			decodedAuth.Authority = authority.Hex()
+		}
+		out.AuthorizationList = append(out.AuthorizationList, decodedAuth)
+	}
+
+	v, r, s := tx.RawSignatureValues()
+	out.V = v.String()
+	out.R = fmt.Sprintf("0x%064x", r)
+	out.S = fmt.Sprintf("0x%064x", s)
+
+	preHash := signer.Hash(&tx)
+	out.PreHash = preHash.Hex()
+
+	pubkeyBytes, err := RecoverPubkey(v, r, s, preHash.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("RecoverPubkey fail: %w", err)
+	}
+	out.SenderPublicKey = hexutil.Encode(pubkeyBytes)
+
+	sender, err := types.Sender(signer, &tx)
+	if err != nil {
+		return nil, fmt.Errorf("recover sender fail: %w", err)
+	}
+	out.Sender = sender.Hex()
+
+	return out, nil
+}
+
 func decodeLegacy(rawTxHexData string) {
 	var tx *types.Transaction
 	rawTxBytes, _ := hex.DecodeString(rawTxHexData)
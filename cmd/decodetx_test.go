@@ -0,0 +1,57 @@
+package cmd
+
+import (
+	"testing"
+)
+
+func TestBuildDecodedTxOutput(t *testing.T) {
+	tests := []struct {
+		rawTx   string
+		txType  string
+		chainId string
+		hash    string
+		preHash string
+		sender  string
+	}{
+		{
+			rawTx:   "f86c808504e3b2920082520894428cf082d321d435ff0e1f8a994e01f976f19c118809b5552f5abade008026a00a27decf27241dca4e5d82bd5b7c1fbcc3f09c35a2a05cb967f2983d148ad6aba0596e9baa40ab157f5b1b0d66746472550ba9000d4154e3faa43ccce00b030452",
+			txType:  "eip155",
+			chainId: "1",
+			hash:    "0xa8208564aa36d095973ce979df5bda03568ae0fb55f76517f1d91438bba84390",
+			preHash: "0x75fee2d3e846aacfcd167febf6af8d17b6fb73188a06bcf7cb5b626a347bad54",
+			sender:  "0xf7033D6010E8F2E12b810883e1c28CAcd6D25B16",
+		},
+		{
+			// the first tx of Ethereum mainnet
+			rawTx:  "f86780862d79883d2000825208945df9b87991262f6ba471f09758cde1c0fc1de734827a69801ca088ff6cf0fefd94db46111149ae4bfc179e9b94721fffd821d38d16464b3f71d0a045e0aff800961cfce805daef7016b9b675c137a6a41a548f7b60a3484c06a33a",
+			txType: "pre-eip155",
+			hash:   "0x5c504ed432cb51138bcf09aa5e8a410dd4a1e204ef84bfed1be16dfba1b22060",
+			sender: "0xA1E4380A3B1f749673E270229993eE55F35663b4",
+		},
+	}
+
+	for i, tc := range tests {
+		got, err := buildDecodedTxOutput(tc.rawTx)
+		if err != nil {
+			t.Fatalf("test %d: unexpected error %v", i+1, err)
+		}
+		if got.Type != tc.txType {
+			t.Fatalf("test %d: expected: %v, got: %v", i+1, tc.txType, got.Type)
+		}
+		if tc.chainId == "" && got.ChainId != nil {
+			t.Fatalf("test %d: expected nil chainId, got: %v", i+1, *got.ChainId)
+		}
+		if tc.chainId != "" && (got.ChainId == nil || *got.ChainId != tc.chainId) {
+			t.Fatalf("test %d: expected: %v, got: %v", i+1, tc.chainId, got.ChainId)
+		}
+		if got.Hash != tc.hash {
+			t.Fatalf("test %d: expected: %v, got: %v", i+1, tc.hash, got.Hash)
+		}
+		if tc.preHash != "" && got.PreHash != tc.preHash {
+			t.Fatalf("test %d: expected: %v, got: %v", i+1, tc.preHash, got.PreHash)
+		}
+		if got.Sender != tc.sender {
+			t.Fatalf("test %d: expected: %v, got: %v", i+1, tc.sender, got.Sender)
+		}
+	}
+}
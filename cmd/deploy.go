@@ -1,15 +1,15 @@
 package cmd
 
 import (
-	"bytes"
 	"encoding/hex"
 	"fmt"
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/shopspring/decimal"
 	"github.com/spf13/cobra"
 	"log"
+	"math/big"
+	"net/url"
 	"os"
-	"os/exec"
-	"path/filepath"
 	"regexp"
 	"strings"
 )
@@ -17,130 +17,214 @@ import (
 var deployABIFile string
 var deployBinFile string
 var deploySrcFile string
+var deployStandardJsonFile string
 var deployContractName string
 var deployValueUnit string
 var deployValue string
+var deployLibraries []string
+var deployCreate2 bool
+var deploySalt string
+var deployFactory string
+var deployVerify bool
 
 func init() {
 	deployCmd.Flags().StringVarP(&deployABIFile, "abi-file", "", "", "the path of abi file, if 'constructor signature' is specified, this option cannot be specified")
 	deployCmd.Flags().StringVarP(&deployBinFile, "bin-file", "", "", "the path of byte code file of contract")
-	deployCmd.Flags().StringVarP(&deploySrcFile, "src-file", "", "", "the path of source file of contract, launch tool solcjs to compile it. If this option is specified, --bin-file, --abi-file, 'constructor signature' cannot be specified")
-	deployCmd.Flags().StringVarP(&deployContractName, "contract-name", "", "", "the contract in source file you want to deploy, if it's not specified, auto find the LAST contract in source file")
-	deployCmd.Flags().StringVarP(&deployValueUnit, "unit", "u", "ether", "wei | gwei | ether, unit of amount")
+	deployCmd.Flags().StringVarP(&deploySrcFile, "src-file", "", "", "the path of source file of contract, compile it by --solc (solcjs by default). If this option is specified, --bin-file, --abi-file, 'constructor signature' cannot be specified")
+	deployCmd.Flags().StringVarP(&deployStandardJsonFile, "standard-json", "", "", "the path of solc standard json input file, compile it by --solc (solcjs by default), the compiler options override its settings")
+	deployCmd.Flags().StringVarP(&deployContractName, "contract-name", "", "", "the contract you want to deploy, format: Name or file.sol:Name. If it's not specified, auto find the LAST contract in --src-file")
+	deployCmd.Flags().StringVarP(&deployValueUnit, "unit", "u", "ether", "wei | gwei | ether, unit of amount, ether means the native currency of chain (its symbol is also accepted, e.g. POL)")
 	deployCmd.Flags().StringVarP(&deployValue, "value", "", "0", "the amount you want to transfer when deploy contract, unit is ether and can be changed by --unit")
-
+	addCompilerFlags(deployCmd)
+	deployCmd.Flags().StringArrayVarP(&deployLibraries, "library", "", nil, "link library address into the placeholders of bytecode, format: <[file.sol:]Name>=<address>, can be repeated")
+	deployCmd.Flags().BoolVarP(&deployCreate2, "create2", "", false, "deploy contract by CREATE2 through --factory, the address is determined by factory, --salt and init code")
+	deployCmd.Flags().StringVarP(&deploySalt, "salt", "", "", "the 32 bytes hex salt of --create2")
+	deployCmd.Flags().BoolVarP(&deployVerify, "verify", "", false, "verify source code of contract on block explorer after deployment, it requires --src-file or --standard-json")
+	deployCmd.Flags().StringVarP(&deployFactory, "factory", "", create2FactoryEip2470, "the factory of --create2, eip2470 | arachnid | createx | <address>, a custom factory must accept calldata salt + init code like arachnid's proxy")
 }
 
 var deployCmd = &cobra.Command{
 	Use:   "deploy <constructor-signature> [arg1 arg2 ...]",
 	Short: "Deploy contract",
 	Run: func(cmd *cobra.Command, args []string) {
-		if deployBinFile == "" && deploySrcFile == "" {
-			log.Fatalf("must specify --bin-file or --src-file")
+		if deployBinFile == "" && deploySrcFile == "" && deployStandardJsonFile == "" {
+			log.Fatalf("must specify --bin-file, --src-file or --standard-json")
+		}
+		if deploySrcFile != "" && deployStandardJsonFile != "" {
+			log.Fatalf("--src-file and --standard-json cannot be specified at the same time")
+		}
+		if deployVerify && deploySrcFile == "" && deployStandardJsonFile == "" {
+			log.Fatalf("--verify requires --src-file or --standard-json")
 		}
+		var salt common.Hash
+		if deployCreate2 {
+			if len(remove0xPrefix(deploySalt)) != 64 || !isValidHexString(deploySalt) {
+				log.Fatalf("--salt must be 32 bytes hex string when --create2 is specified")
+			}
+			salt = common.HexToHash(deploySalt)
+		}
+		libraries, err := parseLibraries(deployLibraries)
+		checkErr(err)
 
 		InitGlobalClient(globalOptNodeUrl)
 
 		var funcSignature string
 		var inputArgData []string
-		var bytecodeByteArray []byte
+		var abiContent []byte
+		var bytecodeHex string
+		var linkReferences map[string]map[string][]solcLinkReference
+		var verifyReq *verifyRequest
 
-		if deploySrcFile != "" { // source file provided
-			// compile source using solcjs, set deployABIFile, deployBinFile
-
-			dir, err := os.MkdirTemp("", "ethutil-deploy")
-			if err != nil {
-				log.Fatal(err)
-			}
-			defer os.RemoveAll(dir)
-
-			//log.Printf("solcjs output to dir %v", dir)
-
-			cmd := exec.Command("solcjs", "--base-path", ".", "--include-path", "./node_modules", "--bin", "--abi", "--output-dir", dir, deploySrcFile)
-			log.Printf("executing command %v", cmd.String())
-			var out bytes.Buffer
-			var stderr bytes.Buffer
-			cmd.Stdout = &out
-			cmd.Stderr = &stderr
-			err = cmd.Run()
-			if err != nil {
-				log.Fatal(fmt.Sprint(err) + ": " + stderr.String())
-			}
-
-			if deployContractName == "" {
-				log.Printf("option --contract-name is not specified, use last contract in source file")
-				// If contract name is not specified, use last contract in source file
-				deployContractName = findContractName(deploySrcFile)
-				if len(deployContractName) == 0 {
-					log.Fatalf("no contract name found in file %v", deploySrcFile)
-				}
-			}
-
-			log.Printf("deploying contract %v", deployContractName)
-
-			abiMatches, err := filepath.Glob(fmt.Sprintf("%v/*_%v.abi", dir, deployContractName))
+		if deploySrcFile != "" || deployStandardJsonFile != "" { // compile source
+			contract, input, output, err := compileContract(compilerSettings(cmd), deploySrcFile, deployStandardJsonFile, deployContractName)
 			checkErr(err)
-			if len(abiMatches) > 1 {
-				log.Fatalf("more than one abi files found: %v", abiMatches)
+			log.Printf("deploying contract %v:%v", contract.File, contract.Name)
+			abiContent = contract.ABI
+			bytecodeHex = contract.Bytecode
+			linkReferences = contract.LinkReferences
+
+			if deployVerify {
+				// prepare verification before deployment, so nothing is deployed if it fails
+				_, err := explorerApiUrl(url.Values{})
+				checkErr(err)
+				verifyReq = &verifyRequest{codeFormat: verifyCodeFormatStandardJson, contractName: contract.File + ":" + contract.Name}
+				sourceCode, err := verificationInput(input, output, linkedLibraries(linkReferences, libraries))
+				checkErr(err)
+				verifyReq.sourceCode = string(sourceCode)
+				verifyReq.compilerVersion, err = solcVersion(compilerSolc)
+				checkErr(err)
 			}
-			deployABIFile = abiMatches[0]
-
-			binMatches, err := filepath.Glob(fmt.Sprintf("%v/*_%v.bin", dir, deployContractName))
+		} else {
+			if deployABIFile != "" {
+				abiContent, err = os.ReadFile(deployABIFile)
+				checkErr(err)
+			}
+			bytecode, err := os.ReadFile(deployBinFile)
 			checkErr(err)
-			if len(binMatches) > 1 {
-				log.Fatalf("more than one bin files found: %v", binMatches)
+			bytecodeHex = strings.TrimSpace(string(bytecode))
+			// remove leading 0x
+			if strings.HasPrefix(bytecodeHex, "0x") {
+				bytecodeHex = bytecodeHex[2:]
 			}
-			deployBinFile = binMatches[0]
 		}
 
-		if deployABIFile == "" { // abi file not provided
+		if abiContent == nil { // abi not provided
 			if len(args) > 0 {
 				funcSignature = args[0]
 				inputArgData = args[1:]
 			}
-		} else { // abi file provided
-			abiContent, err := os.ReadFile(deployABIFile)
-			checkErr(err)
+		} else { // abi provided
+			setRevertErrorsABI(abiContent)
+			setTxEventsABI(abiContent)
 
-			funcSignature, err = extractFuncDefinition(string(abiContent), "constructor")
+			funcSignature, err = constructorDefinition(abiContent)
 			checkErr(err)
 			// log.Printf("extract func definition from abi: %v", funcSignature)
 
 			inputArgData = args[0:]
+			if funcSignature == "" && len(inputArgData) > 0 {
+				log.Fatalf("invalid input, there are no args in constructor, but %v args are provided", len(inputArgData))
+			}
 		}
 
-		bytecode, err := os.ReadFile(deployBinFile)
+		bytecodeHex, err = linkBytecode(bytecodeHex, linkReferences, libraries)
 		checkErr(err)
-
-		var bytecodeHex = strings.TrimSpace(string(bytecode))
-		// remove leading 0x
-		if strings.HasPrefix(bytecodeHex, "0x") {
-			bytecodeHex = bytecodeHex[2:]
-		}
-		bytecodeByteArray, err = hex.DecodeString(bytecodeHex)
+		bytecodeByteArray, err := hex.DecodeString(bytecodeHex)
 		if err != nil {
-			log.Fatal("--bin-file invalid")
+			log.Fatal("bytecode of contract invalid")
 		}
 
 		txData, err := buildTxDataForContractDeploy(funcSignature, inputArgData, bytecodeByteArray)
 		checkErr(err)
 		// log.Printf("txData=%s", hex.Dump(txData))
 
-		if globalOptPrivateKey == "" {
-			log.Fatalf("--private-key is required for deploy command")
+		if !hasSigner() {
+			log.Fatalf("%s is required for deploy command", signerOptions)
 		}
 
 		var value = decimal.RequireFromString(deployValue)
 		var valueInWei = unify2Wei(value, deployValueUnit)
 
-		tx, err := Transact(globalClient.RpcClient, globalClient.EthClient, hexToPrivateKey(globalOptPrivateKey), nil, valueInWei.BigInt(), nil, txData)
-		checkErr(err)
+		var out *TxOutput
+		if deployCreate2 {
+			out = deployByCreate2(salt, txData, valueInWei.BigInt())
+			if out == nil { // already deployed
+				return
+			}
+		} else {
+			out, err = TransactTx(globalClient.RpcClient, globalClient.EthClient, loadSigner(), nil, valueInWei.BigInt(), nil, txData)
+			checkErr(err)
+		}
 
-		log.Printf("transaction %s finished", tx)
+		if verifyReq != nil {
+			if out.Status == "success" {
+				verifyReq.address = out.ContractAddress
+				verifyReq.constructorArgs = txData[len(bytecodeByteArray):]
+				// the contract is deployed, the failure of verification is reported with the tx output
+				out.Verification, err = verifyContract(*verifyReq)
+				if err != nil {
+					out.Verification = &VerifyOutput{Address: out.ContractAddress, Status: "failed", Message: err.Error()}
+				}
+				if !isJsonOutput() {
+					printVerifyOutput(out.Verification)
+				}
+			} else {
+				log.Printf("tx is not mined, skip verification")
+			}
+		}
 
+		printTxOutput(out)
 	},
 }
 
+// deployByCreate2 deploys contract by CREATE2 through --factory, it does nothing and returns nil if the contract is
+// already deployed
+func deployByCreate2(salt common.Hash, initCode []byte, value *big.Int) *TxOutput {
+	factory, err := parseCreate2Factory(deployFactory)
+	checkErr(err)
+	chainId, _ := new(big.Int).SetString(globalChainId, 10)
+	signer := loadSigner()
+
+	contractAddr, calldata, alreadyDeployed, err := prepareCreate2(globalClient.RpcClient, factory, salt, initCode, signer.Address(), value, chainId)
+	checkErr(err)
+	if alreadyDeployed {
+		log.Printf("contract already deployed at %v, skip deployment", contractAddr.Hex())
+		if isJsonOutput() {
+			printJson(ContractAddrOutput{Deployer: factory.address.Hex(), Salt: salt.Hex(), ContractAddress: contractAddr.Hex()})
+		}
+		return nil
+	}
+	log.Printf("deploying contract to %v by create2 factory %v (%v)", contractAddr.Hex(), factory.name, factory.address.Hex())
+
+	out, err := TransactTx(globalClient.RpcClient, globalClient.EthClient, signer, &factory.address, value, nil, calldata)
+	checkErr(err)
+	out.ContractAddress = contractAddr.Hex()
+
+	if out.Status == "success" {
+		// the contract address is verified before sending tx, check it again after tx is mined
+		deployed, err := isContractAddress(globalClient.EthClient, contractAddr)
+		checkErr(err)
+		if !deployed {
+			log.Fatalf("tx %v mined, but no contract found at %v", out.TxHash, contractAddr.Hex())
+		}
+		log.Printf("the new contract deployed at %v", contractAddr.Hex())
+	}
+	return out
+}
+
+// constructorDefinition returns the constructor definition in abi, it's empty if there is no constructor or the
+// constructor has no args
+func constructorDefinition(abiContent []byte) (string, error) {
+	contractABI, err := parseContractABI(abiContent)
+	if err != nil {
+		return "", err
+	}
+	if len(contractABI.Constructor.Inputs) == 0 {
+		return "", nil
+	}
+	return extractFuncDefinition(string(abiContent), "constructor")
+}
+
 // findContractName find last contract name in source file
 func findContractName(deploySrcFile string) string {
 	srcContent, err := os.ReadFile(deploySrcFile)
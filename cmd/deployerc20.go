@@ -55,13 +55,13 @@ var deployErc20Cmd = &cobra.Command{
 		checkErr(err)
 		// log.Printf("txData=%s", hex.Dump(txData))
 
-		if globalOptPrivateKey == "" {
-			log.Fatalf("--private-key is required for deploy command")
+		if !hasSigner() {
+			log.Fatalf("%s is required for deploy command", signerOptions)
 		}
 
-		tx, err := Transact(globalClient.RpcClient, globalClient.EthClient, hexToPrivateKey(globalOptPrivateKey), nil, big.NewInt(0), nil, txData)
+		out, err := TransactTx(globalClient.RpcClient, globalClient.EthClient, loadSigner(), nil, big.NewInt(0), nil, txData)
 		checkErr(err)
 
-		log.Printf("transaction %s finished", tx)
+		printTxOutput(out)
 	},
 }
@@ -0,0 +1,493 @@
+package cmd
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/cobra"
+)
+
+var disasmCmdSummary bool
+var disasmCmdNoLookup bool
+
+func init() {
+	disasmCmd.Flags().BoolVarP(&disasmCmdSummary, "summary", "", false, "only show metadata, function selectors and required forks, instructions are not shown")
+	disasmCmd.Flags().BoolVarP(&disasmCmdNoLookup, "no-lookup", "", false, "do not look up signatures of function selectors online")
+}
+
+// opcodeNames is the names of opcodes, the opcodes not in it are shown as UNKNOWN(0x..)
+var opcodeNames = map[byte]string{
+	0x00: "STOP", 0x01: "ADD", 0x02: "MUL", 0x03: "SUB", 0x04: "DIV", 0x05: "SDIV", 0x06: "MOD", 0x07: "SMOD",
+	0x08: "ADDMOD", 0x09: "MULMOD", 0x0a: "EXP", 0x0b: "SIGNEXTEND",
+	0x10: "LT", 0x11: "GT", 0x12: "SLT", 0x13: "SGT", 0x14: "EQ", 0x15: "ISZERO", 0x16: "AND", 0x17: "OR",
+	0x18: "XOR", 0x19: "NOT", 0x1a: "BYTE", 0x1b: "SHL", 0x1c: "SHR", 0x1d: "SAR", 0x1e: "CLZ",
+	0x20: "KECCAK256",
+	0x30: "ADDRESS", 0x31: "BALANCE", 0x32: "ORIGIN", 0x33: "CALLER", 0x34: "CALLVALUE", 0x35: "CALLDATALOAD",
+	0x36: "CALLDATASIZE", 0x37: "CALLDATACOPY", 0x38: "CODESIZE", 0x39: "CODECOPY", 0x3a: "GASPRICE",
+	0x3b: "EXTCODESIZE", 0x3c: "EXTCODECOPY", 0x3d: "RETURNDATASIZE", 0x3e: "RETURNDATACOPY", 0x3f: "EXTCODEHASH",
+	0x40: "BLOCKHASH", 0x41: "COINBASE", 0x42: "TIMESTAMP", 0x43: "NUMBER", 0x44: "PREVRANDAO", 0x45: "GASLIMIT",
+	0x46: "CHAINID", 0x47: "SELFBALANCE", 0x48: "BASEFEE", 0x49: "BLOBHASH", 0x4a: "BLOBBASEFEE",
+	0x50: "POP", 0x51: "MLOAD", 0x52: "MSTORE", 0x53: "MSTORE8", 0x54: "SLOAD", 0x55: "SSTORE", 0x56: "JUMP",
+	0x57: "JUMPI", 0x58: "PC", 0x59: "MSIZE", 0x5a: "GAS", 0x5b: "JUMPDEST", 0x5c: "TLOAD", 0x5d: "TSTORE",
+	0x5e: "MCOPY", 0x5f: "PUSH0",
+	0xa0: "LOG0", 0xa1: "LOG1", 0xa2: "LOG2", 0xa3: "LOG3", 0xa4: "LOG4",
+	0xf0: "CREATE", 0xf1: "CALL", 0xf2: "CALLCODE", 0xf3: "RETURN", 0xf4: "DELEGATECALL", 0xf5: "CREATE2",
+	0xfa: "STATICCALL", 0xfd: "REVERT", 0xfe: "INVALID", 0xff: "SELFDESTRUCT",
+}
+
+func init() {
+	for i := 1; i <= 32; i++ {
+		opcodeNames[byte(0x5f+i)] = fmt.Sprintf("PUSH%d", i)
+	}
+	for i := 1; i <= 16; i++ {
+		opcodeNames[byte(0x7f+i)] = fmt.Sprintf("DUP%d", i)
+		opcodeNames[byte(0x8f+i)] = fmt.Sprintf("SWAP%d", i)
+	}
+}
+
+// opcodeForks is the opcodes introduced by recent hard forks, the code using them can't run on chains without the fork
+var opcodeForks = []struct {
+	op   string
+	fork string
+}{
+	{"PUSH0", "shanghai"},
+	{"TLOAD", "cancun"},
+	{"TSTORE", "cancun"},
+	{"MCOPY", "cancun"},
+	{"BLOBHASH", "cancun"},
+	{"BLOBBASEFEE", "cancun"},
+	{"CLZ", "osaka"},
+}
+
+// Instruction is an instruction of bytecode
+type Instruction struct {
+	Pc       int    `json:"pc"`
+	Op       string `json:"op"`
+	PushData string `json:"pushData,omitempty"` // only for PUSH1 - PUSH32
+}
+
+// BytecodeMetadata is the CBOR encoded metadata appended to bytecode by compiler
+// See https://docs.soliditylang.org/en/latest/metadata.html#encoding-of-the-metadata-hash-in-the-bytecode
+type BytecodeMetadata struct {
+	Compiler     string `json:"compiler,omitempty"` // solc | vyper, empty if unknown
+	Version      string `json:"version,omitempty"`
+	Ipfs         string `json:"ipfs,omitempty"` // CIDv0 of metadata file
+	Bzzr0        string `json:"bzzr0,omitempty"`
+	Bzzr1        string `json:"bzzr1,omitempty"`
+	Experimental bool   `json:"experimental,omitempty"`
+	Raw          string `json:"raw"` // the CBOR and its length
+}
+
+// String returns the compiler version and the hashes of metadata
+func (m *BytecodeMetadata) String() string {
+	var fields []string
+	if m.Compiler != "" {
+		fields = append(fields, m.Compiler+" "+m.Version)
+	}
+	if m.Ipfs != "" {
+		fields = append(fields, "ipfs "+m.Ipfs)
+	}
+	if m.Bzzr0 != "" {
+		fields = append(fields, "bzzr0 "+m.Bzzr0)
+	}
+	if m.Bzzr1 != "" {
+		fields = append(fields, "bzzr1 "+m.Bzzr1)
+	}
+	if m.Experimental {
+		fields = append(fields, "experimental")
+	}
+	return strings.Join(fields, ", ")
+}
+
+// SelectorInfo is a function selector found in the dispatcher
+type SelectorInfo struct {
+	Selector   string   `json:"selector"`
+	Signatures []string `json:"signatures"` // empty if not found by online lookup
+}
+
+// ForkRequirement is the usage of opcode introduced by a hard fork
+type ForkRequirement struct {
+	Op    string `json:"op"`
+	Fork  string `json:"fork"`
+	Count int    `json:"count"`
+}
+
+// DisasmOutput is the json output (--output json) of disasm command
+type DisasmOutput struct {
+	CodeSize     int               `json:"codeSize"`
+	Metadata     *BytecodeMetadata `json:"metadata,omitempty"`
+	Selectors    []SelectorInfo    `json:"selectors"`
+	RequireForks []ForkRequirement `json:"requireForks"`
+	Instructions []Instruction     `json:"instructions,omitempty"` // empty if --summary
+}
+
+var disasmCmd = &cobra.Command{
+	Use:   "disasm <hex-code|file|address>",
+	Short: "Disassemble EVM bytecode, show metadata, function selectors and opcodes requiring recent hard forks",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		code, err := loadDisasmCode(args[0])
+		checkErr(err)
+
+		var lookupFn funcSigLookup = GetFuncSig
+		if disasmCmdNoLookup {
+			lookupFn = nil
+		}
+		out := disassemble(code, lookupFn)
+		if disasmCmdSummary {
+			out.Instructions = nil
+		}
+
+		if isJsonOutput() {
+			printJson(out)
+			return
+		}
+		printDisasm(os.Stdout, out)
+	},
+}
+
+// loadDisasmCode loads code from chain if arg is an address, or from file if it exists, otherwise arg is hex code
+func loadDisasmCode(arg string) ([]byte, error) {
+	if isValidEthAddress(arg) {
+		InitGlobalClient(globalOptNodeUrl)
+		code, err := codeAt(globalClient.RpcClient, common.HexToAddress(arg), "latest")
+		if err != nil {
+			return nil, err
+		}
+		if len(code) == 0 {
+			return nil, fmt.Errorf("no runtime bytecode found for %v", arg)
+		}
+		return code, nil
+	}
+
+	if content, err := os.ReadFile(arg); err == nil {
+		trimmed := strings.TrimSpace(string(content))
+		if isValidHexString(trimmed) {
+			return hex.DecodeString(remove0xPrefix(trimmed))
+		}
+		// binary file
+		return content, nil
+	}
+
+	if !isValidHexString(arg) {
+		return nil, fmt.Errorf("%v is not a hex string, an existing file or an address", arg)
+	}
+	return hex.DecodeString(remove0xPrefix(arg))
+}
+
+// disassemble disassembles code, the metadata is split off before disassembling. Signatures of selectors are
+// looked up by lookupFn if it's not nil.
+func disassemble(code []byte, lookupFn funcSigLookup) *DisasmOutput {
+	var out = &DisasmOutput{CodeSize: len(code), Selectors: []SelectorInfo{}, RequireForks: []ForkRequirement{}}
+
+	body, metadata := splitMetadata(code)
+	out.Metadata = metadata
+	out.Instructions = disassembleInstructions(body)
+
+	for _, selector := range findSelectors(out.Instructions) {
+		var info = SelectorInfo{Selector: selector, Signatures: []string{}}
+		if lookupFn != nil {
+			if sigs, err := lookupFn(selector); err == nil && len(sigs) > 0 {
+				info.Signatures = sigs
+			}
+		}
+		out.Selectors = append(out.Selectors, info)
+	}
+
+	for _, opFork := range opcodeForks {
+		var count = 0
+		for _, ins := range out.Instructions {
+			if ins.Op == opFork.op {
+				count++
+			}
+		}
+		if count > 0 {
+			out.RequireForks = append(out.RequireForks, ForkRequirement{Op: opFork.op, Fork: opFork.fork, Count: count})
+		}
+	}
+	return out
+}
+
+// disassembleInstructions disassembles code into instructions, the push data may be truncated at the end of code
+func disassembleInstructions(code []byte) []Instruction {
+	var instructions []Instruction
+	for pc := 0; pc < len(code); pc++ {
+		op := code[pc]
+		name, ok := opcodeNames[op]
+		if !ok {
+			name = fmt.Sprintf("UNKNOWN(0x%02x)", op)
+		}
+		var ins = Instruction{Pc: pc, Op: name}
+		if op >= 0x60 && op <= 0x7f { // PUSH1 - PUSH32
+			size := int(op) - 0x5f
+			end := pc + 1 + size
+			if end > len(code) {
+				end = len(code)
+			}
+			ins.PushData = hexutil.Encode(code[pc+1 : end])
+			pc = end - 1
+		}
+		instructions = append(instructions, ins)
+	}
+	return instructions
+}
+
+// findSelectors finds function selectors in the dispatcher, it's the pattern PUSH4 <selector> (DUPn/SWAPn) EQ|XOR
+// PUSHn <dest> JUMPI, EQ is used by solc and XOR is used by vyper
+func findSelectors(instructions []Instruction) []string {
+	var selectors []string
+	var seen = make(map[string]bool)
+	for i, ins := range instructions {
+		if ins.Op != "PUSH4" || ins.PushData == "0xffffffff" || len(ins.PushData) != 10 {
+			continue
+		}
+		j := i + 1
+		for j < len(instructions) && j <= i+2 && (strings.HasPrefix(instructions[j].Op, "DUP") || strings.HasPrefix(instructions[j].Op, "SWAP")) {
+			j++
+		}
+		if j+2 >= len(instructions) || (instructions[j].Op != "EQ" && instructions[j].Op != "XOR") ||
+			!strings.HasPrefix(instructions[j+1].Op, "PUSH") || instructions[j+2].Op != "JUMPI" {
+			continue
+		}
+		if !seen[ins.PushData] {
+			seen[ins.PushData] = true
+			selectors = append(selectors, ins.PushData)
+		}
+	}
+	return selectors
+}
+
+// splitMetadata splits off the CBOR metadata at the end of code, the last 2 bytes are the length of CBOR (vyper
+// since 0.3.10 includes the 2 bytes in the length). metadata is nil if it's not found.
+func splitMetadata(code []byte) ([]byte, *BytecodeMetadata) {
+	if len(code) < 2 {
+		return code, nil
+	}
+	length := int(binary.BigEndian.Uint16(code[len(code)-2:]))
+	for _, start := range []int{len(code) - 2 - length, len(code) - length} {
+		if start < 0 || start >= len(code)-2 {
+			continue
+		}
+		value, n, err := decodeCbor(code[start : len(code)-2])
+		if err != nil || n != len(code)-2-start {
+			continue
+		}
+		if metadata := buildBytecodeMetadata(value); metadata != nil {
+			metadata.Raw = hexutil.Encode(code[start:])
+			return code[:start], metadata
+		}
+	}
+	return code, nil
+}
+
+// buildBytecodeMetadata builds metadata from the decoded CBOR, solc appends a map, and vyper since 0.3.10 appends an
+// array with the map {"vyper": [major, minor, patch]} as the last element
+func buildBytecodeMetadata(value interface{}) *BytecodeMetadata {
+	if array, ok := value.([]interface{}); ok && len(array) > 0 {
+		value = array[len(array)-1]
+	}
+	fields, ok := value.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	var metadata = &BytecodeMetadata{}
+	var known = false
+	if ipfs, ok := fields["ipfs"].([]byte); ok {
+		metadata.Ipfs = base58Encode(ipfs)
+		known = true
+	}
+	if bzzr0, ok := fields["bzzr0"].([]byte); ok {
+		metadata.Bzzr0 = hexutil.Encode(bzzr0)
+		known = true
+	}
+	if bzzr1, ok := fields["bzzr1"].([]byte); ok {
+		metadata.Bzzr1 = hexutil.Encode(bzzr1)
+		known = true
+	}
+	if experimental, ok := fields["experimental"].(bool); ok {
+		metadata.Experimental = experimental
+	}
+	switch version := fields["solc"].(type) {
+	case []byte: // release version, 3 bytes of major, minor and patch
+		metadata.Compiler = "solc"
+		var parts []string
+		for _, b := range version {
+			parts = append(parts, fmt.Sprint(b))
+		}
+		metadata.Version = strings.Join(parts, ".")
+	case string: // pre-release version
+		metadata.Compiler = "solc"
+		metadata.Version = version
+	}
+	if version, ok := fields["vyper"].([]interface{}); ok {
+		metadata.Compiler = "vyper"
+		var parts []string
+		for _, v := range version {
+			parts = append(parts, fmt.Sprint(v))
+		}
+		metadata.Version = strings.Join(parts, ".")
+	}
+	if !known && metadata.Compiler == "" {
+		return nil
+	}
+	return metadata
+}
+
+// decodeCbor decodes a CBOR data item, returns the value and the number of bytes consumed. Only the types used by
+// metadata are supported: unsigned integer, byte string, text string, array, map and bool.
+func decodeCbor(data []byte) (interface{}, int, error) {
+	if len(data) == 0 {
+		return nil, 0, fmt.Errorf("unexpected end of cbor")
+	}
+	major := data[0] >> 5
+	info := data[0] & 0x1f
+
+	if major == 7 {
+		switch info {
+		case 20:
+			return false, 1, nil
+		case 21:
+			return true, 1, nil
+		case 22:
+			return nil, 1, nil
+		}
+		return nil, 0, fmt.Errorf("unsupported cbor simple value %d", info)
+	}
+
+	// the argument of data item
+	var arg uint64
+	var n int
+	switch {
+	case info < 24:
+		arg, n = uint64(info), 1
+	case info <= 27:
+		size := 1 << (info - 24)
+		if len(data) < 1+size {
+			return nil, 0, fmt.Errorf("unexpected end of cbor")
+		}
+		arg, n = new(big.Int).SetBytes(data[1:1+size]).Uint64(), 1+size
+	default:
+		return nil, 0, fmt.Errorf("unsupported cbor additional info %d", info)
+	}
+
+	switch major {
+	case 0:
+		return arg, n, nil
+	case 2, 3:
+		if uint64(len(data)-n) < arg {
+			return nil, 0, fmt.Errorf("unexpected end of cbor")
+		}
+		content := data[n : n+int(arg)]
+		if major == 3 {
+			return string(content), n + int(arg), nil
+		}
+		return content, n + int(arg), nil
+	case 4:
+		var array []interface{}
+		for i := uint64(0); i < arg; i++ {
+			item, size, err := decodeCbor(data[n:])
+			if err != nil {
+				return nil, 0, err
+			}
+			array = append(array, item)
+			n += size
+		}
+		return array, n, nil
+	case 5:
+		var fields = make(map[string]interface{})
+		for i := uint64(0); i < arg; i++ {
+			key, size, err := decodeCbor(data[n:])
+			if err != nil {
+				return nil, 0, err
+			}
+			n += size
+			value, size, err := decodeCbor(data[n:])
+			if err != nil {
+				return nil, 0, err
+			}
+			n += size
+			keyStr, ok := key.(string)
+			if !ok {
+				return nil, 0, fmt.Errorf("unsupported cbor map key %v", key)
+			}
+			fields[keyStr] = value
+		}
+		return fields, n, nil
+	}
+	return nil, 0, fmt.Errorf("unsupported cbor major type %d", major)
+}
+
+const base58Alphabet = "123456789ABCDEFGHJKLMNPQRSTUVWXYZabcdefghijkmnopqrstuvwxyz"
+
+// base58Encode encodes data by bitcoin base58 alphabet, it's used by IPFS CIDv0
+func base58Encode(data []byte) string {
+	var result []byte
+	x := new(big.Int).SetBytes(data)
+	mod := new(big.Int)
+	base := big.NewInt(58)
+	for x.Sign() > 0 {
+		x.DivMod(x, base, mod)
+		result = append(result, base58Alphabet[mod.Int64()])
+	}
+	for _, b := range data {
+		if b != 0 {
+			break
+		}
+		result = append(result, base58Alphabet[0])
+	}
+	for i, j := 0, len(result)-1; i < j; i, j = i+1, j-1 {
+		result[i], result[j] = result[j], result[i]
+	}
+	return string(result)
+}
+
+// printDisasm prints the result of disassembling in human-readable format
+func printDisasm(w io.Writer, out *DisasmOutput) {
+	for _, ins := range out.Instructions {
+		if ins.PushData != "" {
+			fmt.Fprintf(w, "0x%04x  %s %s\n", ins.Pc, ins.Op, ins.PushData)
+		} else {
+			fmt.Fprintf(w, "0x%04x  %s\n", ins.Pc, ins.Op)
+		}
+	}
+	if globalOptTerseOutput {
+		return
+	}
+	if len(out.Instructions) > 0 {
+		fmt.Fprintln(w)
+	}
+
+	fmt.Fprintf(w, "code size: %d bytes\n", out.CodeSize)
+	if out.Metadata == nil {
+		fmt.Fprintf(w, "metadata: not found\n")
+	} else {
+		fmt.Fprintf(w, "metadata: %s\n", out.Metadata)
+	}
+
+	fmt.Fprintf(w, "function selectors: %d\n", len(out.Selectors))
+	for _, selector := range out.Selectors {
+		if len(selector.Signatures) == 0 {
+			fmt.Fprintf(w, "  %s\n", selector.Selector)
+		} else {
+			fmt.Fprintf(w, "  %s %s\n", selector.Selector, strings.Join(selector.Signatures, " | "))
+		}
+	}
+
+	if len(out.RequireForks) == 0 {
+		fmt.Fprintf(w, "required forks: none\n")
+	} else {
+		var forks []string
+		for _, req := range out.RequireForks {
+			forks = append(forks, fmt.Sprintf("%s (%s x%d)", req.Fork, req.Op, req.Count))
+		}
+		fmt.Fprintf(w, "required forks: %s\n", strings.Join(forks, ", "))
+	}
+}
@@ -0,0 +1,137 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestDisassemble(t *testing.T) {
+	code := common.FromHex("0x" +
+		"6080604052" + // PUSH1 0x80 PUSH1 0x40 MSTORE
+		"5f3560e01c" + // PUSH0 CALLDATALOAD PUSH1 0xe0 SHR
+		"8063a9059cbb1461002057" + // DUP1 PUSH4 0xa9059cbb EQ PUSH2 0x0020 JUMPI
+		"8063a9059cbb1461002057" + // the same selector is listed once
+		"6370a082318118610030" + "57" + // PUSH4 0x70a08231 DUP2 XOR PUSH2 0x0030 JUMPI
+		"63ffffffff16" + // PUSH4 0xffffffff AND
+		"63deadbeef50" + // PUSH4 0xdeadbeef POP
+		"5c5d5e" + // TLOAD TSTORE MCOPY
+		"5f80fd" + // PUSH0 DUP1 REVERT
+		"fe0c" + // INVALID UNKNOWN(0x0c)
+		"a2646970667358221220" + strings.Repeat("00", 32) + "64736f6c6343000814" + "0033") // metadata
+
+	lookup := func(selector string) ([]string, error) {
+		if selector == "0xa9059cbb" {
+			return []string{"transfer(address,uint256)"}, nil
+		}
+		return nil, fmt.Errorf("not found")
+	}
+	out := disassemble(code, lookup)
+
+	expectedMetadata := &BytecodeMetadata{
+		Compiler: "solc",
+		Version:  "0.8.20",
+		Ipfs:     base58Encode(append([]byte{0x12, 0x20}, make([]byte, 32)...)),
+		Raw:      "0xa2646970667358221220" + strings.Repeat("00", 32) + "64736f6c63430008140033",
+	}
+	if !reflect.DeepEqual(out.Metadata, expectedMetadata) {
+		t.Fatalf("expected metadata: %+v, got: %+v", expectedMetadata, out.Metadata)
+	}
+
+	expectedSelectors := []SelectorInfo{
+		{Selector: "0xa9059cbb", Signatures: []string{"transfer(address,uint256)"}},
+		{Selector: "0x70a08231", Signatures: []string{}},
+	}
+	if !reflect.DeepEqual(out.Selectors, expectedSelectors) {
+		t.Fatalf("expected selectors: %+v, got: %+v", expectedSelectors, out.Selectors)
+	}
+
+	expectedForks := []ForkRequirement{
+		{Op: "PUSH0", Fork: "shanghai", Count: 2},
+		{Op: "TLOAD", Fork: "cancun", Count: 1},
+		{Op: "TSTORE", Fork: "cancun", Count: 1},
+		{Op: "MCOPY", Fork: "cancun", Count: 1},
+	}
+	if !reflect.DeepEqual(out.RequireForks, expectedForks) {
+		t.Fatalf("expected forks: %+v, got: %+v", expectedForks, out.RequireForks)
+	}
+
+	if len(out.Instructions) != 34 || out.CodeSize != len(code) {
+		t.Fatalf("expected: 34 instructions, got: %v", len(out.Instructions))
+	}
+	expectedInstructions := []Instruction{
+		{Pc: 0, Op: "PUSH1", PushData: "0x80"},
+		{Pc: 2, Op: "PUSH1", PushData: "0x40"},
+		{Pc: 4, Op: "MSTORE"},
+	}
+	if !reflect.DeepEqual(out.Instructions[:3], expectedInstructions) {
+		t.Fatalf("expected: %+v, got: %+v", expectedInstructions, out.Instructions[:3])
+	}
+	if last := out.Instructions[len(out.Instructions)-1]; last.Op != "UNKNOWN(0x0c)" || last.Pc != 62 {
+		t.Fatalf("expected: UNKNOWN(0x0c) at 62, got: %+v", last)
+	}
+
+	var buf bytes.Buffer
+	printDisasm(&buf, out)
+	for _, line := range []string{
+		"0x0007  PUSH1 0xe0\n",
+		"metadata: solc 0.8.20, ipfs " + expectedMetadata.Ipfs + "\n",
+		"  0xa9059cbb transfer(address,uint256)\n  0x70a08231\n",
+		"required forks: shanghai (PUSH0 x2), cancun (TLOAD x1), cancun (TSTORE x1), cancun (MCOPY x1)\n",
+	} {
+		if !strings.Contains(buf.String(), line) {
+			t.Fatalf("expected output contains: %q, got: %s", line, buf.String())
+		}
+	}
+}
+
+func TestSplitMetadata(t *testing.T) {
+	tests := []struct {
+		code     string
+		bodySize int
+		expected *BytecodeMetadata // nil if no metadata
+	}{
+		// solc 0.4.x, bzzr0 only
+		{"0x6000" + "a165627a7a72305820" + strings.Repeat("11", 32) + "0029", 2,
+			&BytecodeMetadata{Bzzr0: "0x" + strings.Repeat("11", 32), Raw: "0xa165627a7a72305820" + strings.Repeat("11", 32) + "0029"}},
+		// solc pre-release version and experimental
+		{"0x6000" + "a264736f6c6369302e382e302d646576" + "6c6578706572696d656e74616cf5" + "001e", 2,
+			&BytecodeMetadata{Compiler: "solc", Version: "0.8.0-dev", Experimental: true, Raw: "0xa264736f6c6369302e382e302d6465766c6578706572696d656e74616cf5001e"}},
+		// vyper 0.3.10, the length includes itself
+		{"0x6000" + "84187b8000a16576797065728300030a" + "0012", 2,
+			&BytecodeMetadata{Compiler: "vyper", Version: "0.3.10", Raw: "0x84187b8000a16576797065728300030a0012"}},
+		// vyper 0.3.7
+		{"0x6000" + "a165767970657283000307" + "000b", 2,
+			&BytecodeMetadata{Compiler: "vyper", Version: "0.3.7", Raw: "0xa165767970657283000307000b"}},
+		{"0x60006000", 4, nil},
+		{"0x", 0, nil},
+	}
+
+	for i, tc := range tests {
+		body, metadata := splitMetadata(common.FromHex(tc.code))
+		if len(body) != tc.bodySize || !reflect.DeepEqual(metadata, tc.expected) {
+			t.Fatalf("test %d: expected: %d bytes body and %+v, got: %d bytes body and %+v", i+1, tc.bodySize, tc.expected, len(body), metadata)
+		}
+	}
+}
+
+func TestBase58Encode(t *testing.T) {
+	tests := []struct {
+		data     []byte
+		expected string
+	}{
+		{[]byte("hello world"), "StV1DL6CwTryKyV"},
+		{[]byte{0, 0, 1}, "112"},
+		{nil, ""},
+	}
+
+	for i, tc := range tests {
+		if got := base58Encode(tc.data); got != tc.expected {
+			t.Fatalf("test %d: expected: %v, got: %v", i+1, tc.expected, got)
+		}
+	}
+}
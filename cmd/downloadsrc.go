@@ -7,6 +7,7 @@ import (
 	"io"
 	"log"
 	"net/http"
+	"net/url"
 	"os"
 	"path/filepath"
 	"strings"
@@ -30,7 +31,14 @@ var downloadSrcCmd = &cobra.Command{
 }
 
 func downloadSrc(contractAddress string) error {
-	var requestUrl = fmt.Sprintf(nodeApiUrlMap[globalOptChain], contractAddress)
+	requestUrl, err := explorerApiUrl(url.Values{
+		"module":  {"contract"},
+		"action":  {"getsourcecode"},
+		"address": {contractAddress},
+	})
+	if err != nil {
+		return err
+	}
 
 	resp, err := http.Get(requestUrl)
 	if err != nil {
@@ -131,6 +139,22 @@ func downloadSrc(contractAddress string) error {
 	return nil
 }
 
+// explorerApiUrl builds the url of Etherscan-style api of current chain, api key is appended if configured
+func explorerApiUrl(params url.Values) (string, error) {
+	if globalChainProfile == nil || globalChainProfile.ApiUrl == "" {
+		return "", fmt.Errorf("block explorer api of chain %q is unknown, please set api-url in config file", globalOptChain)
+	}
+	if globalChainProfile.ApiKey != "" {
+		params.Set("apikey", globalChainProfile.ApiKey)
+	}
+
+	var separator = "?"
+	if strings.Contains(globalChainProfile.ApiUrl, "?") {
+		separator = "&"
+	}
+	return globalChainProfile.ApiUrl + separator + params.Encode(), nil
+}
+
 func saveContract(fileName string, data string) {
 	log.Printf("saving %v", fileName)
 	err := os.WriteFile(fileName, []byte(data), 0644)
@@ -11,8 +11,8 @@ var dropTxCmd = &cobra.Command{
 	Use:   "drop-tx",
 	Short: "Drop pending tx for address",
 	Run: func(cmd *cobra.Command, args []string) {
-		if globalOptPrivateKey == "" {
-			log.Fatalf("--private-key is required for drop-tx command")
+		if !hasSigner() {
+			log.Fatalf("%s is required for drop-tx command", signerOptions)
 		}
 
 		InitGlobalClient(globalOptNodeUrl)
@@ -26,11 +26,13 @@ var dropTxCmd = &cobra.Command{
 		gasPrice.Add(gasPrice, big.NewInt(10*1000000000)) // plus 10 gwei
 		log.Printf("gas price change to %v wei", gasPrice)
 
-		addr := extractAddressFromPrivateKey(hexToPrivateKey(globalOptPrivateKey)).String()
-		if tx, err := TransferHelper(globalClient.RpcClient, globalClient.EthClient, globalOptPrivateKey, addr, big.NewInt(0), gasPrice, nil); err != nil {
+		addr := loadSigner().Address().String()
+		if out, err := TransferHelper(globalClient.RpcClient, globalClient.EthClient, loadSigner(), addr, big.NewInt(0), gasPrice, nil); err != nil {
 			log.Fatalf("transfer 0 wei to self fail: %v", err)
+		} else if isJsonOutput() {
+			printJson(out)
 		} else {
-			log.Printf("transfer 0 wei to self finished, tx = %v", tx)
+			log.Printf("transfer 0 wei to self finished, tx = %v", out.TxHash)
 		}
 	},
 }
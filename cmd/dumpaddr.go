@@ -4,6 +4,7 @@ import (
 	"crypto/ecdsa"
 	"errors"
 	"fmt"
+	"log"
 	"math"
 	"math/big"
 	"strings"
@@ -15,12 +16,6 @@ import (
 	"github.com/tyler-smith/go-bip39"
 )
 
-var dumpAddrCmdDerivationPath string
-
-func init() {
-	dumpAddrCmd.Flags().StringVarP(&dumpAddrCmdDerivationPath, "derivation-path", "", ethBip44Path, "the HD derivation path")
-}
-
 var dumpAddrCmd = &cobra.Command{
 	Use:     "dump-address <mnemonics-or-private-key-or-public-key> <mnemonics-or-private-key-or-public-key> ...",
 	Aliases: []string{"dump-addr"},
@@ -43,6 +38,7 @@ var dumpAddrCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		privateKeyOrMnemonics := args
 		var err error
+		var keys []KeyOutput
 
 		for _, dumpAddrPrivateKeyOrMnemonic := range privateKeyOrMnemonics {
 
@@ -57,25 +53,34 @@ var dumpAddrCmd = &cobra.Command{
 					publicKey, err = hexToPublicKey(dumpAddrPrivateKeyOrMnemonic)
 					checkErr(err)
 				} else {
-					fmt.Printf("invalid key length %v\n", hexLen)
-					return
+					log.Fatalf("invalid key length %v", hexLen)
 				}
 			} else { // mnemonic
-				privateKey, err = MnemonicToPrivateKey(dumpAddrPrivateKeyOrMnemonic, dumpAddrCmdDerivationPath)
+				privateKey, err = MnemonicToPrivateKey(dumpAddrPrivateKeyOrMnemonic, globalOptDerivationPath)
 				publicKey = &privateKey.PublicKey
 				checkErr(err)
 			}
 
+			var key KeyOutput
 			if privateKey != nil {
-				privateHexStr := hexutil.Encode(crypto.FromECDSA(privateKey))
-				fmt.Printf("private key: %v\n", privateHexStr)
+				key.PrivateKey = hexutil.Encode(crypto.FromECDSA(privateKey))
 			}
+			key.PublicKey = hexutil.Encode(crypto.FromECDSAPub(publicKey))
+			key.Address = crypto.PubkeyToAddress(*publicKey).String()
 
-			publicKeyHexStr := hexutil.Encode(crypto.FromECDSAPub(publicKey))
-			fmt.Printf("public key: %v\n", publicKeyHexStr)
+			if isJsonOutput() {
+				keys = append(keys, key)
+				continue
+			}
+			if key.PrivateKey != "" {
+				fmt.Printf("private key: %v\n", key.PrivateKey)
+			}
+			fmt.Printf("public key: %v\n", key.PublicKey)
+			fmt.Printf("addr: %v\n", key.Address)
+		}
 
-			addr := crypto.PubkeyToAddress(*publicKey).String()
-			fmt.Printf("addr: %v\n", addr)
+		if isJsonOutput() {
+			printJson(keys)
 		}
 	},
 }
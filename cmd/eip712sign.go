@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"crypto/ecdsa"
 	"encoding/json"
 	"fmt"
 	"github.com/ethereum/go-ethereum/common"
@@ -19,6 +18,15 @@ func init() {
 	eip712SignCmd.Flags().StringVarP(&eip712TypedDataFile, "eip712-typed-data-file", "", "", "the path of EIP712 typed data json file")
 }
 
+// Eip712SignOutput is the json output (--output json) of eip712-sign command
+type Eip712SignOutput struct {
+	Signer    string `json:"signer"`
+	V         int    `json:"v"` // 27 or 28
+	R         string `json:"r"`
+	S         string `json:"s"`
+	Signature string `json:"signature"` // r || s || v
+}
+
 // eip712SignCmd represents the eip712Sign command
 var eip712SignCmd = &cobra.Command{
 	Use:   "eip712-sign",
@@ -90,40 +98,45 @@ var eip712SignCmd = &cobra.Command{
 }
 `,
 	Run: func(cmd *cobra.Command, args []string) {
-		if globalOptPrivateKey == "" {
-			log.Fatalf("--private-key is required for this command")
+		if !hasSigner() {
+			log.Fatalf("%s is required for this command", signerOptions)
 		}
 		if eip712TypedDataFile == "" {
 			log.Fatalf("--eip712-typed-data-file is required for this command")
 		}
 		eip712TypedDataJson, err := os.ReadFile(eip712TypedDataFile)
 
-		privateKey := hexToPrivateKey(globalOptPrivateKey)
+		signer := loadSigner()
 		var sig []byte
-		sigV, sigR, sigS, err := eip712Sign(eip712TypedDataJson, privateKey)
+		sigV, sigR, sigS, err := eip712Sign(eip712TypedDataJson, signer)
 		checkErr(err)
 		sig = append(sig, sigR...)
 		sig = append(sig, sigS...)
 		sig = append(sig, byte(sigV))
+		if isJsonOutput() {
+			printJson(Eip712SignOutput{
+				Signer:    signer.Address().String(),
+				V:         sigV,
+				R:         hexutil.Encode(sigR),
+				S:         hexutil.Encode(sigS),
+				Signature: hexutil.Encode(sig),
+			})
+			return
+		}
 		fmt.Printf("Signer address: %s\nEIP712 sign v: %d\nEIP712 sign r: %s\nEIP712 sign s: %s\nEIP712 sign (rsv): %s\n",
-			extractAddressFromPrivateKey(privateKey).String(),
+			signer.Address().String(),
 			sigV, hexutil.Encode(sigR), hexutil.Encode(sigS), hexutil.Encode(sig))
 	},
 }
 
 // eip712Sign Returns EIP712 signature data
-func eip712Sign(eip712TypedDataJson []byte, privateKey *ecdsa.PrivateKey) (int, []byte, []byte, error) {
+func eip712Sign(eip712TypedDataJson []byte, signer Signer) (int, []byte, []byte, error) {
 	var typedData apitypes.TypedData
 	if err := json.Unmarshal(eip712TypedDataJson, &typedData); err != nil {
 		return 0, nil, nil, fmt.Errorf("json.Unmarshal failed: %w", err)
 	}
 
-	preHash, err := computePreHash(typedData)
-	if err != nil {
-		return 0, nil, nil, fmt.Errorf("computePreHash failed: %w", err)
-	}
-
-	signatureBytes, err := crypto.Sign(preHash.Bytes(), privateKey)
+	signatureBytes, err := signer.SignTypedData(typedData)
 	if err != nil {
 		return 0, nil, nil, fmt.Errorf("sign failed: %w", err)
 	}
@@ -2,13 +2,11 @@ package cmd
 
 import (
 	"context"
-	"crypto/ecdsa"
 	"fmt"
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/ethereum/go-ethereum/ethclient"
 	"github.com/holiman/uint256"
 	"github.com/shopspring/decimal"
@@ -37,8 +35,8 @@ var eip7702SetEoaCodeCmd = &cobra.Command{
 			return fmt.Errorf("%v is not a valid eth address", args[0])
 		}
 
-		if globalOptPrivateKey == "" {
-			log.Fatalf("--private-key is required for this command")
+		if !hasSigner() {
+			log.Fatalf("%s is required for this command", signerOptions)
 		}
 
 		return nil
@@ -51,16 +49,9 @@ var eip7702SetEoaCodeCmd = &cobra.Command{
 		// We just create a self transfer transaction, the amount is not important
 		var valueInEther = decimal.RequireFromString("0")
 
-		var privateKey *ecdsa.PrivateKey
-		if globalOptPrivateKey != "" {
-			privateKey = hexToPrivateKey(globalOptPrivateKey)
-		}
-
-		if globalOptPrivateKey == "" && eip7702SetEoaCodeSignData == "" {
-			log.Fatalf("require --sign-data or --private-key")
-		}
+		var signer = loadSigner()
 
-		var fromAddress = extractAddressFromPrivateKey(privateKey)
+		var fromAddress = signer.Address()
 		var toAddress = fromAddress
 
 		currentCode, err := globalClient.EthClient.CodeAt(context.Background(), fromAddress, nil)
@@ -68,7 +59,7 @@ var eip7702SetEoaCodeCmd = &cobra.Command{
 
 		log.Printf("%s current code = %s", fromAddress, hexutil.Encode(currentCode))
 
-		signedTx, err := BuildEip7702SignedTx(globalClient.EthClient, privateKey, &toAddress, valueInEther.BigInt(), common.FromHex(eip7702SetEoaCodeHexData), common.FromHex(eip7702SetEoaCodeSignData), delegateTo)
+		signedTx, err := BuildEip7702SignedTx(globalClient.EthClient, signer, &toAddress, valueInEther.BigInt(), common.FromHex(eip7702SetEoaCodeHexData), common.FromHex(eip7702SetEoaCodeSignData), delegateTo)
 		checkErr(err)
 
 		signedRawTx, err := GenRawTx(signedTx)
@@ -91,7 +82,7 @@ var eip7702SetEoaCodeCmd = &cobra.Command{
 
 // BuildEip7702SignedTx builds signed transaction
 func BuildEip7702SignedTx(
-	client *ethclient.Client, privateKey *ecdsa.PrivateKey,
+	client *ethclient.Client, signer Signer,
 	toAddress *common.Address, amount *big.Int, data []byte, sigData []byte, delegateTo common.Address,
 ) (*types.Transaction, error) {
 	chainID, err := client.NetworkID(context.Background())
@@ -99,27 +90,27 @@ func BuildEip7702SignedTx(
 		return nil, fmt.Errorf("NetworkID fail: %w", err)
 	}
 
-	tx, err := BuildEIP7702Tx(client, privateKey, toAddress, amount, data, delegateTo)
+	tx, err := BuildEIP7702Tx(client, signer, toAddress, amount, data, delegateTo)
 	if err != nil {
 		return nil, fmt.Errorf("BuildTx fail: %w", err)
 	}
 
-	signer := types.NewPragueSigner(chainID)
-	preHash := signer.Hash(tx)
+	txSigner := types.NewPragueSigner(chainID)
+	preHash := txSigner.Hash(tx)
 	if globalOptShowPreHash {
-		fmt.Printf("hash before ecdsa sign (hex) = %x\n", preHash.Bytes())
+		printPreHash(preHash)
 	}
 
-	// If sigData is not provided, signs the transaction using the private key
+	// If sigData is not provided, signs the transaction by signer
 	if len(sigData) == 0 {
-		sigData, err = crypto.Sign(preHash[:], privateKey)
+		sigData, err = signer.SignHash(preHash)
 		if err != nil {
 			return nil, err
 		}
 	}
 
 	// attach sigData to tx
-	signedTx, err := tx.WithSignature(signer, sigData)
+	signedTx, err := tx.WithSignature(txSigner, sigData)
 	if err != nil {
 		return nil, fmt.Errorf("WithSignature fail: %w", err)
 	}
@@ -128,7 +119,7 @@ func BuildEip7702SignedTx(
 }
 
 // BuildEIP7702Tx builds EIP7702 transaction
-func BuildEIP7702Tx(client *ethclient.Client, privateKey *ecdsa.PrivateKey,
+func BuildEIP7702Tx(client *ethclient.Client, signer Signer,
 	toAddress *common.Address, amount *big.Int, data []byte, delegateTo common.Address,
 ) (*types.Transaction, error) {
 	log.Printf("amount = %s", amount.String())
@@ -137,7 +128,7 @@ func BuildEIP7702Tx(client *ethclient.Client, privateKey *ecdsa.PrivateKey,
 	var err error
 	if globalOptNonce < 0 {
 
-		var account = extractAddressFromPrivateKey(privateKey)
+		var account = signer.Address()
 
 		nonce, err = client.PendingNonceAt(context.Background(), account)
 		if err != nil {
@@ -160,7 +151,7 @@ func BuildEIP7702Tx(client *ethclient.Client, privateKey *ecdsa.PrivateKey,
 			Data:  data,
 		})
 		if err != nil {
-			return nil, fmt.Errorf("EstimateGas fail: %w", err)
+			return nil, fmt.Errorf("EstimateGas fail: %w", wrapRevertError(err))
 		}
 		gasLimit = estimateGasLimit
 	}
@@ -211,9 +202,9 @@ func BuildEIP7702Tx(client *ethclient.Client, privateKey *ecdsa.PrivateKey,
 		Nonce: nonce + 1,
 	}
 
-	signedAuth, err := types.SignSetCode(privateKey, auth)
+	signedAuth, err := signSetCodeAuth(signer, auth)
 	if err != nil {
-		return nil, fmt.Errorf("SignSetCode fail: %w", err)
+		return nil, fmt.Errorf("signSetCodeAuth fail: %w", err)
 	}
 
 	log.Printf("signed auth = %v", signedAuth)
@@ -236,3 +227,15 @@ func BuildEIP7702Tx(client *ethclient.Client, privateKey *ecdsa.PrivateKey,
 
 	return tx, err
 }
+
+// signSetCodeAuth signs the EIP-7702 authorization tuple, it's same as types.SignSetCode but works with Signer
+func signSetCodeAuth(signer Signer, auth types.SetCodeAuthorization) (types.SetCodeAuthorization, error) {
+	sig, err := signer.SignHash(auth.SigHash())
+	if err != nil {
+		return types.SetCodeAuthorization{}, err
+	}
+	auth.R.SetBytes(sig[0:32])
+	auth.S.SetBytes(sig[32:64])
+	auth.V = sig[64]
+	return auth, nil
+}
@@ -6,14 +6,27 @@ package cmd
 import (
 	"fmt"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
-	"github.com/ethereum/go-ethereum/crypto"
 	"github.com/holiman/uint256"
 	"log"
 
 	"github.com/spf13/cobra"
 )
 
+// AuthTupleSignOutput is the json output (--output json) of eip7702-sign-auth-tuple command
+type AuthTupleSignOutput struct {
+	ChainId   string `json:"chainId"`
+	Address   string `json:"address"` // delegate to
+	Nonce     uint64 `json:"nonce"`
+	PreHash   string `json:"preHash"`
+	Authority string `json:"authority"` // i.e. signer
+	Signature string `json:"signature"` // r || s || yParity
+	R         string `json:"r"`
+	S         string `json:"s"`
+	YParity   uint8  `json:"yParity"`
+}
+
 // eip7702SignAuthTupleCmd represents the eip7702SignAuthTuple command
 var eip7702SignAuthTupleCmd = &cobra.Command{
 	Use:   "eip7702-sign-auth-tuple <chain-id> <delegate-to> <nonce>",
@@ -26,8 +39,8 @@ var eip7702SignAuthTupleCmd = &cobra.Command{
 			return fmt.Errorf("%v is not a valid eth address", args[1])
 		}
 
-		if globalOptPrivateKey == "" {
-			log.Fatalf("--private-key is required for this command")
+		if !hasSigner() {
+			log.Fatalf("%s is required for this command", signerOptions)
 		}
 
 		return nil
@@ -53,13 +66,28 @@ var eip7702SignAuthTupleCmd = &cobra.Command{
 			nonce,
 		})
 
-		fmt.Printf("auth pre hash = %x\n", preHash[:])
-
-		var privateKey = hexToPrivateKey(globalOptPrivateKey)
-		sig, err := crypto.Sign(preHash[:], privateKey)
+		var signer = loadSigner()
+		sig, err := signer.SignHash(preHash)
 		checkErr(err)
 
-		authority := extractAddressFromPrivateKey(privateKey)
+		authority := signer.Address()
+
+		if isJsonOutput() {
+			printJson(AuthTupleSignOutput{
+				ChainId:   chainId.String(),
+				Address:   delegateTo.Hex(),
+				Nonce:     auth.Nonce,
+				PreHash:   hexutil.Encode(preHash[:]),
+				Authority: authority.Hex(),
+				Signature: hexutil.Encode(sig),
+				R:         hexutil.Encode(sig[0:32]),
+				S:         hexutil.Encode(sig[32:64]),
+				YParity:   sig[64],
+			})
+			return
+		}
+
+		fmt.Printf("auth pre hash = %x\n", preHash[:])
 		fmt.Printf("authority (i.e. signer) = %s\n", authority.Hex())
 
 		fmt.Printf("sig hex = %x\n", sig)
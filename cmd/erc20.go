@@ -48,7 +48,11 @@ var erc20Cmd = &cobra.Command{
 
 		if !globalOptDryRun {
 			// don't check contract address if --dry-run specified
-			isContract, err := isContractAddress(globalClient.EthClient, common.HexToAddress(contractAddr))
+			var block interface{} = "latest"
+			if !changeContractState(funcName) {
+				block = blockArg() // read methods read state at --block
+			}
+			isContract, err := isContractAddressAt(globalClient.EthClient, common.HexToAddress(contractAddr), block)
 			if err != nil {
 				panic(err)
 			}
@@ -70,17 +74,17 @@ var erc20Cmd = &cobra.Command{
 		}
 
 		if changeContractState(funcName) {
-			if globalOptPrivateKey == "" {
-				log.Fatalf("--private-key is required for this command")
+			if !hasSigner() {
+				log.Fatalf("%s is required for this command", signerOptions)
 			} else {
 				var contract = common.HexToAddress(contractAddr)
-				tx, err := Transact(globalClient.RpcClient, globalClient.EthClient, hexToPrivateKey(globalOptPrivateKey), &contract, big.NewInt(0), nil, txInputData)
+				out, err := TransactTx(globalClient.RpcClient, globalClient.EthClient, loadSigner(), &contract, big.NewInt(0), nil, txInputData)
 				checkErr(err)
 
-				log.Printf("transaction %s finished", tx)
+				printTxOutput(out)
 			}
 		} else {
-			output, err := Call(globalClient.RpcClient, common.HexToAddress(contractAddr), txInputData)
+			output, err := CallAt(globalClient.RpcClient, common.HexToAddress(contractAddr), txInputData, blockArg())
 			checkErr(err)
 
 			printContractReturnData(funcSignature, output)
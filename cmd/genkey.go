@@ -18,6 +18,14 @@ func init() {
 	genkeyCmd.Flags().IntVarP(&genkeyMnemonicLengthOpt, "mnemonic-len", "", 12, "number of mnemonic words, can be 12/15/18/21/24")
 }
 
+// KeyOutput is the json output (--output json) of gen-key and dump-address, an array of KeyOutput is printed
+type KeyOutput struct {
+	Mnemonic   string `json:"mnemonic,omitempty"`
+	PrivateKey string `json:"privateKey,omitempty"` // absent if only public key is known
+	PublicKey  string `json:"publicKey"`            // uncompressed
+	Address    string `json:"address"`
+}
+
 var genkeyCmd = &cobra.Command{
 	Use:     "gen-key",
 	Aliases: []string{"gen-private-key"},
@@ -43,6 +51,7 @@ var genkeyCmd = &cobra.Command{
 			panic(fmt.Sprintf("invalid mnemonic-len %v", genkeyMnemonicLengthOpt))
 		}
 
+		var keys []KeyOutput
 		for i := 1; i <= genkeyNumOpt; i++ {
 			entropy, err := bip39.NewEntropy(entropyBitSize)
 			checkErr(err)
@@ -57,11 +66,17 @@ var genkeyCmd = &cobra.Command{
 
 			publicKeyHexStr := hexutil.Encode(crypto.FromECDSAPub(&privateKey.PublicKey))
 
-			if globalOptTerseOutput {
+			if isJsonOutput() {
+				keys = append(keys, KeyOutput{Mnemonic: mnemonic, PrivateKey: privateHexStr, PublicKey: publicKeyHexStr, Address: addr})
+			} else if globalOptTerseOutput {
 				fmt.Printf("%v %v\n", privateHexStr, addr)
 			} else {
 				fmt.Printf("mnemonic: %v\nprivate key: %v\npublic key: %v\naddr: %v\n", mnemonic, privateHexStr, publicKeyHexStr, addr)
 			}
 		}
+
+		if isJsonOutput() {
+			printJson(keys)
+		}
 	},
 }
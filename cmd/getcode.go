@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"context"
 	"fmt"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -10,6 +9,13 @@ import (
 	"strings"
 )
 
+// CodeOutput is the json output (--output json) of code command
+type CodeOutput struct {
+	Address    string `json:"address"`
+	Code       string `json:"code"`                 // "0x" if no code
+	DelegateTo string `json:"delegateTo,omitempty"` // only for EIP-7702 EOA
+}
+
 var getCodeCmd = &cobra.Command{
 	Use:   "code <address>",
 	Short: "Get runtime bytecode of a contract on the blockchain, or EIP-7702 EOA code.",
@@ -31,11 +37,18 @@ var getCodeCmd = &cobra.Command{
 
 		InitGlobalClient(globalOptNodeUrl)
 
-		ctx := context.Background()
-
-		byteCode, err := globalClient.EthClient.CodeAt(ctx, common.HexToAddress(address), nil)
+		byteCode, err := codeAt(globalClient.RpcClient, common.HexToAddress(address), blockArg())
 		checkErr(err)
 
+		if isJsonOutput() {
+			var out = CodeOutput{Address: address, Code: hexutil.Encode(byteCode)}
+			if strings.HasPrefix(out.Code, "0xef0100") {
+				out.DelegateTo = common.BytesToAddress(byteCode[3:23]).Hex()
+			}
+			printJson(out)
+			return
+		}
+
 		if len(byteCode) == 0 {
 			log.Printf("no runtime bytecode found for %v", address)
 			return
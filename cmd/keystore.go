@@ -0,0 +1,343 @@
+package cmd
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/keystore"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/spf13/cobra"
+	"golang.org/x/term"
+)
+
+// The environment variable of keystore password, it's used if --password-file is not given
+const keystorePasswordEnv = "ETHUTIL_KEYSTORE_PASSWORD"
+
+var keystoreLightKdf bool
+var keystoreImportKeyFile string
+
+// keystoreCmd represents the keystore command
+var keystoreCmd = &cobra.Command{
+	Use:   "keystore",
+	Short: "Manage encrypted keystore (Web3 Secret Storage V3) files, they can be used by --keystore instead of --private-key",
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = cmd.Help()
+		os.Exit(1)
+	},
+}
+
+var keystoreNewCmd = &cobra.Command{
+	Use:   "new",
+	Short: "Generate a new key, and save it into keystore directory",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		password, err := readKeystorePassword("Password for the new key: ", true)
+		checkErr(err)
+
+		privateKey, err := crypto.GenerateKey()
+		checkErr(err)
+
+		file, err := saveKeystoreKey(keystoreDir(), privateKey, password)
+		checkErr(err)
+		printKeystoreEntry(KeystoreOutput{Address: crypto.PubkeyToAddress(privateKey.PublicKey).Hex(), File: file})
+	},
+}
+
+var keystoreImportCmd = &cobra.Command{
+	Use:   "import",
+	Short: "Import a private key into keystore directory, the private key is read from --key-file (- for stdin) or prompt",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		privateKeyHex, err := readImportPrivateKey(keystoreImportKeyFile)
+		checkErr(err)
+		if !isValidHexString(privateKeyHex) || len(remove0xPrefix(privateKeyHex)) != 64 {
+			log.Fatalf("invalid private key")
+		}
+		privateKey := hexToPrivateKey(privateKeyHex)
+
+		password, err := readKeystorePassword("Password for the imported key: ", true)
+		checkErr(err)
+
+		file, err := saveKeystoreKey(keystoreDir(), privateKey, password)
+		checkErr(err)
+		printKeystoreEntry(KeystoreOutput{Address: crypto.PubkeyToAddress(privateKey.PublicKey).Hex(), File: file})
+	},
+}
+
+var keystoreExportCmd = &cobra.Command{
+	Use:   "export <address>",
+	Short: "Decrypt a key in keystore, and print its private key",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !isValidEthAddress(args[0]) {
+			log.Fatalf("%v is not a valid eth address", args[0])
+		}
+
+		file, err := findKeystoreFile(keystoreDir(), args[0])
+		checkErr(err)
+		privateKey, err := decryptKeystoreFile(file)
+		checkErr(err)
+
+		log.Printf("WARNING: the private key is printed in plain text, keep it secret")
+		var entry = KeystoreOutput{
+			Address:    crypto.PubkeyToAddress(privateKey.PublicKey).Hex(),
+			File:       file,
+			PrivateKey: hexutil.Encode(crypto.FromECDSA(privateKey)),
+		}
+		if isJsonOutput() {
+			printJson(entry)
+			return
+		}
+		fmt.Printf("private key: %v\naddr: %v\n", entry.PrivateKey, entry.Address)
+	},
+}
+
+var keystoreListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "List addresses in keystore directory",
+	Args:  cobra.NoArgs,
+	Run: func(cmd *cobra.Command, args []string) {
+		entries, err := listKeystoreFiles(keystoreDir())
+		checkErr(err)
+
+		if isJsonOutput() {
+			printJson(entries)
+			return
+		}
+		for _, entry := range entries {
+			if globalOptTerseOutput {
+				fmt.Printf("%v\n", entry.Address)
+			} else {
+				fmt.Printf("%v %v\n", entry.Address, entry.File)
+			}
+		}
+	},
+}
+
+func init() {
+	keystoreNewCmd.Flags().BoolVarP(&keystoreLightKdf, "light-kdf", "", false, "use less memory and CPU to encrypt the key, at the expense of security")
+	keystoreImportCmd.Flags().BoolVarP(&keystoreLightKdf, "light-kdf", "", false, "use less memory and CPU to encrypt the key, at the expense of security")
+	keystoreImportCmd.Flags().StringVarP(&keystoreImportKeyFile, "key-file", "", "", "the file of private key (hex) to import, - means stdin. The private key is read from prompt if it's not given")
+
+	keystoreCmd.AddCommand(keystoreNewCmd)
+	keystoreCmd.AddCommand(keystoreImportCmd)
+	keystoreCmd.AddCommand(keystoreExportCmd)
+	keystoreCmd.AddCommand(keystoreListCmd)
+}
+
+// KeystoreOutput is the json output (--output json) of keystore commands, keystore list prints an array of KeystoreOutput
+type KeystoreOutput struct {
+	Address    string `json:"address"`
+	File       string `json:"file"`
+	PrivateKey string `json:"privateKey,omitempty"` // only for keystore export
+}
+
+func printKeystoreEntry(entry KeystoreOutput) {
+	if isJsonOutput() {
+		printJson(entry)
+		return
+	}
+	fmt.Printf("addr: %v\nkeystore file: %v\n", entry.Address, entry.File)
+}
+
+// defaultKeystoreDir returns the keystore directory used when --keystore is not given
+func defaultKeystoreDir() string {
+	return filepath.Join(filepath.Dir(globalConfigFilePath()), "keystore")
+}
+
+// keystoreDir returns the directory of --keystore, or the default keystore directory
+func keystoreDir() string {
+	if globalOptKeystore == "" {
+		return defaultKeystoreDir()
+	}
+	if info, err := os.Stat(globalOptKeystore); err == nil && !info.IsDir() {
+		return filepath.Dir(globalOptKeystore)
+	}
+	return globalOptKeystore
+}
+
+// listKeystoreFiles returns keystore files in dir, files which are not keystore are ignored
+func listKeystoreFiles(dir string) ([]KeystoreOutput, error) {
+	items, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, fmt.Errorf("read keystore directory failed: %w", err)
+	}
+
+	var entries = []KeystoreOutput{}
+	for _, item := range items {
+		if item.IsDir() || strings.HasPrefix(item.Name(), ".") {
+			continue
+		}
+		file := filepath.Join(dir, item.Name())
+		address, err := keystoreFileAddress(file)
+		if err != nil {
+			continue
+		}
+		entries = append(entries, KeystoreOutput{Address: address.Hex(), File: file})
+	}
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].File < entries[j].File
+	})
+	return entries, nil
+}
+
+// keystoreFileAddress returns the address field in keystore file without decrypting it
+func keystoreFileAddress(file string) (common.Address, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return common.Address{}, err
+	}
+	var v struct {
+		Address string          `json:"address"`
+		Crypto  json.RawMessage `json:"crypto"`
+	}
+	if err := json.Unmarshal(content, &v); err != nil {
+		return common.Address{}, err
+	}
+	if !common.IsHexAddress(v.Address) || len(v.Crypto) == 0 {
+		return common.Address{}, fmt.Errorf("%s is not a keystore file", file)
+	}
+	return common.HexToAddress(v.Address), nil
+}
+
+// findKeystoreFile returns path if it's a file, otherwise finds the keystore file of address in directory path.
+// address can be empty if there is only one key in the directory.
+func findKeystoreFile(path string, address string) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("keystore %s not found", path)
+	}
+	if !info.IsDir() {
+		return path, nil
+	}
+
+	entries, err := listKeystoreFiles(path)
+	if err != nil {
+		return "", err
+	}
+	if address == "" {
+		if len(entries) == 1 {
+			return entries[0].File, nil
+		}
+		return "", fmt.Errorf("found %d keys in %s, please specify one by --from", len(entries), path)
+	}
+	for _, entry := range entries {
+		if common.HexToAddress(entry.Address) == common.HexToAddress(address) {
+			return entry.File, nil
+		}
+	}
+	return "", fmt.Errorf("key of %s not found in %s", address, path)
+}
+
+// decryptKeystoreFile decrypts keystore file, the password comes from --password-file, environment variable or prompt
+func decryptKeystoreFile(file string) (*ecdsa.PrivateKey, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	password, err := readKeystorePassword(fmt.Sprintf("Password of %s: ", filepath.Base(file)), false)
+	if err != nil {
+		return nil, err
+	}
+	key, err := keystore.DecryptKey(content, password)
+	if err != nil {
+		return nil, fmt.Errorf("decrypt keystore %s failed: %w", file, err)
+	}
+	return key.PrivateKey, nil
+}
+
+// saveKeystoreKey encrypts privateKey with password, and saves it into dir with the file name used by geth
+func saveKeystoreKey(dir string, privateKey *ecdsa.PrivateKey, password string) (string, error) {
+	scryptN, scryptP := keystore.StandardScryptN, keystore.StandardScryptP
+	if keystoreLightKdf {
+		scryptN, scryptP = keystore.LightScryptN, keystore.LightScryptP
+	}
+
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	ks := keystore.NewKeyStore(dir, scryptN, scryptP)
+	account, err := ks.ImportECDSA(privateKey, password)
+	if err != nil {
+		return "", fmt.Errorf("save key failed: %w", err)
+	}
+	return account.URL.Path, nil
+}
+
+// readImportPrivateKey reads the private key to import from keyFile (- means stdin), or from prompt if keyFile is
+// empty. The private key is never accepted as argument, which would be kept in shell history.
+func readImportPrivateKey(keyFile string) (string, error) {
+	var content []byte
+	var err error
+	switch keyFile {
+	case "":
+		if !term.IsTerminal(int(os.Stdin.Fd())) {
+			return "", fmt.Errorf("stdin is not a terminal, please use --key-file")
+		}
+		var secret string
+		secret, err = promptSecret("Private key: ")
+		content = []byte(secret)
+	case "-":
+		content, err = io.ReadAll(os.Stdin)
+	default:
+		content, err = os.ReadFile(keyFile)
+	}
+	if err != nil {
+		return "", fmt.Errorf("read private key failed: %w", err)
+	}
+	return strings.TrimSpace(string(content)), nil
+}
+
+// readKeystorePassword reads password from --password-file, environment variable ETHUTIL_KEYSTORE_PASSWORD or prompt.
+// The password is asked twice if confirm is true and it's read from prompt.
+func readKeystorePassword(prompt string, confirm bool) (string, error) {
+	if globalOptPasswordFile != "" {
+		content, err := os.ReadFile(globalOptPasswordFile)
+		if err != nil {
+			return "", fmt.Errorf("read password file failed: %w", err)
+		}
+		return strings.TrimRight(string(content), "\r\n"), nil
+	}
+	if password, ok := os.LookupEnv(keystorePasswordEnv); ok {
+		return password, nil
+	}
+
+	password, err := promptSecret(prompt)
+	if err != nil {
+		return "", err
+	}
+	if confirm {
+		again, err := promptSecret("Repeat password: ")
+		if err != nil {
+			return "", err
+		}
+		if again != password {
+			return "", fmt.Errorf("passwords do not match")
+		}
+	}
+	return password, nil
+}
+
+// promptSecret reads a line from terminal without echo
+func promptSecret(prompt string) (string, error) {
+	fd := int(os.Stdin.Fd())
+	if !term.IsTerminal(fd) {
+		return "", fmt.Errorf("stdin is not a terminal, please use --password-file or environment variable %s", keystorePasswordEnv)
+	}
+	fmt.Fprint(os.Stderr, prompt)
+	secret, err := term.ReadPassword(fd)
+	fmt.Fprintln(os.Stderr)
+	if err != nil {
+		return "", err
+	}
+	return string(secret), nil
+}
@@ -0,0 +1,91 @@
+package cmd
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestKeystoreSaveAndDecrypt(t *testing.T) {
+	dir := t.TempDir()
+	passwordFile := filepath.Join(t.TempDir(), "password")
+	if err := os.WriteFile(passwordFile, []byte("test password\n"), 0600); err != nil {
+		t.Fatalf("write password file failed: %v", err)
+	}
+
+	savedPasswordFile, savedLightKdf := globalOptPasswordFile, keystoreLightKdf
+	defer func() { globalOptPasswordFile, keystoreLightKdf = savedPasswordFile, savedLightKdf }()
+	globalOptPasswordFile = passwordFile
+	keystoreLightKdf = true
+
+	var addresses []string
+	for _, keyHex := range []string{
+		"4f66baf5a1c3a91b6cf8173cdb60d12496e1f572cee6f9f86bc507d87a9790d7",
+		"8a1f9a8f95be41cd7ccb6168179afb4504aefe388d1e14474d32c45c72ce7b7a",
+	} {
+		privateKey := hexToPrivateKey(keyHex)
+		if _, err := saveKeystoreKey(dir, privateKey, "test password"); err != nil {
+			t.Fatalf("saveKeystoreKey failed: %v", err)
+		}
+		addresses = append(addresses, crypto.PubkeyToAddress(privateKey.PublicKey).Hex())
+	}
+	// not a keystore file, it's ignored
+	if err := os.WriteFile(filepath.Join(dir, "README"), []byte("hello"), 0600); err != nil {
+		t.Fatalf("write file failed: %v", err)
+	}
+
+	entries, err := listKeystoreFiles(dir)
+	if err != nil {
+		t.Fatalf("listKeystoreFiles failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("expected: %v, got: %v", 2, len(entries))
+	}
+
+	if _, err := findKeystoreFile(dir, ""); err == nil {
+		t.Fatalf("expect error when multiple keys found without address")
+	}
+
+	for i, address := range addresses {
+		file, err := findKeystoreFile(dir, address)
+		if err != nil {
+			t.Fatalf("test %d: findKeystoreFile failed: %v", i+1, err)
+		}
+		privateKey, err := decryptKeystoreFile(file)
+		if err != nil {
+			t.Fatalf("test %d: decryptKeystoreFile failed: %v", i+1, err)
+		}
+		if got := crypto.PubkeyToAddress(privateKey.PublicKey).Hex(); got != address {
+			t.Fatalf("test %d: expected: %v, got: %v", i+1, address, got)
+		}
+		// keystore file itself can be used too
+		if got, err := findKeystoreFile(file, ""); err != nil || got != file {
+			t.Fatalf("test %d: expected: %v, got: %v (%v)", i+1, file, got, err)
+		}
+	}
+
+	// wrong password from environment variable
+	globalOptPasswordFile = ""
+	t.Setenv(keystorePasswordEnv, "wrong password")
+	if _, err := decryptKeystoreFile(entries[0].File); err == nil {
+		t.Fatalf("expect error for wrong password")
+	}
+}
+
+func TestReadImportPrivateKey(t *testing.T) {
+	const key = "0x4f66baf5a1c3a91b6cf8173cdb60d12496e1f572cee6f9f86bc507d87a9790d7"
+	file := filepath.Join(t.TempDir(), "key")
+	if err := os.WriteFile(file, []byte(key+"\n"), 0600); err != nil {
+		t.Fatalf("write key file failed: %v", err)
+	}
+
+	got, err := readImportPrivateKey(file)
+	if err != nil || got != key {
+		t.Fatalf("expected: %v, got: %v (%v)", key, got, err)
+	}
+	if _, err := readImportPrivateKey(filepath.Join(t.TempDir(), "not-exist")); err == nil {
+		t.Fatalf("expect error for missing key file")
+	}
+}
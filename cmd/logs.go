@@ -0,0 +1,322 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"os"
+	"regexp"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/spf13/cobra"
+)
+
+var logsCmdEvent string
+var logsCmdABIFile string
+var logsCmdFilters []string
+var logsCmdFromBlock string
+var logsCmdToBlock string
+var logsCmdMaxRange uint64
+var logsCmdNoLookup bool
+
+func init() {
+	logsCmd.Flags().StringVarP(&logsCmdEvent, "event", "", "", "the event to query, e.g. 'Transfer(address indexed from, address indexed to, uint256 value)', or the event name in --abi-file. For signature without indexed, e.g. 'Transfer(address,address,uint256)', the leading args are assumed indexed")
+	logsCmd.Flags().StringVarP(&logsCmdABIFile, "abi-file", "", "", "the path of abi file, its events are used when decoding logs and finding --event by name")
+	logsCmd.Flags().StringArrayVarP(&logsCmdFilters, "filter", "", nil, "filter by indexed arg of --event, format: <arg-name>=<value>, the unnamed arg is argN (N is its position). It can be repeated, the values of the same arg are OR-ed")
+	logsCmd.Flags().StringVarP(&logsCmdFromBlock, "from-block", "", "latest", "the first block of range, a block number, hash or tag (latest, safe, finalized, earliest)")
+	logsCmd.Flags().StringVarP(&logsCmdToBlock, "to-block", "", "latest", "the last block of range, a block number, hash or tag (latest, safe, finalized, earliest)")
+	logsCmd.Flags().Uint64VarP(&logsCmdMaxRange, "max-range", "", 0, "the max blocks of one eth_getLogs, 0 means no limit. The range is split automatically if node rejects it")
+	logsCmd.Flags().BoolVarP(&logsCmdNoLookup, "no-lookup", "", false, "do not look up signatures of unknown events online")
+}
+
+// LogOutput is a line of the json output (--output json) of logs command, one line per log
+type LogOutput struct {
+	BlockNumber uint64 `json:"blockNumber"`
+	TxHash      string `json:"txHash"`
+	*DecodedLogOutput
+}
+
+var logsCmd = &cobra.Command{
+	Use:   "logs [address...]",
+	Short: "Query event logs by eth_getLogs, logs are decoded by --event, --abi-file, known standard events or online signature lookup",
+	Args: func(cmd *cobra.Command, args []string) error {
+		for _, arg := range args {
+			if !isValidEthAddress(arg) {
+				return fmt.Errorf("%v is not a valid eth address", arg)
+			}
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 && logsCmdEvent == "" {
+			log.Fatalf("must specify address or --event")
+		}
+		if len(logsCmdFilters) > 0 && logsCmdEvent == "" {
+			log.Fatalf("--filter requires --event")
+		}
+
+		var lookupFn eventSigLookup = GetEventSig
+		if logsCmdNoLookup {
+			lookupFn = nil
+		}
+		decoder := newEventDecoder(lookupFn)
+		var abiContent []byte
+		if logsCmdABIFile != "" {
+			var err error
+			abiContent, err = os.ReadFile(logsCmdABIFile)
+			checkErr(err)
+			checkErr(decoder.addABI(abiContent))
+		}
+
+		var query ethereum.FilterQuery
+		for _, arg := range args {
+			query.Addresses = append(query.Addresses, common.HexToAddress(arg))
+		}
+		if logsCmdEvent != "" {
+			event, indexedKnown, err := parseLogsEvent(logsCmdEvent, abiContent)
+			checkErr(err)
+			if !indexedKnown {
+				log.Printf("indexed args are not specified in --event, the leading args are assumed indexed")
+			}
+			decoder.addEvent(event, indexedKnown)
+			query.Topics, err = buildLogsTopics(event, indexedKnown, logsCmdFilters)
+			checkErr(err)
+		}
+
+		InitGlobalClient(globalOptNodeUrl)
+		fromBlock, err := resolveBlockNumber(globalClient.EthClient, logsCmdFromBlock)
+		checkErr(err)
+		toBlock, err := resolveBlockNumber(globalClient.EthClient, logsCmdToBlock)
+		checkErr(err)
+		if fromBlock > toBlock {
+			log.Fatalf("--from-block %d is greater than --to-block %d", fromBlock, toBlock)
+		}
+
+		var count = 0
+		err = fetchLogs(globalClient.EthClient, query, fromBlock, toBlock, logsCmdMaxRange, func(logs []types.Log) {
+			for i := range logs {
+				out := &LogOutput{
+					BlockNumber:      logs[i].BlockNumber,
+					TxHash:           logs[i].TxHash.Hex(),
+					DecodedLogOutput: decoder.decode(&logs[i]),
+				}
+				if isJsonOutput() {
+					printJsonLine(out)
+				} else {
+					printLog(os.Stdout, out)
+				}
+				count++
+			}
+		})
+		checkErr(err)
+		log.Printf("%d logs found in blocks %d - %d", count, fromBlock, toBlock)
+	},
+}
+
+// printLog prints the decoded log with its block and tx
+func printLog(w io.Writer, out *LogOutput) {
+	fmt.Fprintf(w, "block %d tx %s\n", out.BlockNumber, out.TxHash)
+	printDecodedLogs(w, []*DecodedLogOutput{out.DecodedLogOutput})
+}
+
+var logsIndexedRegex = regexp.MustCompile(`\sindexed\b`)
+
+// parseLogsEvent parses --event, it's an event name in abi, or an event declaration. indexedKnown is false if the
+// declaration is a signature without indexed, e.g. `Transfer(address,address,uint256)`.
+func parseLogsEvent(eventOpt string, abiContent []byte) (abi.Event, bool, error) {
+	var abiEvents []abi.Event
+	if abiContent != nil {
+		contractABI, err := parseContractABI(abiContent)
+		if err != nil {
+			return abi.Event{}, false, err
+		}
+		for _, event := range contractABI.Events {
+			abiEvents = append(abiEvents, event)
+		}
+	}
+
+	if !strings.Contains(eventOpt, "(") {
+		if abiContent == nil {
+			return abi.Event{}, false, fmt.Errorf("event %s is not a declaration, --abi-file is required to find it by name", eventOpt)
+		}
+		var matches []abi.Event
+		var sigs []string
+		for _, event := range abiEvents {
+			if event.RawName == eventOpt {
+				matches = append(matches, event)
+				sigs = append(sigs, event.Sig)
+			}
+		}
+		if len(matches) == 0 {
+			return abi.Event{}, false, fmt.Errorf("event %s is not found in abi", eventOpt)
+		}
+		if len(matches) > 1 {
+			return abi.Event{}, false, fmt.Errorf("event %s is ambiguous, specify one of %v", eventOpt, sigs)
+		}
+		return matches[0], true, nil
+	}
+
+	event, err := parseEventDecl(eventOpt)
+	if err != nil {
+		return abi.Event{}, false, err
+	}
+	// prefer the event in abi, it has names and indexed info
+	for _, abiEvent := range abiEvents {
+		if abiEvent.ID == event.ID {
+			return abiEvent, true, nil
+		}
+	}
+	return event, logsIndexedRegex.MatchString(eventOpt), nil
+}
+
+// buildLogsTopics builds the topics of eth_getLogs, topic0 is the event id, the other topics are built by filters
+// (<arg-name>=<value>) of indexed args. The values of the same arg are OR-ed.
+func buildLogsTopics(event abi.Event, indexedKnown bool, filters []string) ([][]common.Hash, error) {
+	argNames := buildArgNames(event.Inputs)
+	// the topic position of each arg, 0 if it's not indexed
+	var topicIndexes = make(map[string]int)
+	var topicCount = 0
+	for i, input := range event.Inputs {
+		if (indexedKnown && input.Indexed) || (!indexedKnown && i < 3) {
+			topicCount++
+			topicIndexes[argNames[i]] = topicCount
+		}
+	}
+
+	var topics = [][]common.Hash{{event.ID}}
+	for _, filter := range filters {
+		name, value, found := strings.Cut(filter, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid --filter %s, format is <arg-name>=<value>", filter)
+		}
+		var input *abi.Argument
+		for i := range event.Inputs {
+			if argNames[i] == name {
+				input = &event.Inputs[i]
+			}
+		}
+		if input == nil {
+			return nil, fmt.Errorf("arg %s is not found in event %s, args are %v", name, event.Sig, argNames)
+		}
+		topicIndex := topicIndexes[name]
+		if topicIndex == 0 {
+			return nil, fmt.Errorf("arg %s of event %s is not indexed, it can not be filtered", name, event.Sig)
+		}
+
+		topic, err := encodeTopic(input.Type.String(), value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid --filter %s: %w", filter, err)
+		}
+		for len(topics) <= topicIndex {
+			topics = append(topics, nil)
+		}
+		topics[topicIndex] = append(topics[topicIndex], topic)
+	}
+	return topics, nil
+}
+
+// encodeTopic encodes value of indexed arg to topic, the value of string and bytes is hashed. Arrays and tuples are
+// not supported.
+func encodeTopic(argType string, value string) (common.Hash, error) {
+	argValue, err := parseArgValue(argType, value)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	_, typedValue, err := buildTypedValue(argType, argValue)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	topics, err := abi.MakeTopics([]interface{}{typedValue})
+	if err != nil {
+		return common.Hash{}, fmt.Errorf("type %s is not supported: %w", argType, err)
+	}
+	return topics[0][0], nil
+}
+
+// resolveBlockNumber resolves block number, hash or tag to block number
+func resolveBlockNumber(client *ethclient.Client, block string) (uint64, error) {
+	bnh, err := parseBlockOpt(block)
+	if err != nil {
+		return 0, fmt.Errorf("invalid block %s: %w", block, err)
+	}
+	var header *types.Header
+	if number, ok := bnh.Number(); ok {
+		if number >= 0 {
+			return uint64(number), nil
+		}
+		header, err = client.HeaderByNumber(context.Background(), big.NewInt(number.Int64()))
+	} else {
+		hash, _ := bnh.Hash()
+		header, err = client.HeaderByHash(context.Background(), hash)
+	}
+	if err != nil {
+		return 0, fmt.Errorf("get block %s fail: %w", block, err)
+	}
+	return header.Number.Uint64(), nil
+}
+
+// logsSuggestedRangeRegex matches the block range suggested in error of eth_getLogs by some providers (e.g. alchemy),
+// e.g. "... this block range should work: [0x1b4f7a0, 0x1b4fc9f]"
+var logsSuggestedRangeRegex = regexp.MustCompile(`\[(0x[0-9a-fA-F]+),\s*(0x[0-9a-fA-F]+)\]`)
+
+// fetchLogs queries logs in blocks [fromBlock, toBlock] by eth_getLogs, at most maxRange (0 means no limit) blocks in
+// one request. If node rejects the range by range or result size limit, it's split into halves (or the range
+// suggested by node), other errors are returned directly. The range grows back after success, but not to the size
+// rejected before. handle is called with logs of each range in order.
+func fetchLogs(client *ethclient.Client, query ethereum.FilterQuery, fromBlock uint64, toBlock uint64, maxRange uint64, handle func(logs []types.Log)) error {
+	var size = toBlock - fromBlock + 1
+	if maxRange > 0 && maxRange < size {
+		size = maxRange
+	}
+	var ceiling = size // the max size to grow back to, it's lowered below the rejected sizes
+
+	for from := fromBlock; from <= toBlock; {
+		to := min(from+size-1, toBlock)
+		query.FromBlock = new(big.Int).SetUint64(from)
+		query.ToBlock = new(big.Int).SetUint64(to)
+		logs, err := client.FilterLogs(context.Background(), query)
+		if err != nil {
+			matches := logsSuggestedRangeRegex.FindStringSubmatch(err.Error())
+			if matches == nil && !resultLimitMessageRE.MatchString(err.Error()) {
+				return fmt.Errorf("eth_getLogs of blocks %d - %d fail: %w", from, to, err)
+			}
+			if from == to {
+				return fmt.Errorf("eth_getLogs of block %d fail: %w", from, err)
+			}
+			ceiling = to - from
+			size = (to - from + 1) / 2
+			if matches != nil {
+				start, err1 := hexutil.DecodeUint64(matches[1])
+				end, err2 := hexutil.DecodeUint64(matches[2])
+				if err1 == nil && err2 == nil && start == from && end >= from && end < to {
+					size = end - from + 1
+					ceiling = size
+				}
+			}
+			log.Printf("eth_getLogs of blocks %d - %d failed (%v), split it into ranges of %d blocks", from, to, err, size)
+			continue
+		}
+
+		var kept []types.Log
+		for _, l := range logs {
+			if !l.Removed {
+				kept = append(kept, l)
+			}
+		}
+		handle(kept)
+		if to == toBlock {
+			break
+		}
+		from = to + 1
+		size = min(size*2, ceiling)
+	}
+	return nil
+}
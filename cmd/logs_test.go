@@ -0,0 +1,217 @@
+package cmd
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+func TestParseLogsEvent(t *testing.T) {
+	abiContent := []byte(`[{"type":"event","name":"Stored","anonymous":false,"inputs":[{"name":"id","type":"uint256","indexed":true},{"name":"note","type":"string","indexed":false}]},
+		{"type":"event","name":"Moved","anonymous":false,"inputs":[{"name":"id","type":"uint256","indexed":true}]},
+		{"type":"event","name":"Moved","anonymous":false,"inputs":[{"name":"id","type":"uint256","indexed":true},{"name":"to","type":"address","indexed":true}]}]`)
+
+	tests := []struct {
+		event                string
+		abiContent           []byte
+		expectedSig          string // empty if error is expected
+		expectedIndexedKnown bool
+	}{
+		{"Transfer(address indexed from, address indexed to, uint256 value)", nil, "Transfer(address,address,uint256)", true},
+		{"Transfer(address,address,uint256)", nil, "Transfer(address,address,uint256)", false},
+		{"Stored", abiContent, "Stored(uint256,string)", true},
+		{"Stored(uint256,string)", abiContent, "Stored(uint256,string)", true}, // indexed info is from abi
+		{"Moved", abiContent, "", false},                                       // ambiguous
+		{"Moved(uint256,address)", abiContent, "Moved(uint256,address)", true},
+		{"NotExist", abiContent, "", false},
+		{"Stored", nil, "", false},
+	}
+
+	for i, tc := range tests {
+		event, indexedKnown, err := parseLogsEvent(tc.event, tc.abiContent)
+		if tc.expectedSig == "" {
+			if err == nil {
+				t.Fatalf("test %d: expect error, got: %v", i+1, event.Sig)
+			}
+			continue
+		}
+		if err != nil || event.Sig != tc.expectedSig || indexedKnown != tc.expectedIndexedKnown {
+			t.Fatalf("test %d: expected: %v %v, got: %v %v (%v)", i+1, tc.expectedSig, tc.expectedIndexedKnown, event.Sig, indexedKnown, err)
+		}
+	}
+}
+
+func TestBuildLogsTopics(t *testing.T) {
+	addr1 := common.HexToAddress("0x8F36975cdeA2e6E64f85719788C8EFBBe89DFBbb")
+	addr2 := common.HexToAddress("0xB2aC853cF815B47903bc19BF4860540306F4f944")
+	transfer, _ := parseEventDecl("Transfer(address indexed from, address indexed to, uint256 value)")
+	transferSig, _ := parseEventDecl("Transfer(address,address,uint256)")
+	named, _ := parseEventDecl("Named(string indexed name, int8 indexed delta, bool indexed flag)")
+
+	tests := []struct {
+		event        abi.Event
+		indexedKnown bool
+		filters      []string
+		expected     [][]common.Hash // nil if error is expected
+	}{
+		{transfer, true, nil, [][]common.Hash{{transfer.ID}}},
+		{transfer, true, []string{"to=" + addr1.Hex()},
+			[][]common.Hash{{transfer.ID}, nil, {common.BytesToHash(addr1.Bytes())}}},
+		{transfer, true, []string{"from=" + addr1.Hex(), "from=" + addr2.Hex()},
+			[][]common.Hash{{transfer.ID}, {common.BytesToHash(addr1.Bytes()), common.BytesToHash(addr2.Bytes())}}},
+		// the leading args are assumed indexed, so the ERC721 tokenId can be filtered
+		{transferSig, false, []string{"arg2=42"},
+			[][]common.Hash{{transfer.ID}, nil, nil, {common.BigToHash(big.NewInt(42))}}},
+		{named, true, []string{"name=abc", "delta=-1", "flag=true"},
+			[][]common.Hash{{named.ID}, {crypto.Keccak256Hash([]byte("abc"))}, {common.HexToHash("0x" + strings.Repeat("ff", 32))}, {common.BigToHash(big.NewInt(1))}}},
+		{transfer, true, []string{"value=1"}, nil},      // not indexed
+		{transfer, true, []string{"amount=1"}, nil},     // not found
+		{transfer, true, []string{"from"}, nil},         // invalid format
+		{transferSig, false, []string{"arg2=abc"}, nil}, // invalid uint256
+	}
+
+	for i, tc := range tests {
+		got, err := buildLogsTopics(tc.event, tc.indexedKnown, tc.filters)
+		if tc.expected == nil {
+			if err == nil {
+				t.Fatalf("test %d: expect error, got: %v", i+1, got)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, tc.expected) {
+			t.Fatalf("test %d: expected: %v, got: %v (%v)", i+1, tc.expected, got, err)
+		}
+	}
+}
+
+// stubLogsEth implements eth_getLogs, the ranges wider than maxRange are rejected
+type stubLogsEth struct {
+	maxRange uint64
+	suggest  bool   // suggest a range in error message like alchemy
+	err      string // if it's not empty, all requests fail with it
+	logs     []*types.Log
+	ranges   [][2]uint64
+}
+
+func (s *stubLogsEth) GetLogs(args map[string]interface{}) ([]*types.Log, error) {
+	from, _ := hexutil.DecodeUint64(args["fromBlock"].(string))
+	to, _ := hexutil.DecodeUint64(args["toBlock"].(string))
+	s.ranges = append(s.ranges, [2]uint64{from, to})
+	if s.err != "" {
+		return nil, fmt.Errorf("%s", s.err)
+	}
+	if to-from+1 > s.maxRange {
+		if s.suggest {
+			return nil, fmt.Errorf("Log response size exceeded. this block range should work: [%#x, %#x]", from, from+s.maxRange-1)
+		}
+		return nil, fmt.Errorf("block range is too wide")
+	}
+	var result = []*types.Log{}
+	for _, l := range s.logs {
+		if l.BlockNumber >= from && l.BlockNumber <= to {
+			result = append(result, l)
+		}
+	}
+	return result, nil
+}
+
+func TestFetchLogs(t *testing.T) {
+	var logs []*types.Log
+	for _, block := range []uint64{100, 110, 111, 125} {
+		logs = append(logs, &types.Log{
+			Address:     common.HexToAddress("0xdAC17F958D2ee523a2206206994597C13D831ec7"),
+			Topics:      []common.Hash{},
+			Data:        []byte{},
+			BlockNumber: block,
+			TxHash:      common.BigToHash(new(big.Int).SetUint64(block)),
+			Removed:     block == 111, // removed by reorg, it's skipped
+		})
+	}
+
+	tests := []struct {
+		stub           *stubLogsEth
+		maxRange       uint64
+		expectedRanges [][2]uint64
+		expectErr      bool
+	}{
+		{&stubLogsEth{maxRange: 100}, 0, [][2]uint64{{100, 125}}, false},
+		// the range grows back after success, but not to the rejected size
+		{&stubLogsEth{maxRange: 10}, 0, [][2]uint64{{100, 125}, {100, 112}, {100, 105}, {106, 117}, {106, 111}, {112, 122}, {112, 116}, {117, 125}}, false},
+		{&stubLogsEth{maxRange: 10, suggest: true}, 0, [][2]uint64{{100, 125}, {100, 109}, {110, 119}, {120, 125}}, false},
+		{&stubLogsEth{maxRange: 10}, 9, [][2]uint64{{100, 108}, {109, 117}, {118, 125}}, false},
+		{&stubLogsEth{maxRange: 0}, 2, [][2]uint64{{100, 101}, {100, 100}}, true},
+		// other errors are not retried with smaller range
+		{&stubLogsEth{maxRange: 100, err: "401 Unauthorized"}, 0, [][2]uint64{{100, 125}}, true},
+		{&stubLogsEth{maxRange: 100, err: "missing trie node"}, 10, [][2]uint64{{100, 109}}, true},
+	}
+
+	for i, tc := range tests {
+		for _, l := range logs {
+			tc.stub.logs = append(tc.stub.logs, l)
+		}
+		rpcClient := dialStubRpc(t, map[string]any{"eth": tc.stub})
+
+		var blocks []uint64
+		err := fetchLogs(ethclient.NewClient(rpcClient), ethereum.FilterQuery{}, 100, 125, tc.maxRange, func(logs []types.Log) {
+			for _, l := range logs {
+				blocks = append(blocks, l.BlockNumber)
+			}
+		})
+
+		if tc.expectErr {
+			if err == nil || !reflect.DeepEqual(tc.stub.ranges, tc.expectedRanges) {
+				t.Fatalf("test %d: expected: error after ranges %v, got: ranges %v (%v)", i+1, tc.expectedRanges, tc.stub.ranges, err)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(tc.stub.ranges, tc.expectedRanges) || !reflect.DeepEqual(blocks, []uint64{100, 110, 125}) {
+			t.Fatalf("test %d: expected: ranges %v and blocks [100 110 125], got: ranges %v and blocks %v (%v)", i+1, tc.expectedRanges, tc.stub.ranges, blocks, err)
+		}
+	}
+}
+
+func TestDecodeLogWithEventFlag(t *testing.T) {
+	from := common.HexToAddress("0x8F36975cdeA2e6E64f85719788C8EFBBe89DFBbb")
+	fooSig, _ := parseEventDecl("Foo(address,uint256)")
+	barDecl, _ := parseEventDecl("Bar(uint256 a, address indexed b)")
+
+	decoder := newEventDecoder(nil)
+	decoder.addEvent(fooSig, false)
+	decoder.addEvent(barDecl, true)
+
+	tests := []struct {
+		log            *types.Log
+		expectedEvent  string
+		expectedParams map[string]any
+	}{
+		{ // the leading args are assumed indexed
+			&types.Log{Topics: []common.Hash{fooSig.ID, common.BytesToHash(from.Bytes())}, Data: common.LeftPadBytes(big.NewInt(7).Bytes(), 32)},
+			"Foo(address,uint256)", map[string]any{"arg0": from.Hex(), "arg1": "7"},
+		},
+		{
+			&types.Log{Topics: []common.Hash{fooSig.ID, common.BytesToHash(from.Bytes()), common.BigToHash(big.NewInt(8))}},
+			"Foo(address,uint256)", map[string]any{"arg0": from.Hex(), "arg1": "8"},
+		},
+		{
+			&types.Log{Topics: []common.Hash{barDecl.ID, common.BytesToHash(from.Bytes())}, Data: common.LeftPadBytes(big.NewInt(9).Bytes(), 32)},
+			"Bar(uint256,address)", map[string]any{"a": "9", "b": from.Hex()},
+		},
+	}
+
+	for i, tc := range tests {
+		got := decoder.decode(tc.log)
+		if got.Event != tc.expectedEvent || got.SigSource != "event" || !reflect.DeepEqual(got.Params, tc.expectedParams) {
+			t.Fatalf("test %d: expected: %v %v, got: %v %v %v", i+1, tc.expectedEvent, tc.expectedParams, got.Event, got.SigSource, got.Params)
+		}
+	}
+}
@@ -1,19 +1,229 @@
 package cmd
 
 import (
-	"encoding/hex"
-	"github.com/ethereum/go-ethereum/common"
-	"github.com/ethereum/go-ethereum/ethclient"
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"log"
 	"math/big"
+	"os"
 	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/spf13/cobra"
 )
 
 const MulticallContractAddr = "0xcA11bde05977b3631167028862bE2a173976CA11" // See https://github.com/mds1/multicall
-const MulticallFuncSignGetEthBalance = "4d2301cc"                          // 4 bytes func signature of `getEthBalance(address)`
-const MulticallFuncSignAggregate = "252dba42"                              // 4 bytes func signature of `aggregate((address,bytes)[])`
+
+// multicall3ABI contains the functions of Multicall3 used by ethutil
+const multicall3ABI = `[
+  {"type":"function","name":"aggregate3","stateMutability":"payable","inputs":[{"name":"calls","type":"tuple[]","components":[{"name":"target","type":"address"},{"name":"allowFailure","type":"bool"},{"name":"callData","type":"bytes"}]}],"outputs":[{"name":"returnData","type":"tuple[]","components":[{"name":"success","type":"bool"},{"name":"returnData","type":"bytes"}]}]},
+  {"type":"function","name":"getEthBalance","stateMutability":"view","inputs":[{"name":"addr","type":"address"}],"outputs":[{"name":"balance","type":"uint256"}]}
+]`
+
+var multicallBatchSize int
+var multicallRequireSuccess bool
+
+func init() {
+	multicallCmd.Flags().IntVarP(&multicallBatchSize, "batch", "", 100, "the number of calls in one aggregate3 call (or json-rpc batch request if Multicall3 is not deployed)")
+	multicallCmd.Flags().BoolVarP(&multicallRequireSuccess, "require-success", "", false, "fail if any call fails, by default (allowFailure of aggregate3) the failed call is reported and others are not affected")
+}
+
+var multicallCmd = &cobra.Command{
+	Use:   "multicall [calls-file]",
+	Short: "Invoke many (constant) contract methods in one request by Multicall3 aggregate3, the calls are read from file or stdin",
+	Long: `Invoke many (constant) contract methods in one request by Multicall3 aggregate3, the calls are read from file or stdin.
+Each line of calls-file is a call: <contract-address> <function-definition> arg1 arg2 ...
+Quote the function definition if it contains spaces, empty lines and lines start with # are ignored. For example:
+
+0xdAC17F958D2ee523a2206206994597C13D831ec7 'balanceOf(address) returns (uint256)' 0x703662e526d2b71944fbfb9d87f61de3e0f0f290
+0xdAC17F958D2ee523a2206206994597C13D831ec7 'decimals() returns (uint8)'
+
+If Multicall3 is not deployed on the chain, the calls are sent by json-rpc batch requests.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if multicallBatchSize <= 0 {
+			log.Fatalf("--batch must be positive")
+		}
+
+		var input io.Reader = os.Stdin
+		if len(args) > 0 && args[0] != "-" {
+			file, err := os.Open(args[0])
+			checkErr(err)
+			defer file.Close()
+			input = file
+		}
+		calls, err := parseMulticallCalls(input)
+		checkErr(err)
+		if len(calls) == 0 {
+			log.Fatalf("no call found")
+		}
+
+		InitGlobalClient(globalOptNodeUrl)
+
+		var call3s []multicall3Call
+		for _, call := range calls {
+			call3s = append(call3s, multicall3Call{Target: call.target, AllowFailure: !multicallRequireSuccess, CallData: call.callData})
+		}
+		results, err := multicall(globalClient.RpcClient, globalClient.EthClient, call3s, multicallBatchSize)
+		checkErr(err)
+
+		var outputs []MulticallOutput
+		for i, call := range calls {
+			out := MulticallOutput{
+				Target:    call.target.Hex(),
+				Signature: call.funcSignature,
+				Success:   results[i].Success,
+			}
+			if results[i].Success {
+				out.Result = buildQueryOutput(call.returnArgs, results[i].ReturnData)
+			} else {
+				out.RevertReason = decodeRevertData(results[i].ReturnData, nil, GetFuncSig)
+			}
+			outputs = append(outputs, out)
+		}
+
+		if isJsonOutput() {
+			printJson(outputs)
+			return
+		}
+		for i, out := range outputs {
+			fmt.Printf("[%d] %s %s\n", i, out.Target, out.Signature)
+			if !out.Success {
+				fmt.Printf("failed: %s\n", out.RevertReason)
+				continue
+			}
+			if len(calls[i].returnArgs) == 0 {
+				fmt.Printf("output = %s\n", hexutil.Encode(results[i].ReturnData))
+				continue
+			}
+			if err := printReturnValues(calls[i].returnArgs, results[i].ReturnData); err != nil {
+				fmt.Printf("decode output %s failed: %v\n", hexutil.Encode(results[i].ReturnData), err)
+			}
+		}
+	},
+}
+
+// MulticallOutput is the json output (--output json) of a call in multicall command
+type MulticallOutput struct {
+	Target       string       `json:"target"`
+	Signature    string       `json:"signature"`
+	Success      bool         `json:"success"`
+	Result       *QueryOutput `json:"result,omitempty"`       // only for succeeded call
+	RevertReason string       `json:"revertReason,omitempty"` // only for failed call
+}
+
+// multicallInput is a parsed line of calls-file
+type multicallInput struct {
+	target        common.Address
+	funcSignature string
+	callData      []byte
+	returnArgs    abi.Arguments
+}
+
+// parseMulticallCalls parses calls, a line is a call: <contract-address> <function-definition> arg1 arg2 ...
+func parseMulticallCalls(r io.Reader) ([]multicallInput, error) {
+	var calls []multicallInput
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 1024*1024), 1024*1024)
+	var lineNum = 0
+	for scanner.Scan() {
+		lineNum++
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+
+		fields, err := splitQuotedFields(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+		if len(fields) < 2 {
+			return nil, fmt.Errorf("line %d: contract address and function definition are required", lineNum)
+		}
+		if !isValidEthAddress(fields[0]) {
+			return nil, fmt.Errorf("line %d: %s is NOT a valid eth address", lineNum, fields[0])
+		}
+
+		callData, err := buildTxInputData(fields[1], fields[2:])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+		returnArgs, err := buildReturnArgs(fields[1])
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", lineNum, err)
+		}
+		calls = append(calls, multicallInput{
+			target:        common.HexToAddress(fields[0]),
+			funcSignature: fields[1],
+			callData:      callData,
+			returnArgs:    returnArgs,
+		})
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return calls, nil
+}
+
+// splitQuotedFields splits line by whitespace, the single or double quoted part is kept as one field
+func splitQuotedFields(line string) ([]string, error) {
+	var fields []string
+	var current strings.Builder
+	var inField = false
+	var quote rune = 0
+	for _, c := range line {
+		switch {
+		case quote != 0:
+			if c == quote {
+				quote = 0
+			} else {
+				current.WriteRune(c)
+			}
+		case c == '\'' || c == '"':
+			quote = c
+			inField = true
+		case c == ' ' || c == '\t':
+			if inField {
+				fields = append(fields, current.String())
+				current.Reset()
+				inField = false
+			}
+		default:
+			current.WriteRune(c)
+			inField = true
+		}
+	}
+	if quote != 0 {
+		return nil, fmt.Errorf("unclosed quote %c", quote)
+	}
+	if inField {
+		fields = append(fields, current.String())
+	}
+	return fields, nil
+}
+
+// multicall3Call is the struct Call3 of Multicall3
+type multicall3Call struct {
+	Target       common.Address
+	AllowFailure bool
+	CallData     []byte
+}
+
+// multicall3Result is the struct Result of Multicall3, ReturnData is the revert data if call failed
+type multicall3Result struct {
+	Success    bool
+	ReturnData []byte
+}
 
 func isMulticallDeployed(client *ethclient.Client) bool {
-	deployed, err := isContractAddress(client, common.HexToAddress(MulticallContractAddr))
+	deployed, err := isContractAddressAt(client, common.HexToAddress(MulticallContractAddr), blockArg())
 	if err != nil {
 		return false
 	}
@@ -21,64 +231,127 @@ func isMulticallDeployed(client *ethclient.Client) bool {
 	return deployed
 }
 
-func queryEthBalancesByMulticall(addresses []string) ([]*big.Int, error) {
-	contractAddress := common.HexToAddress(MulticallContractAddr)
+// multicall invokes calls by Multicall3 aggregate3, batchSize calls in one eth_call.
+// The calls are sent by json-rpc batch requests if Multicall3 is not deployed.
+func multicall(rpcClient *rpc.Client, client *ethclient.Client, calls []multicall3Call, batchSize int) ([]multicall3Result, error) {
+	var aggregate aggregateFunc = aggregate3
+	if !isMulticallDeployed(client) {
+		log.Printf("Multicall contract is not deployed on chain %s, use json-rpc batch requests", globalChainId)
+		aggregate = batchCalls
+	}
+	return aggregateInBatches(rpcClient, aggregate, calls, batchSize)
+}
+
+// aggregateFunc invokes calls in one request, it's aggregate3 or batchCalls
+type aggregateFunc func(rpcClient *rpc.Client, calls []multicall3Call) ([]multicall3Result, error)
 
-	funcSignGetEthBalance, err := hex.DecodeString(MulticallFuncSignGetEthBalance)
+// aggregateInBatches splits calls into batches of batchSize, each batch is invoked by aggregate
+func aggregateInBatches(rpcClient *rpc.Client, aggregate aggregateFunc, calls []multicall3Call, batchSize int) ([]multicall3Result, error) {
+	var results []multicall3Result
+	for i := 0; i < len(calls); i += batchSize {
+		end := i + batchSize
+		if end > len(calls) {
+			end = len(calls)
+		}
+		batchResults, err := aggregate(rpcClient, calls[i:end])
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, batchResults...)
+	}
+	return results, nil
+}
+
+// aggregate3 invokes Multicall3 function:
+// function aggregate3(Call3[] calldata calls) public payable returns (Result[] memory returnData)
+func aggregate3(rpcClient *rpc.Client, calls []multicall3Call) ([]multicall3Result, error) {
+	parsedABI, err := abi.JSON(strings.NewReader(multicall3ABI))
 	if err != nil {
 		return nil, err
 	}
+	input, err := parsedABI.Pack("aggregate3", calls)
+	if err != nil {
+		return nil, fmt.Errorf("pack aggregate3 fail: %w", err)
+	}
+
+	output, err := CallAt(rpcClient, common.HexToAddress(MulticallContractAddr), input, blockArg())
+	if err != nil {
+		return nil, fmt.Errorf("call aggregate3 fail: %w", err)
+	}
 
-	funcSignAggregate, err := hex.DecodeString(MulticallFuncSignAggregate)
+	values, err := parsedABI.Unpack("aggregate3", output)
+	if err != nil {
+		return nil, fmt.Errorf("unpack aggregate3 fail: %w", err)
+	}
+	results := *abi.ConvertType(values[0], new([]multicall3Result)).(*[]multicall3Result)
+	if len(results) != len(calls) {
+		return nil, fmt.Errorf("aggregate3 returns %d results, but %d calls are sent", len(results), len(calls))
+	}
+	return results, nil
+}
+
+// batchCalls sends calls by json-rpc batch request of eth_call, it's the fallback of aggregate3
+func batchCalls(rpcClient *rpc.Client, calls []multicall3Call) ([]multicall3Result, error) {
+	var batch = make([]rpc.BatchElem, len(calls))
+	var outputs = make([]hexutil.Bytes, len(calls))
+	for i, call := range calls {
+		target := call.Target
+		batch[i] = rpc.BatchElem{
+			Method: "eth_call",
+			Args:   []interface{}{toCallArg(ethereum.CallMsg{To: &target, Data: call.CallData}), blockArg()},
+			Result: &outputs[i],
+		}
+	}
+	if err := rpcClient.BatchCallContext(context.Background(), batch); err != nil {
+		return nil, fmt.Errorf("BatchCallContext fail: %w", err)
+	}
+
+	var results = make([]multicall3Result, len(calls))
+	for i, elem := range batch {
+		if elem.Error == nil {
+			results[i] = multicall3Result{Success: true, ReturnData: outputs[i]}
+			continue
+		}
+		revertData, isRevert := revertDataFromError(elem.Error)
+		if !isRevert && !strings.Contains(elem.Error.Error(), "revert") {
+			return nil, fmt.Errorf("eth_call of call %d fail: %w", i, elem.Error)
+		}
+		if !calls[i].AllowFailure {
+			return nil, fmt.Errorf("call %d fail: %w", i, wrapRevertError(elem.Error))
+		}
+		results[i] = multicall3Result{Success: false, ReturnData: revertData}
+	}
+	return results, nil
+}
+
+// queryEthBalancesByMulticall queries balances of addresses by Multicall3 getEthBalance in one aggregate3 call
+func queryEthBalancesByMulticall(addresses []string) ([]*big.Int, error) {
+	parsedABI, err := abi.JSON(strings.NewReader(multicall3ABI))
 	if err != nil {
 		return nil, err
 	}
 
-	// build tx input data
-	var callDataForAggregate []string
+	var calls []multicall3Call
 	for _, address := range addresses {
-		parameter, err := encodeParameters([]string{"address"}, []string{address})
+		callData, err := parsedABI.Pack("getEthBalance", common.HexToAddress(address))
 		if err != nil {
 			return nil, err
 		}
+		calls = append(calls, multicall3Call{Target: common.HexToAddress(MulticallContractAddr), CallData: callData})
+	}
 
-		// prepare call data for function getEthBalance(address)
-		callDataForGetEthBalance := append(funcSignGetEthBalance, parameter...)
-
-		callDataForAggregate = append(callDataForAggregate,
-			"("+contractAddress.String()+",0x"+hex.EncodeToString(callDataForGetEthBalance)+")")
-	}
-	txInputData, err := encodeParameters([]string{"(address,bytes)[]"},
-		[]string{"[" + strings.Join(callDataForAggregate, ",") + "]"})
-	// fmt.Printf("txInputData = %x\n", txInputData)
-
-	// call multicall contract function aggregate:
-	// function aggregate((address,bytes)[]) public payable returns (uint256 blockNumber, bytes[] memory returnData)
-	output, err := Call(globalClient.RpcClient, contractAddress, append(funcSignAggregate, txInputData...))
-	checkErr(err)
-	// fmt.Printf("output = %x\n", output)
-	//
-	// Here is an output example of query balances for two addresses:
-	// 0000000000000000000000000000000000000000000000000000000000865cd5000000000000000000000000000000000000000000000000000000000000004000000000000000000000000000000000000000000000000000000000000000020000000000000000000000000000000000000000000000000000000000000040000000000000000000000000000000000000000000000000000000000000008000000000000000000000000000000000000000000000000000000000000000200000000000000000000000000000000000000000000000074bfbaccf3734130a00000000000000000000000000000000000000000000000000000000000000200000000000000000000000000000000000000000000000020116afda686cbe3c
-	//[0]:  0x0000000000000000000000000000000000000000000000000000000000865cd5  // blockNumber
-	//[1]:  0x0000000000000000000000000000000000000000000000000000000000000040  // offset of returnData
-	//[2]:  0x0000000000000000000000000000000000000000000000000000000000000002  // length of returnData
-	//[3]:  0x0000000000000000000000000000000000000000000000000000000000000040  // offset of first element
-	//[4]:  0x0000000000000000000000000000000000000000000000000000000000000080  // offset of second element
-	//[5]:  0x0000000000000000000000000000000000000000000000000000000000000020  // length of first element
-	//[6]:  0x0000000000000000000000000000000000000000000000074bfbaccf3734130a  // first element (first address balance)   <- result 0
-	//[7]:  0x0000000000000000000000000000000000000000000000000000000000000020  // length of second element
-	//[8]:  0x0000000000000000000000000000000000000000000000020116afda686cbe3c  // second element (second address balance)  <- result 1
-
-	balancePart := output[len(output)-(64*len(addresses)):] // take last 64*len(addresses) bytes
-	// fmt.Printf("balancePart = %x\n", balancePart)
+	results, err := aggregate3(globalClient.RpcClient, calls)
+	if err != nil {
+		return nil, err
+	}
 
 	rv := make([]*big.Int, len(addresses))
-	for index := range addresses {
-		result := balancePart[64*index+32 : 64*index+64]
-		balance := new(big.Int)
-		balance.SetString(hex.EncodeToString(result), 16)
-		rv[index] = balance
+	for index, result := range results {
+		values, err := parsedABI.Unpack("getEthBalance", result.ReturnData)
+		if err != nil {
+			return nil, fmt.Errorf("unpack getEthBalance fail: %w", err)
+		}
+		rv[index] = values[0].(*big.Int)
 	}
 
 	return rv, nil
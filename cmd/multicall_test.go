@@ -0,0 +1,152 @@
+package cmd
+
+import (
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/ethclient"
+)
+
+func TestSplitQuotedFields(t *testing.T) {
+	tests := []struct {
+		line     string
+		expected []string
+	}{
+		{"0x01 'balanceOf(address) returns (uint256)' 0x02", []string{"0x01", "balanceOf(address) returns (uint256)", "0x02"}},
+		{`0x01  "decimals() returns (uint8)"`, []string{"0x01", "decimals() returns (uint8)"}},
+		{"0x01 totalSupply()\t''", []string{"0x01", "totalSupply()", ""}},
+		{"0x01 f(string) 'a \"b\" c'", []string{"0x01", "f(string)", `a "b" c`}},
+	}
+
+	for i, tc := range tests {
+		got, err := splitQuotedFields(tc.line)
+		if err != nil || !reflect.DeepEqual(got, tc.expected) {
+			t.Fatalf("test %d: expected: %q, got: %q (%v)", i+1, tc.expected, got, err)
+		}
+	}
+
+	if _, err := splitQuotedFields("0x01 'balanceOf(address)"); err == nil {
+		t.Fatalf("expect error for unclosed quote")
+	}
+}
+
+// stubMulticallEth is a stub of eth namespace, it simulates a token contract and optionally the Multicall3
+type stubMulticallEth struct {
+	multicallDeployed bool
+	token             common.Address
+}
+
+// execute simulates the token contract, returns the output or the revert data
+func (s *stubMulticallEth) execute(to common.Address, data []byte) ([]byte, []byte) {
+	notSupported, _ := buildTxInputData("Error(string)", []string{"not supported"})
+	if to != s.token || len(data) < 4 {
+		return nil, notSupported
+	}
+	switch hexutil.Encode(data[:4]) {
+	case "0x70a08231": // balanceOf(address)
+		return common.LeftPadBytes(big.NewInt(1000).Bytes(), 32), nil
+	case "0x313ce567": // decimals()
+		return common.LeftPadBytes([]byte{6}, 32), nil
+	case "0x95d89b41": // symbol()
+		symbolArgs, _ := buildReturnArgs("symbol() returns (string)")
+		output, _ := symbolArgs.Pack("USDT")
+		return output, nil
+	}
+	return nil, notSupported
+}
+
+func (s *stubMulticallEth) GetCode(addr common.Address, block string) (hexutil.Bytes, error) {
+	if s.multicallDeployed && addr == common.HexToAddress(MulticallContractAddr) {
+		return hexutil.Bytes{0x60, 0x80}, nil
+	}
+	return hexutil.Bytes{}, nil
+}
+
+func (s *stubMulticallEth) Call(args map[string]interface{}, block string) (hexutil.Bytes, error) {
+	to := common.HexToAddress(args["to"].(string))
+	data := hexutil.MustDecode(args["data"].(string))
+
+	if !s.multicallDeployed || to != common.HexToAddress(MulticallContractAddr) {
+		output, revertData := s.execute(to, data)
+		if revertData != nil {
+			return nil, &stubRevertError{data: hexutil.Encode(revertData)}
+		}
+		return output, nil
+	}
+
+	parsedABI, _ := abi.JSON(strings.NewReader(multicall3ABI))
+	method := parsedABI.Methods["aggregate3"]
+	values, err := method.Inputs.Unpack(data[4:])
+	if err != nil {
+		return nil, err
+	}
+	var results []multicall3Result
+	for _, call := range *abi.ConvertType(values[0], new([]multicall3Call)).(*[]multicall3Call) {
+		output, revertData := s.execute(call.Target, call.CallData)
+		if revertData != nil && !call.AllowFailure {
+			return nil, &stubRevertError{data: "0x"}
+		}
+		if revertData != nil {
+			results = append(results, multicall3Result{Success: false, ReturnData: revertData})
+		} else {
+			results = append(results, multicall3Result{Success: true, ReturnData: output})
+		}
+	}
+	return method.Outputs.Pack(results)
+}
+
+func TestMulticall(t *testing.T) {
+	token := common.HexToAddress("0xdAC17F958D2ee523a2206206994597C13D831ec7")
+	callsFile := `# calls of token
+0xdAC17F958D2ee523a2206206994597C13D831ec7 'balanceOf(address) returns (uint256)' 0x8F36975cdeA2e6E64f85719788C8EFBBe89DFBbb
+0xdAC17F958D2ee523a2206206994597C13D831ec7 "decimals() returns (uint8)"
+
+0xdAC17F958D2ee523a2206206994597C13D831ec7 'name() returns (string)'
+`
+	calls, err := parseMulticallCalls(strings.NewReader(callsFile))
+	if err != nil {
+		t.Fatalf("parseMulticallCalls failed: %v", err)
+	}
+	if len(calls) != 3 || calls[1].funcSignature != "decimals() returns (uint8)" || len(calls[2].returnArgs) != 1 {
+		t.Fatalf("expected: 3 calls, got: %+v", calls)
+	}
+
+	for _, multicallDeployed := range []bool{true, false} {
+		rpcClient := dialStubRpc(t, map[string]any{"eth": &stubMulticallEth{multicallDeployed: multicallDeployed, token: token}})
+
+		var call3s []multicall3Call
+		for _, call := range calls {
+			call3s = append(call3s, multicall3Call{Target: call.target, AllowFailure: true, CallData: call.callData})
+		}
+		results, err := multicall(rpcClient, ethclient.NewClient(rpcClient), call3s, 2)
+		if err != nil {
+			t.Fatalf("multicall (deployed: %v) failed: %v", multicallDeployed, err)
+		}
+
+		expected := []struct {
+			success bool
+			value   string
+		}{{true, "1000"}, {true, "6"}, {false, "not supported"}}
+		for i, result := range results {
+			var got string
+			if result.Success {
+				got = buildQueryOutput(calls[i].returnArgs, result.ReturnData).Returns[0].Value.(string)
+			} else {
+				got = decodeRevertData(result.ReturnData, nil, nil)
+			}
+			if result.Success != expected[i].success || got != expected[i].value {
+				t.Fatalf("test %d (deployed: %v): expected: %v %v, got: %v %v", i+1, multicallDeployed, expected[i].success, expected[i].value, result.Success, got)
+			}
+		}
+
+		call3s[2].AllowFailure = false
+		if _, err := multicall(rpcClient, ethclient.NewClient(rpcClient), call3s, 2); err == nil {
+			t.Fatalf("expect error if call with allowFailure false fails (deployed: %v)", multicallDeployed)
+		}
+	}
+}
@@ -0,0 +1,30 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+const outputText = "text"
+const outputJson = "json"
+
+// isJsonOutput returns true if --output json is specified.
+// In json mode, only one json document is printed to stdout (or one per line by streaming commands, e.g. logs),
+// logs are kept on stderr.
+func isJsonOutput() bool {
+	return globalOptOutput == outputJson
+}
+
+// printJson prints v as indented json to stdout
+func printJson(v any) {
+	data, err := json.MarshalIndent(v, "", "  ")
+	checkErr(err)
+	fmt.Println(string(data))
+}
+
+// printJsonLine prints v as one line json to stdout, it's used by the commands streaming results
+func printJsonLine(v any) {
+	data, err := json.Marshal(v)
+	checkErr(err)
+	fmt.Println(string(data))
+}
@@ -0,0 +1,229 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/cobra"
+)
+
+var overrideFile string
+var overrideBalances []string
+var overrideNonces []string
+var overrideCodes []string
+var overrideStorages []string
+var overrideBlockFields []string
+
+// The state and block overrides passed to eth_call by Call, nil if not specified.
+// See https://geth.ethereum.org/docs/interacting-with-geth/rpc/ns-eth#eth-call
+var callStateOverrides map[common.Address]ethereum.OverrideAccount
+var callBlockOverrides *ethereum.BlockOverrides
+
+// addOverrideFlags adds the flags of state and block overrides of eth_call to cmd
+func addOverrideFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&overrideFile, "override-file", "", "", "the json file of state override set of eth_call, e.g. {\"0x..\": {\"balance\": \"0x..\", \"nonce\": \"0x..\", \"code\": \"0x..\", \"state\": {...}, \"stateDiff\": {...}}}")
+	cmd.Flags().StringArrayVarP(&overrideBalances, "override-balance", "", nil, "override balance of account in eth_call, format: <address>=<balance-in-wei>, can be repeated")
+	cmd.Flags().StringArrayVarP(&overrideNonces, "override-nonce", "", nil, "override nonce of account in eth_call, format: <address>=<nonce>, can be repeated")
+	cmd.Flags().StringArrayVarP(&overrideCodes, "override-code", "", nil, "override code of account in eth_call, format: <address>=<hex-runtime-bytecode>, can be repeated")
+	cmd.Flags().StringArrayVarP(&overrideStorages, "override-storage", "", nil, "override a storage slot of account in eth_call, format: <address>:<slot>=<value>, can be repeated")
+	cmd.Flags().StringArrayVarP(&overrideBlockFields, "override-block", "", nil, "override block header field in eth_call, format: <number|time|gasLimit|coinbase|random|baseFee|difficulty>=<value>, can be repeated")
+}
+
+// hasOverrides returns true if any state or block override is specified
+func hasOverrides() bool {
+	return callStateOverrides != nil || callBlockOverrides != nil
+}
+
+// overrideFileAccount is the account in the json file of --override-file, it's the format of geth
+type overrideFileAccount struct {
+	Nonce     *hexutil.Uint64             `json:"nonce"`
+	Code      *hexutil.Bytes              `json:"code"`
+	Balance   *hexutil.Big                `json:"balance"`
+	State     map[common.Hash]common.Hash `json:"state"`
+	StateDiff map[common.Hash]common.Hash `json:"stateDiff"`
+}
+
+// parseOverrides parses the override flags into callStateOverrides and callBlockOverrides, the flags are applied
+// after --override-file, so they can change a part of the file
+func parseOverrides() error {
+	var stateOverrides = make(map[common.Address]ethereum.OverrideAccount)
+
+	if overrideFile != "" {
+		content, err := os.ReadFile(overrideFile)
+		if err != nil {
+			return err
+		}
+		var accounts map[common.Address]overrideFileAccount
+		if err := json.Unmarshal(content, &accounts); err != nil {
+			return fmt.Errorf("parse %s fail: %w", overrideFile, err)
+		}
+		for addr, account := range accounts {
+			var override = ethereum.OverrideAccount{State: account.State, StateDiff: account.StateDiff}
+			if account.Nonce != nil {
+				override.Nonce = uint64(*account.Nonce)
+			}
+			if account.Code != nil {
+				override.Code = *account.Code
+			}
+			if account.Balance != nil {
+				override.Balance = account.Balance.ToInt()
+			}
+			stateOverrides[addr] = override
+		}
+	}
+
+	for _, item := range overrideBalances {
+		addr, value, err := splitOverrideItem(item)
+		if err != nil {
+			return fmt.Errorf("invalid --override-balance %s: %w", item, err)
+		}
+		balance, ok := new(big.Int).SetString(value, 0)
+		if !ok || balance.Sign() < 0 {
+			return fmt.Errorf("invalid --override-balance %s: invalid balance", item)
+		}
+		override := stateOverrides[addr]
+		override.Balance = balance
+		stateOverrides[addr] = override
+	}
+
+	for _, item := range overrideNonces {
+		addr, value, err := splitOverrideItem(item)
+		if err != nil {
+			return fmt.Errorf("invalid --override-nonce %s: %w", item, err)
+		}
+		nonce, ok := new(big.Int).SetString(value, 0)
+		if !ok || !nonce.IsUint64() {
+			return fmt.Errorf("invalid --override-nonce %s: invalid nonce", item)
+		}
+		override := stateOverrides[addr]
+		override.Nonce = nonce.Uint64()
+		stateOverrides[addr] = override
+	}
+
+	for _, item := range overrideCodes {
+		addr, value, err := splitOverrideItem(item)
+		if err != nil {
+			return fmt.Errorf("invalid --override-code %s: %w", item, err)
+		}
+		code, err := hexutil.Decode(value)
+		if err != nil {
+			return fmt.Errorf("invalid --override-code %s: %w", item, err)
+		}
+		override := stateOverrides[addr]
+		override.Code = code
+		stateOverrides[addr] = override
+	}
+
+	for _, item := range overrideStorages {
+		addrSlot, value, found := strings.Cut(item, "=")
+		addrHex, slotStr, found2 := strings.Cut(addrSlot, ":")
+		if !found || !found2 || !isValidEthAddress(addrHex) {
+			return fmt.Errorf("invalid --override-storage %s: format is <address>:<slot>=<value>", item)
+		}
+		slot, err := parseOverrideWord(slotStr)
+		if err != nil {
+			return fmt.Errorf("invalid --override-storage %s: %w", item, err)
+		}
+		word, err := parseOverrideWord(value)
+		if err != nil {
+			return fmt.Errorf("invalid --override-storage %s: %w", item, err)
+		}
+		addr := common.HexToAddress(addrHex)
+		override := stateOverrides[addr]
+		if override.StateDiff == nil {
+			override.StateDiff = make(map[common.Hash]common.Hash)
+		}
+		override.StateDiff[slot] = word
+		stateOverrides[addr] = override
+	}
+
+	callStateOverrides = nil
+	if len(stateOverrides) > 0 {
+		callStateOverrides = stateOverrides
+	}
+
+	callBlockOverrides = nil
+	if len(overrideBlockFields) > 0 {
+		blockOverrides, err := parseBlockOverrides(overrideBlockFields)
+		if err != nil {
+			return err
+		}
+		callBlockOverrides = blockOverrides
+	}
+	return nil
+}
+
+// splitOverrideItem splits <address>=<value>
+func splitOverrideItem(item string) (common.Address, string, error) {
+	addr, value, found := strings.Cut(item, "=")
+	if !found || !isValidEthAddress(addr) {
+		return common.Address{}, "", fmt.Errorf("format is <address>=<value>")
+	}
+	return common.HexToAddress(addr), value, nil
+}
+
+// parseOverrideWord parses a storage slot or value, it can be a decimal or hex number
+func parseOverrideWord(s string) (common.Hash, error) {
+	value, ok := new(big.Int).SetString(s, 0)
+	if !ok || value.Sign() < 0 || value.BitLen() > 256 {
+		return common.Hash{}, fmt.Errorf("%s is not a valid 32 bytes word", s)
+	}
+	return common.BigToHash(value), nil
+}
+
+// parseBlockOverrides parses the items of --override-block
+func parseBlockOverrides(items []string) (*ethereum.BlockOverrides, error) {
+	var blockOverrides = new(ethereum.BlockOverrides)
+	for _, item := range items {
+		field, value, found := strings.Cut(item, "=")
+		if !found {
+			return nil, fmt.Errorf("invalid --override-block %s: format is <field>=<value>", item)
+		}
+		switch field {
+		case "coinbase":
+			if !isValidEthAddress(value) {
+				return nil, fmt.Errorf("invalid --override-block %s: invalid address", item)
+			}
+			blockOverrides.Coinbase = common.HexToAddress(value)
+			continue
+		case "random":
+			word, err := parseOverrideWord(value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid --override-block %s: %w", item, err)
+			}
+			blockOverrides.Random = word
+			continue
+		}
+
+		number, ok := new(big.Int).SetString(value, 0)
+		if !ok || number.Sign() < 0 {
+			return nil, fmt.Errorf("invalid --override-block %s: invalid number", item)
+		}
+		switch field {
+		case "number":
+			blockOverrides.Number = number
+		case "difficulty":
+			blockOverrides.Difficulty = number
+		case "baseFee":
+			blockOverrides.BaseFee = number
+		case "time", "gasLimit":
+			if !number.IsUint64() {
+				return nil, fmt.Errorf("invalid --override-block %s: number too large", item)
+			}
+			if field == "time" {
+				blockOverrides.Time = number.Uint64()
+			} else {
+				blockOverrides.GasLimit = number.Uint64()
+			}
+		default:
+			return nil, fmt.Errorf("invalid --override-block %s: unknown field %s", item, field)
+		}
+	}
+	return blockOverrides, nil
+}
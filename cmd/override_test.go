@@ -0,0 +1,79 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// overrideRecordingEth is a stub of eth namespace, it records the overrides of eth_call
+type overrideRecordingEth struct {
+	stateOverrides *map[string]interface{}
+	blockOverrides *map[string]interface{}
+}
+
+func (s *overrideRecordingEth) Call(args map[string]interface{}, block string, stateOverrides *map[string]interface{}, blockOverrides *map[string]interface{}) (hexutil.Bytes, error) {
+	s.stateOverrides = stateOverrides
+	s.blockOverrides = blockOverrides
+	return common.LeftPadBytes([]byte{1}, 32), nil
+}
+
+func TestParseOverrides(t *testing.T) {
+	defer func() {
+		overrideFile, overrideBalances, overrideNonces, overrideCodes, overrideStorages, overrideBlockFields = "", nil, nil, nil, nil, nil
+		callStateOverrides, callBlockOverrides = nil, nil
+	}()
+
+	overrideFile = filepath.Join(t.TempDir(), "overrides.json")
+	fileContent := `{"0xdAC17F958D2ee523a2206206994597C13D831ec7": {"balance": "0x10", "stateDiff": {"0x0000000000000000000000000000000000000000000000000000000000000001": "0x0000000000000000000000000000000000000000000000000000000000000002"}}}`
+	if err := os.WriteFile(overrideFile, []byte(fileContent), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+	overrideBalances = []string{"0x8F36975cdeA2e6E64f85719788C8EFBBe89DFBbb=1000000000000000000"}
+	overrideNonces = []string{"0x8F36975cdeA2e6E64f85719788C8EFBBe89DFBbb=5"}
+	overrideCodes = []string{"0xdAC17F958D2ee523a2206206994597C13D831ec7=0x6080"}
+	overrideStorages = []string{"0xdAC17F958D2ee523a2206206994597C13D831ec7:3=0xff"}
+	overrideBlockFields = []string{"number=100", "time=0x6553f100", "coinbase=0x8F36975cdeA2e6E64f85719788C8EFBBe89DFBbb"}
+	if err := parseOverrides(); err != nil {
+		t.Fatalf("parseOverrides failed: %v", err)
+	}
+
+	stub := &overrideRecordingEth{}
+	rpcClient := dialStubRpc(t, map[string]any{"eth": stub})
+	if _, err := Call(rpcClient, common.HexToAddress("0xdAC17F958D2ee523a2206206994597C13D831ec7"), []byte{1, 2, 3, 4}); err != nil {
+		t.Fatalf("Call failed: %v", err)
+	}
+
+	gotState, _ := json.Marshal(stub.stateOverrides)
+	expectedState := `{"0x8f36975cdea2e6e64f85719788c8efbbe89dfbbb":{"balance":"0xde0b6b3a7640000","nonce":"0x5"},` +
+		`"0xdac17f958d2ee523a2206206994597c13d831ec7":{"balance":"0x10","code":"0x6080","stateDiff":{` +
+		`"0x0000000000000000000000000000000000000000000000000000000000000001":"0x0000000000000000000000000000000000000000000000000000000000000002",` +
+		`"0x0000000000000000000000000000000000000000000000000000000000000003":"0x00000000000000000000000000000000000000000000000000000000000000ff"}}}`
+	if string(gotState) != expectedState {
+		t.Fatalf("expected: %v, got: %v", expectedState, string(gotState))
+	}
+	gotBlock, _ := json.Marshal(stub.blockOverrides)
+	expectedBlock := `{"feeRecipient":"0x8f36975cdea2e6e64f85719788c8efbbe89dfbbb","number":"0x64","time":"0x6553f100"}`
+	if string(gotBlock) != expectedBlock {
+		t.Fatalf("expected: %v, got: %v", expectedBlock, string(gotBlock))
+	}
+
+	invalidTests := []func(){
+		func() { overrideBalances = []string{"0x8F36975cdeA2e6E64f85719788C8EFBBe89DFBbb"} },
+		func() { overrideNonces = []string{"0x1234=1"} },
+		func() { overrideCodes = []string{"0x8F36975cdeA2e6E64f85719788C8EFBBe89DFBbb=6080"} },
+		func() { overrideStorages = []string{"0x8F36975cdeA2e6E64f85719788C8EFBBe89DFBbb=1"} },
+		func() { overrideBlockFields = []string{"height=1"} },
+	}
+	for i, setInvalid := range invalidTests {
+		overrideFile, overrideBalances, overrideNonces, overrideCodes, overrideStorages, overrideBlockFields = "", nil, nil, nil, nil, nil
+		setInvalid()
+		if err := parseOverrides(); err == nil {
+			t.Fatalf("test %d: expect error for invalid override", i+1)
+		}
+	}
+}
@@ -1,7 +1,6 @@
 package cmd
 
 import (
-	"crypto/ecdsa"
 	"fmt"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"log"
@@ -10,6 +9,12 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// PersonalSignOutput is the json output (--output json) of personal-sign command
+type PersonalSignOutput struct {
+	Signature string `json:"signature"` // r || s || v, v is 27 or 28
+	Signer    string `json:"signer"`
+}
+
 // personalSignCmd represents the personalSign command
 var personalSignCmd = &cobra.Command{
 	Use:   "personal-sign <msg>",
@@ -18,21 +23,25 @@ var personalSignCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		var msg = args[0]
 
-		if globalOptPrivateKey == "" {
-			log.Fatalf("--private-key is required for this command")
+		if !hasSigner() {
+			log.Fatalf("%s is required for this command", signerOptions)
 		}
 
-		privateKey := hexToPrivateKey(globalOptPrivateKey)
-		sig, err := personalSign([]byte(msg), privateKey)
+		signer := loadSigner()
+		sig, err := personalSign([]byte(msg), signer)
 		checkErr(err)
-		fmt.Printf("personal sign: %s, signer address: %s\n", hexutil.Encode(sig), extractAddressFromPrivateKey(privateKey).String())
+		if isJsonOutput() {
+			printJson(PersonalSignOutput{Signature: hexutil.Encode(sig), Signer: signer.Address().String()})
+			return
+		}
+		fmt.Printf("personal sign: %s, signer address: %s\n", hexutil.Encode(sig), signer.Address().String())
 	},
 }
 
 // personalSign Returns personal_sign signature data
 // See: https://eips.ethereum.org/EIPS/eip-191
 // The signature data can be verified in https://etherscan.io/verifiedSignatures
-func personalSign(message []byte, privateKey *ecdsa.PrivateKey) ([]byte, error) {
+func personalSign(message []byte, signer Signer) ([]byte, error) {
 	if len(message) > 2 && string(message[:2]) == "0x" {
 		if decodedMessage, err := hexutil.Decode(string(message)); err == nil {
 			message = decodedMessage
@@ -43,7 +52,7 @@ func personalSign(message []byte, privateKey *ecdsa.PrivateKey) ([]byte, error)
 	// log.Printf("fullMessage: %s", fullMessage)
 	hash := crypto.Keccak256Hash([]byte(fullMessage))
 	log.Printf("pre hash %s", hash.Hex())
-	signatureBytes, err := crypto.Sign(hash.Bytes(), privateKey)
+	signatureBytes, err := signer.SignText(message)
 	if err != nil {
 		return nil, err
 	}
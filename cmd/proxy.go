@@ -0,0 +1,214 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/spf13/cobra"
+)
+
+const proxyTypeEip1967 = "eip1967"
+const proxyTypeEip1967Beacon = "eip1967-beacon"
+const proxyTypeEip1167 = "eip1167"
+const proxyTypeOzLegacy = "oz-legacy"
+
+// The slots of EIP-1967 are keccak256 of the name minus 1, see https://eips.ethereum.org/EIPS/eip-1967
+var eip1967ImplementationSlot = common.HexToHash("0x360894a13ba1a3210667c828492db98dca3e2076cc3735a920a3ca505d382bbc")
+var eip1967AdminSlot = common.HexToHash("0xb53127684a568b3173ae13b9f8a6016e243e63b6e8ee1178d6a717850b5d6103")
+var eip1967BeaconSlot = common.HexToHash("0xa3f0ad74e5423aebfd80d3ef4346578335a9a72aeaee59ff6cb3582b35133d50")
+
+// The slots of proxies of OpenZeppelin (ZeppelinOS) before EIP-1967
+var ozLegacyImplementationSlot = crypto.Keccak256Hash([]byte("org.zeppelinos.proxy.implementation"))
+var ozLegacyAdminSlot = crypto.Keccak256Hash([]byte("org.zeppelinos.proxy.admin"))
+
+// The runtime bytecode of EIP-1167 minimal proxy is eip1167Prefix + implementation + eip1167Suffix
+var eip1167Prefix = common.FromHex("0x363d3d373d3d3d363d73")
+var eip1167Suffix = common.FromHex("0x5af43d82803e903d91602b57fd5bf3")
+
+// ProxyOutput is the json output (--output json) of proxy command
+type ProxyOutput struct {
+	Address        string `json:"address"`
+	Type           string `json:"type,omitempty"` // eip1967 | eip1967-beacon | eip1167 | oz-legacy, empty if it's not a known proxy
+	Implementation string `json:"implementation,omitempty"`
+	Admin          string `json:"admin,omitempty"`
+	Beacon         string `json:"beacon,omitempty"` // only for eip1967-beacon
+}
+
+var proxyCmd = &cobra.Command{
+	Use:   "proxy <address>",
+	Short: "Detect proxy contract and show its implementation, EIP-1967, EIP-1167 and legacy OpenZeppelin proxies are supported",
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("requires address")
+		}
+		if !isValidEthAddress(args[0]) {
+			return fmt.Errorf("%v is not a valid eth address", args[0])
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		InitGlobalClient(globalOptNodeUrl)
+
+		out, err := detectProxy(globalClient.RpcClient, common.HexToAddress(args[0]))
+		checkErr(err)
+
+		if isJsonOutput() {
+			printJson(out)
+			return
+		}
+		if out.Type == "" {
+			log.Printf("%s is not a known proxy", args[0])
+			return
+		}
+		if globalOptTerseOutput {
+			fmt.Println(out.Implementation)
+			return
+		}
+		fmt.Printf("proxy type: %s\n", out.Type)
+		fmt.Printf("implementation: %s\n", out.Implementation)
+		if out.Beacon != "" {
+			fmt.Printf("beacon: %s\n", out.Beacon)
+		}
+		if out.Admin != "" {
+			fmt.Printf("admin: %s\n", out.Admin)
+		}
+	},
+}
+
+// detectProxy detects the type and implementation of proxy, Type of result is empty if it's not a known proxy
+func detectProxy(rpcClient *rpc.Client, address common.Address) (*ProxyOutput, error) {
+	var out = &ProxyOutput{Address: address.Hex()}
+
+	code, err := codeAt(rpcClient, address, blockArg())
+	if err != nil {
+		return nil, err
+	}
+	if len(code) == len(eip1167Prefix)+common.AddressLength+len(eip1167Suffix) &&
+		bytes.HasPrefix(code, eip1167Prefix) && bytes.HasSuffix(code, eip1167Suffix) {
+		out.Type = proxyTypeEip1167
+		out.Implementation = common.BytesToAddress(code[len(eip1167Prefix) : len(eip1167Prefix)+common.AddressLength]).Hex()
+		return out, nil
+	}
+
+	readAddress := func(slot common.Hash) (common.Address, error) {
+		value, err := storageAt(rpcClient, address, slot)
+		return common.BytesToAddress(value.Bytes()), err
+	}
+
+	implementation, err := readAddress(eip1967ImplementationSlot)
+	if err != nil {
+		return nil, err
+	}
+	beacon, err := readAddress(eip1967BeaconSlot)
+	if err != nil {
+		return nil, err
+	}
+	if implementation != (common.Address{}) || beacon != (common.Address{}) {
+		admin, err := readAddress(eip1967AdminSlot)
+		if err != nil {
+			return nil, err
+		}
+		if admin != (common.Address{}) {
+			out.Admin = admin.Hex()
+		}
+
+		if implementation != (common.Address{}) {
+			out.Type = proxyTypeEip1967
+			out.Implementation = implementation.Hex()
+			return out, nil
+		}
+
+		// the implementation of beacon proxy is returned by implementation() of beacon
+		output, err := CallAt(rpcClient, beacon, common.FromHex("0x5c60da1b"), blockArg())
+		if err != nil {
+			return nil, fmt.Errorf("call implementation() of beacon %s fail: %w", beacon.Hex(), err)
+		}
+		out.Type = proxyTypeEip1967Beacon
+		out.Beacon = beacon.Hex()
+		out.Implementation = common.BytesToAddress(output).Hex()
+		return out, nil
+	}
+
+	implementation, err = readAddress(ozLegacyImplementationSlot)
+	if err != nil {
+		return nil, err
+	}
+	if implementation != (common.Address{}) {
+		out.Type = proxyTypeOzLegacy
+		out.Implementation = implementation.Hex()
+		admin, err := readAddress(ozLegacyAdminSlot)
+		if err != nil {
+			return nil, err
+		}
+		if admin != (common.Address{}) {
+			out.Admin = admin.Hex()
+		}
+	}
+	return out, nil
+}
+
+// fetchContractABI downloads abi of verified contract from block explorer
+func fetchContractABI(contractAddress string) ([]byte, error) {
+	requestUrl, err := explorerApiUrl(url.Values{
+		"module":  {"contract"},
+		"action":  {"getabi"},
+		"address": {contractAddress},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := http.Get(requestUrl)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	var data struct {
+		Status  string `json:"status"`
+		Message string `json:"message"`
+		Result  string `json:"result"` // the abi in json string, or error message
+	}
+	if err := json.Unmarshal(body, &data); err != nil {
+		return nil, err
+	}
+	if data.Status != "1" {
+		return nil, fmt.Errorf("get abi of %s from block explorer fail: %s %s", contractAddress, data.Message, data.Result)
+	}
+	return []byte(data.Result), nil
+}
+
+// followProxyFuncDefinition finds the function in abi of the implementation if contract is a proxy, the abi is
+// downloaded from block explorer. It's used when the function is not found in --abi-file (e.g. abi of proxy).
+func followProxyFuncDefinition(rpcClient *rpc.Client, contract common.Address, funcName string) (string, []byte, error) {
+	proxy, err := detectProxy(rpcClient, contract)
+	if err != nil {
+		return "", nil, err
+	}
+	if proxy.Type == "" {
+		return "", nil, fmt.Errorf("%s is not a known proxy", contract.Hex())
+	}
+
+	log.Printf("%s is a %s proxy, find %s in abi of implementation %s", contract.Hex(), proxy.Type, funcName, proxy.Implementation)
+	abiContent, err := fetchContractABI(proxy.Implementation)
+	if err != nil {
+		return "", nil, err
+	}
+	funcDefinition, err := extractFuncDefinition(string(abiContent), funcName)
+	if err != nil {
+		return "", nil, err
+	}
+	return funcDefinition, abiContent, nil
+}
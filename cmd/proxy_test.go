@@ -0,0 +1,111 @@
+package cmd
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strconv"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+)
+
+// stubProxyEth is a stub of eth namespace, it serves the code and storage of contracts, and implementation() of beacon
+type stubProxyEth struct {
+	code    map[common.Address][]byte
+	storage map[common.Address]map[common.Hash]common.Hash
+	beacon  common.Address
+	impl    common.Address
+}
+
+func (s *stubProxyEth) GetCode(addr common.Address, block string) (hexutil.Bytes, error) {
+	return s.code[addr], nil
+}
+
+func (s *stubProxyEth) GetStorageAt(addr common.Address, slot common.Hash, block string) (hexutil.Bytes, error) {
+	value := s.storage[addr][slot]
+	return value.Bytes(), nil
+}
+
+func (s *stubProxyEth) Call(args map[string]interface{}, block string) (hexutil.Bytes, error) {
+	if common.HexToAddress(args["to"].(string)) == s.beacon && args["data"] == "0x5c60da1b" {
+		return common.LeftPadBytes(s.impl.Bytes(), 32), nil
+	}
+	return nil, &stubRevertError{data: "0x"}
+}
+
+func TestDetectProxy(t *testing.T) {
+	impl := common.HexToAddress("0x43506849D7C04F9138D1A2050bbF3A0c054402dd")
+	admin := common.HexToAddress("0x807a96288A1A408dBC13DE2b1d087d10356395d2")
+	beacon := common.HexToAddress("0x5a2a4F2F3C18f09179B6703e63D9eDD165909073")
+
+	minimal := common.HexToAddress("0x0000000000000000000000000000000000000001")
+	transparent := common.HexToAddress("0x0000000000000000000000000000000000000002")
+	beaconProxy := common.HexToAddress("0x0000000000000000000000000000000000000003")
+	legacy := common.HexToAddress("0x0000000000000000000000000000000000000004")
+	notProxy := common.HexToAddress("0x0000000000000000000000000000000000000005")
+
+	var minimalCode []byte
+	minimalCode = append(minimalCode, eip1167Prefix...)
+	minimalCode = append(minimalCode, impl.Bytes()...)
+	minimalCode = append(minimalCode, eip1167Suffix...)
+
+	stub := &stubProxyEth{
+		code: map[common.Address][]byte{minimal: minimalCode},
+		storage: map[common.Address]map[common.Hash]common.Hash{
+			transparent: {
+				eip1967ImplementationSlot: common.BytesToHash(impl.Bytes()),
+				eip1967AdminSlot:          common.BytesToHash(admin.Bytes()),
+			},
+			beaconProxy: {eip1967BeaconSlot: common.BytesToHash(beacon.Bytes())},
+			legacy:      {ozLegacyImplementationSlot: common.BytesToHash(impl.Bytes())},
+		},
+		beacon: beacon,
+		impl:   impl,
+	}
+	rpcClient := dialStubRpc(t, map[string]any{"eth": stub})
+
+	tests := []struct {
+		address  common.Address
+		expected ProxyOutput
+	}{
+		{minimal, ProxyOutput{Address: minimal.Hex(), Type: proxyTypeEip1167, Implementation: impl.Hex()}},
+		{transparent, ProxyOutput{Address: transparent.Hex(), Type: proxyTypeEip1967, Implementation: impl.Hex(), Admin: admin.Hex()}},
+		{beaconProxy, ProxyOutput{Address: beaconProxy.Hex(), Type: proxyTypeEip1967Beacon, Implementation: impl.Hex(), Beacon: beacon.Hex()}},
+		{legacy, ProxyOutput{Address: legacy.Hex(), Type: proxyTypeOzLegacy, Implementation: impl.Hex()}},
+		{notProxy, ProxyOutput{Address: notProxy.Hex()}},
+	}
+
+	for i, tc := range tests {
+		got, err := detectProxy(rpcClient, tc.address)
+		if err != nil || !reflect.DeepEqual(*got, tc.expected) {
+			t.Fatalf("test %d: expected: %+v, got: %+v (%v)", i+1, tc.expected, got, err)
+		}
+	}
+}
+
+func TestFetchContractABI(t *testing.T) {
+	const abiContent = `[{"type":"function","name":"owner","inputs":[],"outputs":[{"name":"","type":"address"}],"stateMutability":"view"}]`
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		query := r.URL.Query()
+		if query.Get("action") != "getabi" || query.Get("address") != "0x43506849D7C04F9138D1A2050bbF3A0c054402dd" {
+			_, _ = w.Write([]byte(`{"status":"0","message":"NOTOK","result":"Contract source code not verified"}`))
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"1","message":"OK","result":` + strconv.Quote(abiContent) + `}`))
+	}))
+	defer server.Close()
+
+	savedProfile := globalChainProfile
+	defer func() { globalChainProfile = savedProfile }()
+	globalChainProfile = &ChainProfile{ApiUrl: server.URL}
+
+	got, err := fetchContractABI("0x43506849D7C04F9138D1A2050bbF3A0c054402dd")
+	if err != nil || string(got) != abiContent {
+		t.Fatalf("expected: %s, got: %s (%v)", abiContent, got, err)
+	}
+	if _, err := fetchContractABI("0x807a96288A1A408dBC13DE2b1d087d10356395d2"); err == nil {
+		t.Fatalf("expect error if contract is not verified")
+	}
+}
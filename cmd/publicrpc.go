@@ -2,14 +2,9 @@ package cmd
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
-	"io"
 	"log"
 	"math/big"
-	"net/http"
-	"os"
-	"strconv"
 	"strings"
 	"time"
 
@@ -17,20 +12,6 @@ import (
 	"github.com/spf13/cobra"
 )
 
-type ChainData struct {
-	Name      string        `json:"name"`
-	Chain     string        `json:"chain"`
-	ChainId   uint64        `json:"chainId"`
-	Rpc       []RpcEndpoint `json:"rpc"`
-	ChainSlug string        `json:"chainSlug"`
-}
-
-type RpcEndpoint struct {
-	Url          string `json:"url"`
-	Tracking     string `json:"tracking"`
-	IsOpenSource bool   `json:"isOpenSource,omitempty"`
-}
-
 type RpcStatus struct {
 	Url           string
 	BlockHeight   *big.Int
@@ -40,67 +21,24 @@ type RpcStatus struct {
 }
 
 var publicRpcCmd = &cobra.Command{
-	Use:   "public-rpc <chain-id>",
+	Use:   "public-rpc <chain>",
 	Short: "Show public RPC endpoints for a chain",
-	Args: func(cmd *cobra.Command, args []string) error {
-		if len(args) != 1 {
-			return fmt.Errorf("requires exactly one chain ID argument")
-		}
-
-		if _, err := strconv.ParseUint(args[0], 10, 64); err != nil {
-			return fmt.Errorf("chain ID must be a valid number: %v", args[0])
-		}
-
-		return nil
-	},
+	Long:  "Show public RPC endpoints for a chain, chain can be chain id or short name in the chain registry (see chains command)",
+	Args:  cobra.ExactArgs(1),
 	Run: func(cmd *cobra.Command, args []string) {
-		chainId := args[0]
-
-		log.Printf("Fetching RPC data for chain ID %s", chainId)
-
-		// Fetch chain data from chainlist.org
-		resp, err := http.Get("https://chainlist.org/rpcs.json")
-		if err != nil {
-			log.Printf("Failed to fetch RPC data: %v", err)
-			os.Exit(1)
-		}
-		defer resp.Body.Close()
-
-		body, err := io.ReadAll(resp.Body)
-		if err != nil {
-			log.Printf("Failed to read response body: %v", err)
-			os.Exit(1)
-		}
-
-		var chains []ChainData
-		err = json.Unmarshal(body, &chains)
-		if err != nil {
-			log.Printf("Failed to parse JSON: %v", err)
-			os.Exit(1)
-		}
-
-		// Find the chain with matching chain ID
-		var targetChain *ChainData
-		for i, chain := range chains {
-			if strconv.FormatUint(chain.ChainId, 10) == chainId {
-				targetChain = &chains[i]
-				break
-			}
-		}
-
+		targetChain := lookupChain(loadChainRegistry(), args[0])
 		if targetChain == nil {
-			log.Printf("Chain ID %s not found", chainId)
-			os.Exit(1)
+			log.Fatalf("Chain %s not found in chain registry, try `ethutil chains refresh`", args[0])
 		}
 
 		log.Printf("Found chain: %s (%s)", targetChain.Name, targetChain.Chain)
 
 		if len(targetChain.Rpc) == 0 {
-			fmt.Printf("No RPC endpoints found for chain %s\n", chainId)
+			fmt.Printf("No RPC endpoints found for chain %s\n", args[0])
 			return
 		}
 
-		fmt.Printf("Chain: %s (ID: %s)\n", targetChain.Name, chainId)
+		fmt.Printf("Chain: %s (ID: %d)\n", targetChain.Name, targetChain.ChainId)
 		fmt.Printf("RPC Endpoints:\n\n")
 
 		// Test each RPC endpoint and get block height
@@ -2,6 +2,7 @@ package cmd
 
 import (
 	"encoding/hex"
+	"errors"
 	"fmt"
 	"log"
 	"os"
@@ -18,8 +19,9 @@ var queryCmdABIFile string
 var queryHexData string
 
 func init() {
-	queryCmd.Flags().StringVarP(&queryCmdABIFile, "abi-file", "", "", "the path of abi file, if this option specified, 'function definition' can be just function name")
+	queryCmd.Flags().StringVarP(&queryCmdABIFile, "abi-file", "", "", "the path of abi file, if this option specified, 'function definition' can be just function name. If the function is not found and the contract is a proxy, the abi of implementation is downloaded from block explorer")
 	queryCmd.Flags().StringVarP(&queryHexData, "hex-data", "", "", "the input hex data")
+	addOverrideFlags(queryCmd)
 }
 
 var queryCmd = &cobra.Command{
@@ -36,14 +38,15 @@ var queryCmd = &cobra.Command{
 			_ = cmd.Help()
 			os.Exit(1)
 		}
+		checkErr(parseOverrides())
 
 		InitGlobalClient(globalOptNodeUrl)
 
 		contractAddr := args[0]
 
-		if !globalOptDryRun {
-			// don't check contract address if --dry-run specified
-			isContract, err := isContractAddress(globalClient.EthClient, common.HexToAddress(contractAddr))
+		if !globalOptDryRun && !hasOverrides() {
+			// don't check contract address if --dry-run specified, or the code may be overridden
+			isContract, err := isContractAddressAt(globalClient.EthClient, common.HexToAddress(contractAddr), blockArg())
 			if err != nil {
 				panic(err)
 			}
@@ -59,9 +62,19 @@ var queryCmd = &cobra.Command{
 			}
 			txInputData, err := hex.DecodeString(queryHexData)
 			checkErr(err)
-			output, err := Call(globalClient.RpcClient, common.HexToAddress(contractAddr), txInputData)
+			if queryCmdABIFile != "" {
+				abiContent, err := os.ReadFile(queryCmdABIFile)
+				checkErr(err)
+				setRevertErrorsABI(abiContent)
+			}
+			output, err := CallAt(globalClient.RpcClient, common.HexToAddress(contractAddr), txInputData, blockArg())
 			checkErr(err)
 
+			if isJsonOutput() {
+				printJson(buildQueryOutput(nil, output))
+				return
+			}
+
 			log.Printf("Output raw data\n%v\n", hex.EncodeToString(output))
 			// Pretty print output raw data
 			num := len(output) / 32
@@ -80,8 +93,18 @@ var queryCmd = &cobra.Command{
 			if err != nil {
 				log.Fatal(err)
 			}
+			setRevertErrorsABI(abiContent)
 			funcName := funcSignature
 			funcSignature, err = extractFuncDefinition(string(abiContent), extractFuncName(funcName))
+			var notFound *ErrFuncNotFound
+			if errors.As(err, &notFound) {
+				// --abi-file may be the abi of a proxy, follow it to the implementation
+				var implABIContent []byte
+				funcSignature, implABIContent, err = followProxyFuncDefinition(globalClient.RpcClient, common.HexToAddress(contractAddr), extractFuncName(funcName))
+				if err == nil {
+					setRevertErrorsABI(implABIContent)
+				}
+			}
 			checkErr(err)
 			// log.Printf("extract func definition from abi: %v", funcDefinition)
 		}
@@ -93,32 +116,82 @@ var queryCmd = &cobra.Command{
 			log.Printf("input data = %v", hexutil.Encode(txInputData))
 		}
 
-		output, err := Call(globalClient.RpcClient, common.HexToAddress(contractAddr), txInputData)
+		output, err := CallAt(globalClient.RpcClient, common.HexToAddress(contractAddr), txInputData, blockArg())
 		checkErr(err)
 
 		printContractReturnData(funcSignature, output)
 	},
 }
 
+// QueryOutput is the json output (--output json) of query command and read-only erc20 functions
+type QueryOutput struct {
+	RawOutput string        `json:"rawOutput"`
+	Words     []string      `json:"words"`             // raw output split into 32 bytes words
+	Returns   []ReturnValue `json:"returns,omitempty"` // only present when return types are known
+}
+
+// ReturnValue is a decoded return value, Value is string for address, integer and bytes types
+type ReturnValue struct {
+	Name  string `json:"name"`
+	Type  string `json:"type"`
+	Value any    `json:"value"`
+}
+
+// buildQueryOutput builds QueryOutput, returns are decoded if returnArgs is not empty
+func buildQueryOutput(returnArgs abi.Arguments, output []byte) *QueryOutput {
+	var rc = &QueryOutput{
+		RawOutput: hexutil.Encode(output),
+		Words:     []string{},
+	}
+	for i := 0; i+32 <= len(output); i += 32 {
+		rc.Words = append(rc.Words, hexutil.Encode(output[i:i+32]))
+	}
+	if len(returnArgs) == 0 {
+		return rc
+	}
+
+	values, err := returnArgs.Unpack(output)
+	checkErr(err)
+	for i, returnArg := range returnArgs {
+		rc.Returns = append(rc.Returns, ReturnValue{
+			Name:  returnArg.Name,
+			Type:  returnArg.Type.String(),
+			Value: normalizeDecodedValue(values[i]),
+		})
+	}
+	return rc
+}
+
 func printContractReturnData(funcDefinition string, output []byte) {
-	var v = make(map[string]interface{})
 	returnArgs, err := buildReturnArgs(funcDefinition)
 	checkErr(err)
 
+	if isJsonOutput() {
+		printJson(buildQueryOutput(returnArgs, output))
+		return
+	}
+
 	log.Printf("Output raw data\n%v\n", hex.EncodeToString(output))
 	// Pretty print output raw data
 	num := len(output) / 32
 	for i := 0; i <= num-1; i++ {
 		fmt.Printf("[%d]:  %v\n", i, hexutil.Encode(output[32*i:32*(i+1)]))
 	}
+	checkErr(printReturnValues(returnArgs, output))
+}
+
+// printReturnValues prints the decoded return values as `name = value`, nothing is printed if returnArgs is empty
+func printReturnValues(returnArgs abi.Arguments, output []byte) error {
 	if len(returnArgs) == 0 {
 		// Return if type of function not specified
-		return
+		return nil
 	}
 
 	// Unpack hex data into v
-	err = returnArgs.UnpackIntoMap(v, output)
-	checkErr(err)
+	var v = make(map[string]interface{})
+	if err := returnArgs.UnpackIntoMap(v, output); err != nil {
+		return err
+	}
 
 	for _, returnArg := range returnArgs {
 		// fmt.Printf("type of v: %v\n", reflect.TypeOf(v[returnArg.Name]))
@@ -135,7 +208,7 @@ func printContractReturnData(funcDefinition string, output []byte) {
 						fmt.Printf(" ") // separator
 					}
 				}
-				fmt.Printf("]")
+				fmt.Printf("]\n")
 			} else {
 				fmt.Printf("%v = %v\n", returnArg.Name, v[returnArg.Name])
 			}
@@ -145,7 +218,7 @@ func printContractReturnData(funcDefinition string, output []byte) {
 	}
 
 	// fmt.Printf("raw output:\n%s\n", hex.Dump(output))
-	return
+	return nil
 }
 
 func validationQueryCmdOpts(args []string) bool {
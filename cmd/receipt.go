@@ -0,0 +1,448 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"os"
+	"strings"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+)
+
+var receiptCmdABIFile string
+
+func init() {
+	receiptCmd.Flags().StringVarP(&receiptCmdABIFile, "abi-file", "", "", "the path of abi file, its events are preferred when decoding logs, its errors are used to decode revert reason")
+}
+
+var receiptCmd = &cobra.Command{
+	Use:   "receipt <tx-hash>",
+	Short: "Show receipt of tx, event logs are decoded by --abi-file, known standard events or online signature lookup",
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("requires tx-hash")
+		}
+		if len(remove0xPrefix(args[0])) != 64 || !isValidHexString(args[0]) {
+			return fmt.Errorf("invalid tx-hash %s", args[0])
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		decoder := newEventDecoder(GetEventSig)
+		if receiptCmdABIFile != "" {
+			abiContent, err := os.ReadFile(receiptCmdABIFile)
+			checkErr(err)
+			checkErr(decoder.addABI(abiContent))
+			setRevertErrorsABI(abiContent)
+		}
+
+		InitGlobalClient(globalOptNodeUrl)
+		out, err := getReceiptOutput(globalClient.RpcClient, globalClient.EthClient, common.HexToHash(args[0]), decoder)
+		checkErr(err)
+
+		if isJsonOutput() {
+			printJson(out)
+			return
+		}
+		printReceipt(os.Stdout, out)
+	},
+}
+
+// ReceiptOutput is the json output (--output json) of receipt command, it's also attached to the
+// output of sent transaction if --show-receipt is specified
+type ReceiptOutput struct {
+	TxHash            string              `json:"txHash"`
+	Status            string              `json:"status"`                 // success | failed
+	RevertReason      string              `json:"revertReason,omitempty"` // only for failed tx, approximated by replaying it on the state of parent block
+	BlockNumber       string              `json:"blockNumber"`
+	From              string              `json:"from"`
+	To                string              `json:"to,omitempty"`              // empty means contract creation
+	ContractAddress   string              `json:"contractAddress,omitempty"` // only for contract creation
+	GasUsed           uint64              `json:"gasUsed"`
+	EffectiveGasPrice string              `json:"effectiveGasPrice,omitempty"` // in wei
+	Fee               string              `json:"fee,omitempty"`               // in wei, gasUsed * effectiveGasPrice
+	Logs              []*DecodedLogOutput `json:"logs"`
+}
+
+// DecodedLogOutput is an event log in receipt, Event and Params are empty if the log can not be decoded
+type DecodedLogOutput struct {
+	LogIndex  uint           `json:"logIndex"`
+	Address   string         `json:"address"`
+	Event     string         `json:"event,omitempty"`
+	SigSource string         `json:"sigSource,omitempty"` // event (--event of logs) | abi-file | known | online
+	Params    map[string]any `json:"params,omitempty"`
+	Topics    []string       `json:"topics"`
+	Data      string         `json:"data"`
+
+	argNames []string // names of Params in the order of event inputs
+}
+
+// getReceiptOutput gets the receipt of tx and decodes its logs, the failed tx is replayed to recover revert reason
+func getReceiptOutput(rpcClient *rpc.Client, client *ethclient.Client, txHash common.Hash, decoder *eventDecoder) (*ReceiptOutput, error) {
+	receipt, err := client.TransactionReceipt(context.Background(), txHash)
+	if err != nil {
+		return nil, fmt.Errorf("TransactionReceipt fail: %w", err)
+	}
+	tx, _, err := client.TransactionByHash(context.Background(), txHash)
+	if err != nil {
+		return nil, fmt.Errorf("TransactionByHash fail: %w", err)
+	}
+	from, err := client.TransactionSender(context.Background(), tx, receipt.BlockHash, receipt.TransactionIndex)
+	if err != nil {
+		return nil, fmt.Errorf("TransactionSender fail: %w", err)
+	}
+
+	out := buildReceiptOutput(receipt, from, tx.To(), decoder)
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		out.RevertReason = replayTxRevertReason(rpcClient, tx, from, receipt.BlockNumber)
+	}
+	return out, nil
+}
+
+// buildReceiptOutput builds output of receipt, the logs are decoded by decoder
+func buildReceiptOutput(receipt *types.Receipt, from common.Address, to *common.Address, decoder *eventDecoder) *ReceiptOutput {
+	var out = &ReceiptOutput{
+		TxHash:  receipt.TxHash.Hex(),
+		Status:  "success",
+		From:    from.Hex(),
+		GasUsed: receipt.GasUsed,
+		Logs:    []*DecodedLogOutput{},
+	}
+	if receipt.Status != types.ReceiptStatusSuccessful {
+		out.Status = "failed"
+	}
+	if receipt.BlockNumber != nil {
+		out.BlockNumber = receipt.BlockNumber.String()
+	}
+	if to != nil {
+		out.To = to.Hex()
+	} else if receipt.ContractAddress != (common.Address{}) {
+		out.ContractAddress = receipt.ContractAddress.Hex()
+	}
+	if receipt.EffectiveGasPrice != nil {
+		out.EffectiveGasPrice = receipt.EffectiveGasPrice.String()
+		out.Fee = new(big.Int).Mul(receipt.EffectiveGasPrice, new(big.Int).SetUint64(receipt.GasUsed)).String()
+	}
+
+	for _, l := range receipt.Logs {
+		out.Logs = append(out.Logs, decoder.decode(l))
+	}
+	return out
+}
+
+// printReceipt prints receipt in human-readable format
+func printReceipt(w io.Writer, out *ReceiptOutput) {
+	symbol := currentNativeCurrency().Symbol
+
+	fmt.Fprintf(w, "tx hash: %s\n", out.TxHash)
+	fmt.Fprintf(w, "status: %s\n", out.Status)
+	if out.RevertReason != "" {
+		fmt.Fprintf(w, "revert reason (replayed on the state of parent block, approximate): %s\n", out.RevertReason)
+	}
+	fmt.Fprintf(w, "block number: %s\n", out.BlockNumber)
+	fmt.Fprintf(w, "from: %s\n", out.From)
+	if out.To != "" {
+		fmt.Fprintf(w, "to: %s\n", out.To)
+	}
+	if out.ContractAddress != "" {
+		fmt.Fprintf(w, "contract address: %s\n", out.ContractAddress)
+	}
+	fmt.Fprintf(w, "gas used: %d\n", out.GasUsed)
+	if gasPrice, err := decimal.NewFromString(out.EffectiveGasPrice); err == nil {
+		fmt.Fprintf(w, "effective gas price: %s gwei\n", wei2Other(gasPrice, unitGwei).String())
+	}
+	if fee, err := decimal.NewFromString(out.Fee); err == nil {
+		fmt.Fprintf(w, "fee: %s %s (%s wei)\n", wei2Other(fee, unitEther).String(), symbol, out.Fee)
+	}
+
+	fmt.Fprintf(w, "logs: %d\n", len(out.Logs))
+	printDecodedLogs(w, out.Logs)
+}
+
+// printDecodedLogs prints the decoded event logs, the raw topics and data are printed if the log can not be decoded
+func printDecodedLogs(w io.Writer, logs []*DecodedLogOutput) {
+	for _, l := range logs {
+		fmt.Fprintf(w, "[%d] address: %s\n", l.LogIndex, l.Address)
+		if l.Event == "" {
+			fmt.Fprintf(w, "    event: unknown\n")
+			for i, topic := range l.Topics {
+				fmt.Fprintf(w, "    topic%d: %s\n", i, topic)
+			}
+			fmt.Fprintf(w, "    data: %s\n", l.Data)
+			continue
+		}
+		fmt.Fprintf(w, "    event: %s\n", l.Event)
+		for _, name := range l.argNames {
+			value, _ := json.Marshal(l.Params[name])
+			fmt.Fprintf(w, "      %s: %s\n", name, strings.Trim(string(value), `"`))
+		}
+	}
+}
+
+type eventSigLookup func(topic0 string) ([]string, error)
+
+// eventDecoder decodes event logs, the events specified by --event are tried first, then the events in abi file,
+// then the known events, then the signatures from online lookup
+type eventDecoder struct {
+	flagEvents    []abi.Event
+	flagEventSigs []string // the signatures without indexed info, the leading args are assumed indexed
+	abiFileEvents []abi.Event
+	lookupFn      eventSigLookup
+	lookupCache   map[common.Hash][]string
+}
+
+func newEventDecoder(lookupFn eventSigLookup) *eventDecoder {
+	return &eventDecoder{
+		lookupFn:    lookupFn,
+		lookupCache: make(map[common.Hash][]string),
+	}
+}
+
+// txEventDecoder decodes the logs of the tx sent by commands (--show-receipt and --simulate), the events of abi
+// (--abi-file) of the contract being called or deployed are added by setTxEventsABI
+var txEventDecoder = newEventDecoder(GetEventSig)
+
+// setTxEventsABI adds the events of abi to txEventDecoder, invalid abi is ignored
+func setTxEventsABI(abiContent []byte) {
+	if err := txEventDecoder.addABI(abiContent); err != nil {
+		log.Printf("warning: events in abi are not used to decode logs: %v", err)
+	}
+}
+
+// addABI adds events of abi (content of --abi-file) to decoder
+func (d *eventDecoder) addABI(abiContent []byte) error {
+	contractABI, err := parseContractABI(abiContent)
+	if err != nil {
+		return err
+	}
+	for _, event := range contractABI.Events {
+		d.abiFileEvents = append(d.abiFileEvents, event)
+	}
+	return nil
+}
+
+// addEvent adds event specified by --event to decoder, if indexedKnown is false, the leading args are assumed
+// indexed by the count of topics of each log
+func (d *eventDecoder) addEvent(event abi.Event, indexedKnown bool) {
+	if indexedKnown {
+		d.flagEvents = append(d.flagEvents, event)
+	} else {
+		d.flagEventSigs = append(d.flagEventSigs, event.Sig)
+	}
+}
+
+// decode decodes the event log, the raw topics and data are kept in output
+func (d *eventDecoder) decode(l *types.Log) *DecodedLogOutput {
+	var out = &DecodedLogOutput{
+		LogIndex: l.Index,
+		Address:  l.Address.Hex(),
+		Topics:   []string{},
+		Data:     hexutil.Encode(l.Data),
+	}
+	for _, topic := range l.Topics {
+		out.Topics = append(out.Topics, topic.Hex())
+	}
+	if len(l.Topics) == 0 {
+		// anonymous event, it can not be identified
+		return out
+	}
+
+	tryEvents := func(events []abi.Event, sigSource string) bool {
+		for _, event := range events {
+			if event.ID != l.Topics[0] {
+				continue
+			}
+			params, argNames, err := decodeLogWithEvent(event, l)
+			if err != nil {
+				continue
+			}
+			out.Event = event.Sig
+			out.SigSource = sigSource
+			out.Params = params
+			out.argNames = argNames
+			return true
+		}
+		return false
+	}
+
+	var flagEvents = append([]abi.Event{}, d.flagEvents...)
+	for _, sig := range d.flagEventSigs {
+		if event, err := buildEventFromSig(sig, len(l.Topics)-1); err == nil {
+			flagEvents = append(flagEvents, event)
+		}
+	}
+	if tryEvents(flagEvents, "event") || tryEvents(d.abiFileEvents, "abi-file") || tryEvents(knownEvents(), "known") {
+		return out
+	}
+
+	// The signature from online lookup doesn't tell which args are indexed, assume the leading ones
+	var onlineEvents []abi.Event
+	for _, sig := range d.lookupEventSigs(l.Topics[0]) {
+		if event, err := buildEventFromSig(sig, len(l.Topics)-1); err == nil {
+			onlineEvents = append(onlineEvents, event)
+		}
+	}
+	tryEvents(onlineEvents, "online")
+	return out
+}
+
+// lookupEventSigs returns the signatures of topic0 from online lookup, the result is cached
+func (d *eventDecoder) lookupEventSigs(topic0 common.Hash) []string {
+	if d.lookupFn == nil {
+		return nil
+	}
+	if sigs, ok := d.lookupCache[topic0]; ok {
+		return sigs
+	}
+	sigs, err := d.lookupFn(topic0.Hex())
+	if err != nil {
+		log.Printf("warning: lookup event signature of %s failed: %v", topic0.Hex(), err)
+	}
+	d.lookupCache[topic0] = sigs
+	return sigs
+}
+
+// buildEventFromSig builds event from signature like `Transfer(address,address,uint256)`, the leading
+// indexedCount args are marked as indexed
+func buildEventFromSig(sig string, indexedCount int) (abi.Event, error) {
+	name, argTypes, err := parseFuncSignature(sig)
+	if err != nil {
+		return abi.Event{}, err
+	}
+	if indexedCount > len(argTypes) {
+		return abi.Event{}, fmt.Errorf("event %s has %d args, less than %d indexed topics", sig, len(argTypes), indexedCount)
+	}
+	inputs, err := buildInputArgs(argTypes)
+	if err != nil {
+		return abi.Event{}, err
+	}
+	for i := range inputs {
+		inputs[i].Indexed = i < indexedCount
+	}
+	return abi.NewEvent(name, name, false, inputs), nil
+}
+
+// decodeLogWithEvent decodes the log with event, returns the decoded params and their names in order of event inputs
+func decodeLogWithEvent(event abi.Event, l *types.Log) (map[string]any, []string, error) {
+	argNames := buildArgNames(event.Inputs)
+
+	var indexed, nonIndexed abi.Arguments
+	for i, input := range event.Inputs {
+		input.Name = argNames[i]
+		if input.Indexed {
+			indexed = append(indexed, input)
+		} else {
+			nonIndexed = append(nonIndexed, input)
+		}
+	}
+	if len(indexed) != len(l.Topics)-1 {
+		return nil, nil, fmt.Errorf("event %s has %d indexed args, but log has %d topics", event.Sig, len(indexed), len(l.Topics))
+	}
+
+	values, err := nonIndexed.UnpackValues(l.Data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("decode log data failed: %w", err)
+	}
+	if !isExactABIDecode(nonIndexed, values, l.Data) {
+		return nil, nil, fmt.Errorf("decode log data failed: data does not match event inputs exactly")
+	}
+
+	topicValues := make(map[string]any)
+	if err := abi.ParseTopicsIntoMap(topicValues, indexed, l.Topics[1:]); err != nil {
+		return nil, nil, fmt.Errorf("decode log topics failed: %w", err)
+	}
+
+	params := make(map[string]any, len(event.Inputs))
+	for i, value := range values {
+		params[nonIndexed[i].Name] = normalizeDecodedValue(value)
+	}
+	for name, value := range topicValues {
+		params[name] = normalizeDecodedValue(value)
+	}
+	return params, argNames, nil
+}
+
+// knownEventDecls are events of common standards, they are used to decode logs without network.
+// The ERC20 and ERC721 Transfer/Approval share topic0, they are distinguished by count of indexed args.
+var knownEventDecls = []string{
+	// ERC20
+	"Transfer(address indexed from, address indexed to, uint256 value)",
+	"Approval(address indexed owner, address indexed spender, uint256 value)",
+	// ERC721
+	"Transfer(address indexed from, address indexed to, uint256 indexed tokenId)",
+	"Approval(address indexed owner, address indexed approved, uint256 indexed tokenId)",
+	"ApprovalForAll(address indexed owner, address indexed operator, bool approved)",
+	// ERC1155
+	"TransferSingle(address indexed operator, address indexed from, address indexed to, uint256 id, uint256 value)",
+	"TransferBatch(address indexed operator, address indexed from, address indexed to, uint256[] ids, uint256[] values)",
+	"URI(string value, uint256 indexed id)",
+	// ERC4337 EntryPoint (v0.6 and v0.7)
+	"UserOperationEvent(bytes32 indexed userOpHash, address indexed sender, address indexed paymaster, uint256 nonce, bool success, uint256 actualGasCost, uint256 actualGasUsed)",
+	"AccountDeployed(bytes32 indexed userOpHash, address indexed sender, address factory, address paymaster)",
+	"UserOperationRevertReason(bytes32 indexed userOpHash, address indexed sender, uint256 nonce, bytes revertReason)",
+	"PostOpRevertReason(bytes32 indexed userOpHash, address indexed sender, uint256 nonce, bytes revertReason)",
+	"BeforeExecution()",
+	"SignatureAggregatorChanged(address indexed aggregator)",
+	"Deposited(address indexed account, uint256 totalDeposit)",
+	"Withdrawn(address indexed account, address withdrawAddress, uint256 amount)",
+	"StakeLocked(address indexed account, uint256 totalStaked, uint256 unstakeDelaySec)",
+	"StakeUnlocked(address indexed account, uint256 withdrawTime)",
+	"StakeWithdrawn(address indexed account, address withdrawAddress, uint256 amount)",
+}
+
+var knownEventList []abi.Event
+var knownEventOnce sync.Once
+
+// knownEvents returns the parsed knownEventDecls
+func knownEvents() []abi.Event {
+	knownEventOnce.Do(func() {
+		for _, decl := range knownEventDecls {
+			event, err := parseEventDecl(decl)
+			checkErr(err)
+			knownEventList = append(knownEventList, event)
+		}
+	})
+	return knownEventList
+}
+
+// parseEventDecl parses event declaration like `Transfer(address indexed from, address indexed to, uint256 value)`
+func parseEventDecl(decl string) (abi.Event, error) {
+	leftParenthesisLoc := strings.Index(decl, "(")
+	if leftParenthesisLoc < 0 || !strings.HasSuffix(decl, ")") {
+		return abi.Event{}, fmt.Errorf("invalid event declaration %s", decl)
+	}
+	name := strings.TrimSpace(decl[:leftParenthesisLoc])
+
+	var inputs abi.Arguments
+	if argsPart := decl[leftParenthesisLoc+1 : len(decl)-1]; strings.TrimSpace(argsPart) != "" {
+		for _, arg := range splitTopLevel(argsPart) {
+			fields := strings.Fields(arg)
+			args, err := buildInputArgs(fields[:1])
+			if err != nil {
+				return abi.Event{}, fmt.Errorf("invalid event declaration %s: %w", decl, err)
+			}
+			input := args[0]
+			for _, field := range fields[1:] {
+				if field == "indexed" {
+					input.Indexed = true
+				} else {
+					input.Name = field
+				}
+			}
+			inputs = append(inputs, input)
+		}
+	}
+	return abi.NewEvent(name, name, false, inputs), nil
+}
@@ -0,0 +1,159 @@
+package cmd
+
+import (
+	"bytes"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestDecodeReceiptLogs(t *testing.T) {
+	from := common.HexToAddress("0x8F36975cdeA2e6E64f85719788C8EFBBe89DFBbb")
+	to := common.HexToAddress("0xB2aC853cF815B47903bc19BF4860540306F4f944")
+	token := common.HexToAddress("0xdAC17F958D2ee523a2206206994597C13D831ec7")
+	transferTopic := crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+	fooTopic := crypto.Keccak256Hash([]byte("Foo(address,uint256)"))
+	storedTopic := crypto.Keccak256Hash([]byte("Stored(uint256,string)"))
+
+	abiContent := []byte(`[{"type":"event","name":"Stored","anonymous":false,"inputs":[{"name":"id","type":"uint256","indexed":true},{"name":"note","type":"string","indexed":false}]}]`)
+	storedData, _ := hexutil.Decode("0x0000000000000000000000000000000000000000000000000000000000000020" +
+		"0000000000000000000000000000000000000000000000000000000000000002" +
+		"6869000000000000000000000000000000000000000000000000000000000000") // "hi"
+
+	var lookupCount int
+	decoder := newEventDecoder(func(topic0 string) ([]string, error) {
+		lookupCount++
+		if topic0 == fooTopic.Hex() {
+			return []string{"Foo(address,uint256)"}, nil
+		}
+		return nil, nil
+	})
+	if err := decoder.addABI(abiContent); err != nil {
+		t.Fatalf("addABI failed: %v", err)
+	}
+
+	tests := []struct {
+		log            *types.Log
+		expectedEvent  string
+		expectedSource string
+		expectedParams map[string]any
+	}{
+		{ // ERC20 Transfer
+			&types.Log{Address: token, Topics: []common.Hash{transferTopic, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes())}, Data: common.LeftPadBytes(big.NewInt(1000000).Bytes(), 32)},
+			"Transfer(address,address,uint256)", "known",
+			map[string]any{"from": from.Hex(), "to": to.Hex(), "value": "1000000"},
+		},
+		{ // ERC721 Transfer, same topic0 as ERC20 Transfer
+			&types.Log{Address: token, Topics: []common.Hash{transferTopic, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes()), common.BigToHash(big.NewInt(42))}},
+			"Transfer(address,address,uint256)", "known",
+			map[string]any{"from": from.Hex(), "to": to.Hex(), "tokenId": "42"},
+		},
+		{ // online lookup, the leading args are indexed
+			&types.Log{Address: token, Topics: []common.Hash{fooTopic, common.BytesToHash(from.Bytes())}, Data: common.LeftPadBytes(big.NewInt(7).Bytes(), 32)},
+			"Foo(address,uint256)", "online",
+			map[string]any{"arg0": from.Hex(), "arg1": "7"},
+		},
+		{ // abi file
+			&types.Log{Address: token, Topics: []common.Hash{storedTopic, common.BigToHash(big.NewInt(3))}, Data: storedData},
+			"Stored(uint256,string)", "abi-file",
+			map[string]any{"id": "3", "note": "hi"},
+		},
+		{ // unknown event
+			&types.Log{Address: token, Topics: []common.Hash{crypto.Keccak256Hash([]byte("Bar()"))}},
+			"", "", nil,
+		},
+		{ // anonymous event
+			&types.Log{Address: token, Data: []byte{1}},
+			"", "", nil,
+		},
+		{ // data doesn't match known event
+			&types.Log{Address: token, Topics: []common.Hash{transferTopic, common.BytesToHash(from.Bytes()), common.BytesToHash(to.Bytes())}},
+			"", "", nil,
+		},
+	}
+
+	for i, tc := range tests {
+		got := decoder.decode(tc.log)
+		if got.Event != tc.expectedEvent || got.SigSource != tc.expectedSource {
+			t.Fatalf("test %d: expected: %v (%v), got: %v (%v)", i+1, tc.expectedEvent, tc.expectedSource, got.Event, got.SigSource)
+		}
+		if !reflect.DeepEqual(got.Params, tc.expectedParams) {
+			t.Fatalf("test %d: expected: %v, got: %v", i+1, tc.expectedParams, got.Params)
+		}
+		if len(got.Topics) != len(tc.log.Topics) || got.Data != hexutil.Encode(tc.log.Data) {
+			t.Fatalf("test %d: expected raw topics and data are kept, got: %v %v", i+1, got.Topics, got.Data)
+		}
+	}
+
+	// Transfer is decoded as known event, so topic0 of it is never looked up; Transfer with empty data is looked up once
+	if lookupCount != 3 {
+		t.Fatalf("expected: %v lookups, got: %v", 3, lookupCount)
+	}
+}
+
+func TestSetTxEventsABI(t *testing.T) {
+	defer func(decoder *eventDecoder) { txEventDecoder = decoder }(txEventDecoder)
+	txEventDecoder = newEventDecoder(nil)
+
+	storedLog := &types.Log{
+		Address: common.HexToAddress("0xdAC17F958D2ee523a2206206994597C13D831ec7"),
+		Topics:  []common.Hash{crypto.Keccak256Hash([]byte("Stored(uint256,uint256)")), common.BigToHash(big.NewInt(3))},
+		Data:    common.LeftPadBytes(big.NewInt(7).Bytes(), 32),
+	}
+	if got := txEventDecoder.decode(storedLog); got.Event != "" {
+		t.Fatalf("expected: undecoded log, got: %v", got.Event)
+	}
+
+	setTxEventsABI([]byte("invalid abi")) // ignored
+	setTxEventsABI([]byte(`[{"type":"event","name":"Stored","anonymous":false,"inputs":[{"name":"id","type":"uint256","indexed":true},{"name":"value","type":"uint256","indexed":false}]}]`))
+	got := txEventDecoder.decode(storedLog)
+	if got.Event != "Stored(uint256,uint256)" || got.SigSource != "abi-file" || !reflect.DeepEqual(got.Params, map[string]any{"id": "3", "value": "7"}) {
+		t.Fatalf("expected: Stored(uint256,uint256) from abi-file, got: %v (%v) %v", got.Event, got.SigSource, got.Params)
+	}
+}
+
+func TestBuildReceiptOutput(t *testing.T) {
+	from := common.HexToAddress("0x8F36975cdeA2e6E64f85719788C8EFBBe89DFBbb")
+	to := common.HexToAddress("0xdAC17F958D2ee523a2206206994597C13D831ec7")
+	receipt := &types.Receipt{
+		Status:            types.ReceiptStatusSuccessful,
+		TxHash:            common.HexToHash("0x01"),
+		BlockNumber:       big.NewInt(100),
+		GasUsed:           50000,
+		EffectiveGasPrice: big.NewInt(3e9),
+		Logs: []*types.Log{{
+			Address: to,
+			Topics: []common.Hash{
+				crypto.Keccak256Hash([]byte("Approval(address,address,uint256)")),
+				common.BytesToHash(from.Bytes()),
+				common.BytesToHash(to.Bytes()),
+			},
+			Data: common.LeftPadBytes(big.NewInt(5).Bytes(), 32),
+		}},
+	}
+
+	out := buildReceiptOutput(receipt, from, &to, newEventDecoder(nil))
+	if out.Status != "success" || out.Fee != "150000000000000" || out.EffectiveGasPrice != "3000000000" || out.ContractAddress != "" {
+		t.Fatalf("expected: success 150000000000000 3000000000, got: %+v", out)
+	}
+
+	var buf bytes.Buffer
+	printReceipt(&buf, out)
+	summary := buf.String()
+	for i, expected := range []string{
+		"gas used: 50000\n",
+		"effective gas price: 3 gwei\n",
+		"fee: 0.00015 ETH (150000000000000 wei)\n",
+		"    event: Approval(address,address,uint256)\n      owner: " + from.Hex() + "\n      spender: " + to.Hex() + "\n      value: 5\n",
+	} {
+		if !strings.Contains(summary, expected) {
+			t.Fatalf("test %d: expected: %q in summary, got: %s", i+1, expected, summary)
+		}
+	}
+}
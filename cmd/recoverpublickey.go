@@ -11,6 +11,12 @@ import (
 	"github.com/spf13/cobra"
 )
 
+// RecoveredKeyOutput is the json output (--output json) of recover-public-key command
+type RecoveredKeyOutput struct {
+	PublicKey string `json:"publicKey"` // uncompressed
+	Address   string `json:"address"`
+}
+
 // recoverPublicKeyCmd represents the recover-public-key command
 var recoverPublicKeyCmd = &cobra.Command{
 	Use:   "recover-public-key <message-hash> <signature(RSV)>",
@@ -63,6 +69,10 @@ var recoverPublicKeyCmd = &cobra.Command{
 		addr := crypto.PubkeyToAddress(*pubkey)
 
 		// Output
+		if isJsonOutput() {
+			printJson(RecoveredKeyOutput{PublicKey: hexutil.Encode(pubkeyBytes), Address: addr.String()})
+			return
+		}
 		fmt.Printf("uncompressed public key (hex) = %s\n", hexutil.Encode(pubkeyBytes))
 		fmt.Printf("address = %s\n", addr.String())
 	},
@@ -0,0 +1,212 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+var (
+	errorStringSelector = crypto.Keccak256([]byte("Error(string)"))[:4]
+	panicSelector       = crypto.Keccak256([]byte("Panic(uint256)"))[:4]
+)
+
+// panicReasons are the reasons of solidity panic codes
+// See https://docs.soliditylang.org/en/latest/control-structures.html#panic-via-assert-and-error-via-require
+var panicReasons = map[uint64]string{
+	0x00: "generic compiler inserted panic",
+	0x01: "assertion failed",
+	0x11: "arithmetic underflow or overflow",
+	0x12: "division or modulo by zero",
+	0x21: "enum conversion out of range",
+	0x22: "incorrectly encoded storage byte array",
+	0x31: "pop() on empty array",
+	0x32: "array index out of bounds",
+	0x41: "out of memory",
+	0x51: "call to zero-initialized internal function",
+}
+
+// revertErrorsABI is the abi (--abi-file) of the contract being called, its errors are used to decode custom errors
+var revertErrorsABI *abi.ABI
+
+// setRevertErrorsABI sets the abi used to decode custom errors, invalid abi is ignored
+func setRevertErrorsABI(abiContent []byte) {
+	contractABI, err := parseContractABI(abiContent)
+	if err != nil {
+		log.Printf("warning: custom errors in abi are not used for revert reason: %v", err)
+		return
+	}
+	revertErrorsABI = &contractABI
+}
+
+// revertError is the error of a reverted call, the revert data is decoded as reason
+type revertError struct {
+	err    error // the original rpc error
+	data   []byte
+	reason string
+}
+
+func (e *revertError) Error() string {
+	if len(e.data) == 0 {
+		return fmt.Sprintf("execution reverted: %s", e.reason)
+	}
+	return fmt.Sprintf("execution reverted: %s (revert data: %s)", e.reason, hexutil.Encode(e.data))
+}
+
+func (e *revertError) Unwrap() error {
+	return e.err
+}
+
+// wrapRevertError returns revertError if err is a revert with data, otherwise err is returned unchanged
+func wrapRevertError(err error) error {
+	data, ok := revertDataFromError(err)
+	if !ok {
+		return err
+	}
+	return &revertError{err: err, data: data, reason: decodeRevertData(data, revertErrorsABI, GetFuncSig)}
+}
+
+// revertDataFromError extracts the revert data from error of rpc eth_call or eth_estimateGas
+func revertDataFromError(err error) ([]byte, bool) {
+	var dataErr rpc.DataError
+	if err == nil || !errors.As(err, &dataErr) {
+		return nil, false
+	}
+	dataHex, ok := dataErr.ErrorData().(string)
+	if !ok {
+		return nil, false
+	}
+	data, err := hexutil.Decode(dataHex)
+	if err != nil {
+		return nil, false
+	}
+	return data, true
+}
+
+// decodeRevertData decodes revert data as Error(string), Panic(uint256) or custom error.
+// Custom errors are resolved from errorsABI first, then the signatures from lookupFn.
+func decodeRevertData(data []byte, errorsABI *abi.ABI, lookupFn funcSigLookup) string {
+	if len(data) == 0 {
+		return "no reason"
+	}
+	if len(data) < 4 {
+		return fmt.Sprintf("invalid revert data %s", hexutil.Encode(data))
+	}
+	selector, payload := data[:4], data[4:]
+
+	if bytes.Equal(selector, errorStringSelector) {
+		args, _ := buildInputArgs([]string{"string"})
+		if values, err := args.UnpackValues(payload); err == nil {
+			return values[0].(string)
+		}
+	}
+	if bytes.Equal(selector, panicSelector) {
+		args, _ := buildInputArgs([]string{"uint256"})
+		if values, err := args.UnpackValues(payload); err == nil {
+			code := values[0].(*big.Int)
+			if reason, ok := panicReasons[code.Uint64()]; code.IsUint64() && ok {
+				return fmt.Sprintf("panic: %s (0x%x)", reason, code)
+			}
+			return fmt.Sprintf("panic: unknown code 0x%x", code)
+		}
+	}
+
+	if errorsABI != nil {
+		for _, abiErr := range errorsABI.Errors {
+			if !bytes.Equal(abiErr.ID[:4], selector) {
+				continue
+			}
+			if params, err := formatErrorParams(abiErr.Inputs, payload); err == nil {
+				return abiErr.Sig + params
+			}
+		}
+	}
+
+	if lookupFn != nil {
+		sigs, err := lookupFn("0x" + hex.EncodeToString(selector))
+		if err != nil {
+			log.Printf("warning: lookup error signature failed: %v", err)
+		}
+		for _, sig := range sigs {
+			name, argTypes, err := parseFuncSignature(sig)
+			if err != nil {
+				continue
+			}
+			args, err := buildInputArgs(argTypes)
+			if err != nil {
+				continue
+			}
+			if params, err := formatErrorParams(args, payload); err == nil {
+				return fmt.Sprintf("%s(%s)%s", name, strings.Join(argTypes, ","), params)
+			}
+		}
+	}
+
+	return fmt.Sprintf("unknown custom error 0x%x", selector)
+}
+
+// formatErrorParams decodes the params of custom error, returns them like `: name1=value1, name2=value2`
+func formatErrorParams(args abi.Arguments, payload []byte) (string, error) {
+	values, err := args.UnpackValues(payload)
+	if err != nil {
+		return "", err
+	}
+	if !isExactABIDecode(args, values, payload) {
+		return "", fmt.Errorf("payload does not match error inputs exactly")
+	}
+	if len(values) == 0 {
+		return "", nil
+	}
+
+	argNames := buildArgNames(args)
+	var params []string
+	for i, value := range values {
+		encoded, _ := json.Marshal(normalizeDecodedValue(value))
+		params = append(params, fmt.Sprintf("%s=%s", argNames[i], strings.Trim(string(encoded), `"`)))
+	}
+	return ": " + strings.Join(params, ", "), nil
+}
+
+// replayTxRevertReason replays the mined failed tx by eth_call on the state of parent block of its block to recover
+// the revert reason. The state of its own block already includes the effects of the tx and the later txs in the
+// block, but the parent state misses the earlier txs in the block, so the reason is an approximation.
+// Empty string is returned if the revert can not be reproduced.
+func replayTxRevertReason(rpcClient *rpc.Client, tx *types.Transaction, from common.Address, blockNumber *big.Int) string {
+	var parent = new(big.Int)
+	if blockNumber.Sign() > 0 {
+		parent.Sub(blockNumber, big.NewInt(1))
+	}
+
+	msg := ethereum.CallMsg{
+		From:  from,
+		To:    tx.To(),
+		Gas:   tx.Gas(),
+		Value: tx.Value(),
+		Data:  tx.Data(),
+	}
+	var result hexutil.Bytes
+	err := rpcClient.CallContext(context.Background(), &result, "eth_call", toCallArg(msg), hexutil.EncodeBig(parent))
+	if err == nil {
+		return ""
+	}
+
+	var revertErr *revertError
+	if errors.As(wrapRevertError(err), &revertErr) {
+		return revertErr.reason
+	}
+	return err.Error()
+}
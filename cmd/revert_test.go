@@ -0,0 +1,103 @@
+package cmd
+
+import (
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestDecodeRevertData(t *testing.T) {
+	errorsABI, err := parseContractABI([]byte(`[{"type":"error","name":"InsufficientBalance","inputs":[{"name":"available","type":"uint256"},{"name":"required","type":"uint256"}]}]`))
+	if err != nil {
+		t.Fatalf("parseContractABI failed: %v", err)
+	}
+	lookupFn := func(selector string) ([]string, error) {
+		if selector == "0x8c905368" {
+			return []string{"NotEnoughFunds(uint256,uint256)"}, nil
+		}
+		return nil, nil
+	}
+
+	tests := []struct {
+		data     string
+		expected string
+	}{
+		{"0x", "no reason"},
+		{"0x08c379a0" +
+			"0000000000000000000000000000000000000000000000000000000000000020" +
+			"0000000000000000000000000000000000000000000000000000000000000014" +
+			"696e73756666696369656e742062616c616e6365000000000000000000000000", "insufficient balance"},
+		{"0x4e487b710000000000000000000000000000000000000000000000000000000000000011", "panic: arithmetic underflow or overflow (0x11)"},
+		{"0x4e487b710000000000000000000000000000000000000000000000000000000000000099", "panic: unknown code 0x99"},
+		{"0xcf479181" + // InsufficientBalance(uint256,uint256) in abi
+			"0000000000000000000000000000000000000000000000000000000000000001" +
+			"0000000000000000000000000000000000000000000000000000000000000002", "InsufficientBalance(uint256,uint256): available=1, required=2"},
+		{"0x8c905368" + // NotEnoughFunds(uint256,uint256) from lookup
+			"0000000000000000000000000000000000000000000000000000000000000003" +
+			"0000000000000000000000000000000000000000000000000000000000000004", "NotEnoughFunds(uint256,uint256): arg0=3, arg1=4"},
+		{"0x12345678", "unknown custom error 0x12345678"},
+		{"0x1234", "invalid revert data 0x1234"},
+	}
+
+	for i, tc := range tests {
+		got := decodeRevertData(hexutil.MustDecode(tc.data), &errorsABI, lookupFn)
+		if got != tc.expected {
+			t.Fatalf("test %d: expected: %v, got: %v", i+1, tc.expected, got)
+		}
+	}
+}
+
+// revertingEth is a stub of eth namespace, eth_call always reverts with data
+type revertingEth struct {
+	data  string
+	block string // the block of last eth_call
+}
+
+type stubRevertError struct {
+	data string
+}
+
+func (e *stubRevertError) Error() string          { return "execution reverted" }
+func (e *stubRevertError) ErrorCode() int         { return 3 }
+func (e *stubRevertError) ErrorData() interface{} { return e.data }
+
+func (s *revertingEth) Call(args map[string]interface{}, block string) (hexutil.Bytes, error) {
+	s.block = block
+	return nil, &stubRevertError{data: s.data}
+}
+
+func TestRevertReasonFromRpc(t *testing.T) {
+	errorsABI, err := abi.JSON(strings.NewReader(`[{"type":"error","name":"Unauthorized","inputs":[{"name":"caller","type":"address"}]}]`))
+	if err != nil {
+		t.Fatalf("abi.JSON failed: %v", err)
+	}
+	revertErrorsABI = &errorsABI
+	defer func() { revertErrorsABI = nil }()
+
+	revertData := "0x8e4a23d6000000000000000000000000" + "8f36975cdea2e6e64f85719788c8efbbe89dfbbb" // Unauthorized(address)
+	stub := &revertingEth{data: revertData}
+	rpcClient := dialStubRpc(t, map[string]any{"eth": stub})
+
+	expectedReason := "Unauthorized(address): caller=0x8F36975cdeA2e6E64f85719788C8EFBBe89DFBbb"
+	to := common.HexToAddress("0xdAC17F958D2ee523a2206206994597C13D831ec7")
+	_, err = Call(rpcClient, to, []byte{1, 2, 3, 4})
+	var revertErr *revertError
+	if !errors.As(err, &revertErr) || revertErr.reason != expectedReason {
+		t.Fatalf("expected: %v, got: %v", expectedReason, err)
+	}
+	if !strings.Contains(err.Error(), revertData) {
+		t.Fatalf("expected revert data in error, got: %v", err)
+	}
+
+	tx := types.NewTx(&types.LegacyTx{Nonce: 1, To: &to, Value: big.NewInt(0), Gas: 60000, GasPrice: big.NewInt(1e9), Data: []byte{1, 2, 3, 4}})
+	reason := replayTxRevertReason(rpcClient, tx, common.HexToAddress("0x8F36975cdeA2e6E64f85719788C8EFBBe89DFBbb"), big.NewInt(100))
+	if reason != expectedReason || stub.block != "0x63" {
+		t.Fatalf("expected: %v at parent block 0x63, got: %v at %v", expectedReason, reason, stub.block)
+	}
+}
@@ -2,13 +2,8 @@ package cmd
 
 import (
 	"context"
-	"encoding/json"
-	"fmt"
-	"io"
 	"log"
-	"net/http"
 	"os"
-	"strconv"
 	"strings"
 
 	"github.com/ethereum/go-ethereum/ethclient"
@@ -26,13 +21,25 @@ var (
 	globalOptGasLimit             uint64
 	globalOptNonce                int64
 	globalOptPrivateKey           string
+	globalOptKeystore             string
+	globalOptFrom                 string
+	globalOptPasswordFile         string
+	globalOptMnemonic             string
+	globalOptDerivationPath       string
+	globalOptExternalSigner       string
 	globalOptTerseOutput          bool
 	globalOptDryRun               bool
+	globalOptSimulate             bool
+	globalOptForce                bool
 	globalOptShowPreHash          bool
 	globalOptShowRawTx            bool
 	globalOptShowInputData        bool
 	globalOptShowEstimateGas      bool
+	globalOptShowReceipt          bool
 	globalOptTxType               string
+	globalOptConfigFile           string
+	globalOptOutput               string
+	globalOptBlock                string
 	rootCmd                       = &cobra.Command{
 		Use:   "ethutil",
 		Short: "An Ethereum util, can transfer eth, check balance, call any contract function etc. All EVM-compatible chains are supported.",
@@ -40,6 +47,10 @@ var (
 
 	globalClient  *Client
 	globalChainId string
+	globalBlock   = rpc.BlockNumberOrHashWithNumber(rpc.LatestBlockNumber) // parsed from --block
+
+	globalConfig       *Config
+	globalChainProfile *ChainProfile // nil if chain is unknown, e.g. only --node-url is given
 )
 
 type Client struct {
@@ -48,8 +59,9 @@ type Client struct {
 }
 
 // InitGlobalClient initializes a client that uses the given RPC client.
+// nodeUrl can be a comma separated list, the healthiest endpoint is used, and the others are used for failover.
 func InitGlobalClient(nodeUrl string) {
-	rpcClient, err := rpc.Dial(nodeUrl)
+	rpcClient, err := dialRpc(splitRpcUrls(nodeUrl))
 	checkErr(err)
 
 	globalClient = &Client{
@@ -71,27 +83,6 @@ const nodeSepolia = "sepolia"
 const nodeSokol = "sokol"
 const nodeBsc = "bsc"
 
-var nodeUrlMap = map[string]string{
-	nodeMainnet: "wss://mainnet.infura.io/ws/v3/21a9f5ba4bce425795cac796a66d7472", // please replace infura project id
-	nodeSepolia: "wss://sepolia.infura.io/ws/v3/21a9f5ba4bce425795cac796a66d7472", // please replace infura project id
-	nodeSokol:   "https://sokol.poa.network",
-	nodeBsc:     "https://bsc-dataseed1.binance.org",
-}
-
-var nodeTxExplorerUrlMap = map[string]string{
-	nodeMainnet: "https://etherscan.io/tx/",
-	nodeSepolia: "https://sepolia.etherscan.io/tx/",
-	nodeSokol:   "https://blockscout.com/poa/sokol/tx/",
-	nodeBsc:     "https://bscscan.com/tx/",
-}
-
-var nodeApiUrlMap = map[string]string{
-	nodeMainnet: "https://api.etherscan.io/api?module=contract&action=getsourcecode&address=%s",
-	nodeSepolia: "https://api-sepolia.etherscan.io/api?module=contract&action=getsourcecode&address=%s",
-	nodeSokol:   "https://blockscout.com/poa/sokol/api?module=contract&action=getsourcecode&address=%s",
-	nodeBsc:     "https://api.bscscan.com/api?module=contract&action=getsourcecode&address=%s",
-}
-
 // Execute cobra root command
 func Execute() error {
 	return rootCmd.Execute()
@@ -101,26 +92,39 @@ func init() {
 	cobra.EnableCommandSorting = false
 	cobra.OnInitialize(initConfig)
 
-	rootCmd.PersistentFlags().StringVarP(&globalOptNodeUrl, "node-url", "", "", "the target connection node url, if this option specified, the --chain option is ignored")
-	rootCmd.PersistentFlags().StringVarP(&globalOptChain, "chain", "", "sepolia", "mainnet | sepolia | sokol | bsc. This parameter can be set as the chain ID, in this case the rpc comes from https://chainid.network/chains_mini.json")
+	rootCmd.PersistentFlags().StringVarP(&globalOptNodeUrl, "node-url", "", "", "the target connection node url, can be a comma separated list for failover. If this option specified, the rpc of --chain is ignored")
+	rootCmd.PersistentFlags().StringVarP(&globalOptChain, "chain", "", "sepolia", "mainnet | sepolia | sokol | bsc | any chain profile name in config file. This parameter can also be a chain id or short name in the chain registry, see chains command")
+	rootCmd.PersistentFlags().StringVarP(&globalOptConfigFile, "config", "", "", "the config file, it's merged after ~/.config/ethutil/config.toml and ./ethutil.toml")
 	rootCmd.PersistentFlags().StringVarP(&globalOptGasPrice, "gas-price", "", "", "the gas price, unit is gwei.")
 	rootCmd.PersistentFlags().StringVarP(&globalOptMaxPriorityFeePerGas, "max-priority-fee-per-gas", "", "", "maximum fee per gas they are willing to give to miners, unit is gwei. see eip1559")
 	rootCmd.PersistentFlags().StringVarP(&globalOptMaxFeePerGas, "max-fee-per-gas", "", "", "maximum fee per gas they are willing to pay total, unit is gwei. see eip1559")
 	rootCmd.PersistentFlags().Uint64VarP(&globalOptGasLimit, "gas-limit", "", 0, "the gas limit")
 	rootCmd.PersistentFlags().Int64VarP(&globalOptNonce, "nonce", "", -1, "the nonce, -1 means check online")
 	rootCmd.PersistentFlags().StringVarP(&globalOptPrivateKey, "private-key", "k", "", "the private key, eth would be send from this account")
+	rootCmd.PersistentFlags().StringVarP(&globalOptKeystore, "keystore", "", "", "the keystore file or directory, it can be used instead of --private-key")
+	rootCmd.PersistentFlags().StringVarP(&globalOptFrom, "from", "", "", "the address of signer, it selects the key when --keystore is a directory with multiple keys, or the account of --external-signer. It's required by build-raw-tx with --sign-data")
+	rootCmd.PersistentFlags().StringVarP(&globalOptPasswordFile, "password-file", "", "", "the file contains password of keystore, environment variable "+keystorePasswordEnv+" is used if not given, otherwise prompt for it")
+	rootCmd.PersistentFlags().StringVarP(&globalOptMnemonic, "mnemonic", "", "", "the mnemonic words, the key of --derivation-path is used as signer")
+	rootCmd.PersistentFlags().StringVarP(&globalOptDerivationPath, "derivation-path", "", ethBip44Path, "the HD derivation path, it's used by --mnemonic and dump-address")
+	rootCmd.PersistentFlags().StringVarP(&globalOptExternalSigner, "external-signer", "", "", "the http url or ipc path of external signer (e.g. clef), tx and messages are signed by its json-rpc api account_signTransaction and account_signData")
 	rootCmd.PersistentFlags().BoolVarP(&globalOptTerseOutput, "terse", "", false, "produce terse output")
 	rootCmd.PersistentFlags().BoolVarP(&globalOptDryRun, "dry-run", "", false, "do not broadcast tx")
+	rootCmd.PersistentFlags().BoolVarP(&globalOptSimulate, "simulate", "", false, "simulate the signed tx by eth_call (and debug_traceCall if available) at pending state before broadcasting, report revert reason, gas used, events and balance deltas. The tx is not broadcasted if simulation fails, unless --force")
+	rootCmd.PersistentFlags().BoolVarP(&globalOptForce, "force", "", false, "broadcast tx even if --simulate reports it would fail")
 	rootCmd.PersistentFlags().BoolVarP(&globalOptShowPreHash, "show-pre-hash", "", false, "print pre hash, the input of ecdsa sign")
 	rootCmd.PersistentFlags().BoolVarP(&globalOptShowRawTx, "show-raw-tx", "", false, "print raw signed tx")
 	rootCmd.PersistentFlags().BoolVarP(&globalOptShowInputData, "show-input-data", "", false, "print input data of tx")
 	rootCmd.PersistentFlags().BoolVarP(&globalOptShowEstimateGas, "show-estimate-gas", "", false, "print estimate gas of tx")
+	rootCmd.PersistentFlags().BoolVarP(&globalOptShowReceipt, "show-receipt", "", false, "print receipt of tx after it's mined, including gas used, fee paid and decoded event logs")
 	rootCmd.PersistentFlags().StringVarP(&globalOptTxType, "tx-type", "", "eip1559", "eip155 | eip1559, the type of tx your want to send")
+	rootCmd.PersistentFlags().StringVarP(&globalOptOutput, "output", "o", outputText, "text | json, the format of result printed to stdout, logs are always printed to stderr")
+	rootCmd.PersistentFlags().StringVarP(&globalOptBlock, "block", "", "latest", "number | hash | latest | pending | safe | finalized | earliest, the block whose state is read by query, erc20 read methods, balance, code, storage, proxy and multicall, other commands read the latest state")
 
 	rootCmd.AddCommand(balanceCmd)
 	rootCmd.AddCommand(transferCmd)
 	rootCmd.AddCommand(callCmd)
 	rootCmd.AddCommand(queryCmd)
+	rootCmd.AddCommand(multicallCmd)
 	rootCmd.AddCommand(deployCmd)
 	rootCmd.AddCommand(deployErc20Cmd)
 	rootCmd.AddCommand(dropTxCmd)
@@ -130,118 +134,66 @@ func init() {
 	rootCmd.AddCommand(dumpAddrCmd)
 	rootCmd.AddCommand(computeContractAddrCmd)
 	rootCmd.AddCommand(buildRawTxCmd)
+	rootCmd.AddCommand(txCmd)
 	rootCmd.AddCommand(broadcastTxCmd)
+	rootCmd.AddCommand(receiptCmd)
+	rootCmd.AddCommand(traceCmd)
+	rootCmd.AddCommand(logsCmd)
 	rootCmd.AddCommand(decodeTxCmd)
 	rootCmd.AddCommand(decodeCalldataCmd)
 	rootCmd.AddCommand(getCodeCmd)
+	rootCmd.AddCommand(storageCmd)
+	rootCmd.AddCommand(proxyCmd)
+	rootCmd.AddCommand(disasmCmd)
 	rootCmd.AddCommand(erc20Cmd)
 	rootCmd.AddCommand(keccakCmd)
 	rootCmd.AddCommand(personalSignCmd)
 	rootCmd.AddCommand(eip712SignCmd)
 	rootCmd.AddCommand(aaSimpleAccountCmd)
 	rootCmd.AddCommand(downloadSrcCmd)
+	rootCmd.AddCommand(verifyCmd)
 	rootCmd.AddCommand(eip7702SetEoaCodeCmd)
 	rootCmd.AddCommand(eip7702SignAuthTupleCmd)
 	rootCmd.AddCommand(publicRpcCmd)
 	rootCmd.AddCommand(recoverPublicKeyCmd)
+	rootCmd.AddCommand(chainsCmd)
+	rootCmd.AddCommand(keystoreCmd)
 }
 
-func testRpcValid(rpcUrl string) error {
-	client, err := rpc.Dial(rpcUrl)
-	if err != nil {
-		return err
-	}
-	defer client.Close()
-
-	var result string
-	err = client.Call(&result, "eth_chainId")
-	return err
-}
+func initConfig() {
+	var err error
 
-func findRpc(chainId string) (string, string, error) {
-	// Get rpc from https://chainid.network/chains_mini.json
-	resp, err := http.Get("https://chainid.network/chains_mini.json")
-	if err != nil {
-		return "", "", err
-	}
-	defer resp.Body.Close()
-	body, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return "", "", err
-	}
+	globalConfig, err = loadConfig(globalOptConfigFile)
+	checkErr(err)
 
-	type respItem struct {
-		Name           string `json:"name"`
-		ChainId        uint64 `json:"chainId"`
-		ShortName      string `json:"shortName"`
-		NativeCurrency struct {
-			Symbol   string `json:"symbol"`
-			Decimals uint64 `json:"decimals"`
-		} `json:"nativeCurrency"`
-		Rpc     []string `json:"rpc"`
-		InfoUrl string   `json:"infoURL"`
+	if !rootCmd.PersistentFlags().Changed("chain") && globalConfig.DefaultChain != "" {
+		globalOptChain = globalConfig.DefaultChain
 	}
 
-	var data []respItem
-	err = json.Unmarshal(body, &data)
-	if err != nil {
-		return "", "", err
+	if globalOptNodeUrl != "" && !rootCmd.PersistentFlags().Changed("chain") {
+		// Clear globalOptChain if only globalOptNodeUrl is provided
+		globalOptChain = ""
 	}
 
-	var finalRpc = ""
-	var chainName = ""
-	for _, item := range data {
-		if strconv.Itoa(int(item.ChainId)) == chainId {
-			if item.NativeCurrency.Decimals != 18 {
-				return "", "", fmt.Errorf("only support chain with decimals 18, but %s is %d", globalOptChain, item.NativeCurrency.Decimals)
-			}
-
-			for _, nodeRpc := range item.Rpc {
-				if strings.Contains(nodeRpc, "${") {
-					// filter out rpc contains '${', for example, https://mainnet.infura.io/v3/${INFURA_API_KEY}
-					continue
-				}
-
-				err = testRpcValid(nodeRpc)
-				if err != nil {
-					log.Printf("Try to find another rpc for chain id %s (%s), because %s unavailable (%s)", chainId, item.Name, nodeRpc, err)
-					continue
-				}
+	if globalOptChain != "" {
+		if profile, ok := globalConfig.Chains[globalOptChain]; ok {
+			globalChainProfile = profile
+			applyProfileDefaults(profile)
+		} else if chain := lookupChain(loadChainRegistry(), globalOptChain); chain != nil {
+			globalChainProfile = chainProfileFromRegistry(chain)
+			log.Printf("Chain %s is %s (chain id %d) in chain registry", globalOptChain, chain.Name, chain.ChainId)
+		} else {
+			log.Fatalf("unknown chain %s, valid chains: %s, or a chain id / short name in chain registry (see chains command)", globalOptChain, strings.Join(globalConfig.profileNames(), " | "))
+		}
 
-				finalRpc = nodeRpc
-				chainName = item.Name
+		if globalOptNodeUrl == "" {
+			if len(globalChainProfile.Rpc) == 0 {
+				log.Fatalf("no rpc configured for chain %s", globalOptChain)
 			}
-			break
+			globalOptNodeUrl = strings.Join(globalChainProfile.Rpc, ",")
 		}
 	}
 
-	if finalRpc == "" {
-		return "", "", fmt.Errorf("Can not find any rpc for chain id %v", chainId)
-	}
-
-	return finalRpc, chainName, nil
-}
-
-func initConfig() {
-	var err error
-
-	if !contains([]string{nodeMainnet, nodeSepolia, nodeSokol, nodeBsc}, globalOptChain) {
-		var chainId = globalOptChain
-		log.Printf("Seaching rpc for chain id %s", chainId)
-
-		finalRpc, chainName, err := findRpc(chainId)
-		checkErr(err)
-		log.Printf("Chain id %s (%s) use rpc %s", chainId, chainName, finalRpc)
-		globalOptNodeUrl = finalRpc
-	}
-
-	if globalOptNodeUrl == "" {
-		globalOptNodeUrl = nodeUrlMap[globalOptChain]
-	} else {
-		// Clear globalOptChain if globalOptNodeUrl is provided
-		globalOptChain = ""
-	}
-
 	if globalOptGasPrice != "" {
 		if _, err = decimal.NewFromString(globalOptGasPrice); err != nil {
 			log.Printf("invalid option for --gas-price: %v", globalOptGasPrice)
@@ -266,6 +218,18 @@ func initConfig() {
 		}
 	}
 
+	if !contains([]string{outputText, outputJson}, globalOptOutput) {
+		log.Printf("invalid option for --output: %v", globalOptOutput)
+		_ = rootCmd.Help()
+		os.Exit(1)
+	}
+
+	if globalBlock, err = parseBlockOpt(globalOptBlock); err != nil {
+		log.Printf("invalid option for --block: %v", globalOptBlock)
+		_ = rootCmd.Help()
+		os.Exit(1)
+	}
+
 	if !contains([]string{txTypeEip155, txTypeEip1559}, globalOptTxType) {
 		log.Printf("invalid option for --tx-type: %v", globalOptTxType)
 		_ = rootCmd.Help()
@@ -0,0 +1,365 @@
+package cmd
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// A healthy endpoint which is more than maxRpcBlockLag blocks behind the highest one is only used for failover
+const maxRpcBlockLag = 5
+
+// How many times all endpoints are tried before giving up a request
+const rpcFailoverRounds = 3
+
+// rateLimitMessageRE matches the messages of JSON-RPC errors which mean "rate limited", they are returned with HTTP 200
+// by some providers
+var rateLimitMessageRE = regexp.MustCompile(`(?i)rate.?limit|too many requests|daily request count exceeded`)
+
+// resultLimitMessageRE matches the messages of JSON-RPC errors about caps of result size or block range (e.g. of
+// eth_getLogs), they share the code -32005 with rate limit but retrying the same request doesn't help, a smaller
+// range does
+var resultLimitMessageRE = regexp.MustCompile(`(?i)more than [\d,]+ results|max(imum)? results|too many (logs|results)|block range|range (is )?too (large|wide)|[\d,]+ range|range limit|response size|query timeout`)
+
+// splitRpcUrls splits comma separated rpc urls, empty items are ignored
+func splitRpcUrls(nodeUrl string) []string {
+	var urls []string
+	for _, item := range strings.Split(nodeUrl, ",") {
+		item = strings.TrimSpace(item)
+		if item != "" {
+			urls = append(urls, item)
+		}
+	}
+	return urls
+}
+
+// redactRpcUrl keeps only scheme and host of rpc url in logs, the api key of provider is often in its path or query
+func redactRpcUrl(rpcUrl string) string {
+	u, err := url.Parse(rpcUrl)
+	if err != nil || u.Host == "" {
+		return "<invalid url>"
+	}
+	return u.Scheme + "://" + u.Host
+}
+
+func isHttpUrl(rpcUrl string) bool {
+	return strings.HasPrefix(rpcUrl, "http://") || strings.HasPrefix(rpcUrl, "https://")
+}
+
+// dialRpc dials the healthiest endpoint of urls, the other http endpoints are used for failover
+func dialRpc(urls []string) (*rpc.Client, error) {
+	if len(urls) == 0 {
+		return nil, fmt.Errorf("no rpc url")
+	}
+
+	var ranked = urls
+	if len(urls) > 1 {
+		statuses := probeRpcEndpoints(urls)
+		var err error
+		ranked, err = rankRpcEndpoints(statuses)
+		if err != nil {
+			return nil, err
+		}
+		for _, status := range statuses {
+			if status.Url == ranked[0] {
+				log.Printf("Use rpc %s (block %v, response time %v), %d more endpoints for failover",
+					redactRpcUrl(status.Url), status.BlockHeight, status.ResponseTime, len(ranked)-1)
+			}
+		}
+	}
+
+	if !isHttpUrl(ranked[0]) {
+		// failover is only supported for http endpoints
+		return rpc.Dial(ranked[0])
+	}
+
+	var httpUrls []string
+	for _, rpcUrl := range ranked {
+		if isHttpUrl(rpcUrl) {
+			httpUrls = append(httpUrls, rpcUrl)
+		}
+	}
+	transport, err := newFailoverTransport(httpUrls, http.DefaultTransport)
+	if err != nil {
+		return nil, err
+	}
+	return rpc.DialOptions(context.Background(), httpUrls[0], rpc.WithHTTPClient(&http.Client{Transport: transport}))
+}
+
+// probeRpcEndpoints tests all endpoints concurrently
+func probeRpcEndpoints(urls []string) []RpcStatus {
+	var statuses = make([]RpcStatus, len(urls))
+	var wg sync.WaitGroup
+	for i, rpcUrl := range urls {
+		wg.Add(1)
+		go func(i int, rpcUrl string) {
+			defer wg.Done()
+			statuses[i] = testRpcEndpoint(rpcUrl)
+		}(i, rpcUrl)
+	}
+	wg.Wait()
+	return statuses
+}
+
+// rankRpcEndpoints returns urls of healthy endpoints, the best one first.
+// Endpoints near the highest block are ordered by response time, lagging endpoints are put at the end.
+func rankRpcEndpoints(statuses []RpcStatus) ([]string, error) {
+	var healthy []RpcStatus
+	var maxHeight = new(big.Int)
+	for _, status := range statuses {
+		if status.Error != "" {
+			log.Printf("rpc %s unavailable: %s", redactRpcUrl(status.Url), status.Error)
+			continue
+		}
+		healthy = append(healthy, status)
+		if status.BlockHeight.Cmp(maxHeight) > 0 {
+			maxHeight = status.BlockHeight
+		}
+	}
+	if len(healthy) == 0 {
+		return nil, fmt.Errorf("all %d rpc endpoints are unavailable", len(statuses))
+	}
+
+	isLagging := func(status RpcStatus) bool {
+		lag := new(big.Int).Sub(maxHeight, status.BlockHeight)
+		return lag.Cmp(big.NewInt(maxRpcBlockLag)) > 0
+	}
+	sort.SliceStable(healthy, func(i, j int) bool {
+		if isLagging(healthy[i]) != isLagging(healthy[j]) {
+			return !isLagging(healthy[i])
+		}
+		return healthy[i].ResponseTime < healthy[j].ResponseTime
+	})
+
+	var rc []string
+	for _, status := range healthy {
+		rc = append(rc, status.Url)
+	}
+	return rc, nil
+}
+
+// failoverTransport is a http.RoundTripper which sends request to the current endpoint,
+// and switches to the next endpoint on transport error, HTTP 429/5xx or rate limit error in JSON-RPC response.
+// The non-idempotent requests (e.g. eth_sendRawTransaction) are sent again only if the endpoint didn't process
+// them, otherwise a tx accepted by a node whose response is lost would be reported as failed by the next node.
+type failoverTransport struct {
+	mu         sync.Mutex
+	endpoints  []*url.URL
+	current    int
+	base       http.RoundTripper
+	retryDelay time.Duration // delay before a new round of all endpoints
+}
+
+func newFailoverTransport(urls []string, base http.RoundTripper) (*failoverTransport, error) {
+	var endpoints []*url.URL
+	for _, rpcUrl := range urls {
+		u, err := url.Parse(rpcUrl)
+		if err != nil {
+			return nil, fmt.Errorf("invalid rpc url %s: %w", rpcUrl, err)
+		}
+		endpoints = append(endpoints, u)
+	}
+	return &failoverTransport{
+		endpoints:  endpoints,
+		base:       base,
+		retryDelay: 2 * time.Second,
+	}, nil
+}
+
+func (t *failoverTransport) currentEndpoint() (int, *url.URL) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return t.current, t.endpoints[t.current]
+}
+
+// switchEndpoint moves to the next endpoint if the failed one is still the current one
+func (t *failoverTransport) switchEndpoint(failed int, reason error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.current != failed {
+		// switched by another request already
+		return
+	}
+	t.current = (failed + 1) % len(t.endpoints)
+	if len(t.endpoints) > 1 {
+		log.Printf("rpc %s failed (%v), fail over to %s", redactRpcUrl(t.endpoints[failed].String()), reason, redactRpcUrl(t.endpoints[t.current].String()))
+	} else {
+		log.Printf("rpc %s failed (%v), retry it", redactRpcUrl(t.endpoints[failed].String()), reason)
+	}
+}
+
+func (t *failoverTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	// Keep the request body, it's sent again on failover
+	var body []byte
+	if req.Body != nil {
+		var err error
+		body, err = io.ReadAll(req.Body)
+		_ = req.Body.Close()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var idempotent = isIdempotentRpcRequest(body)
+	var lastErr error
+	for attempt := 0; attempt < len(t.endpoints)*rpcFailoverRounds; attempt++ {
+		if attempt > 0 && attempt%len(t.endpoints) == 0 {
+			select {
+			case <-req.Context().Done():
+				return nil, req.Context().Err()
+			case <-time.After(t.retryDelay):
+			}
+		}
+
+		index, endpoint := t.currentEndpoint()
+		outReq := req.Clone(req.Context())
+		endpointCopy := *endpoint
+		outReq.URL = &endpointCopy
+		outReq.Host = endpoint.Host
+		outReq.Body = io.NopCloser(bytes.NewReader(body))
+		outReq.ContentLength = int64(len(body))
+
+		resp, err := t.base.RoundTrip(outReq)
+		if err == nil {
+			err = checkRetryableResponse(resp)
+			if err == nil {
+				return resp, nil
+			}
+		}
+		if req.Context().Err() != nil {
+			return nil, err
+		}
+
+		lastErr = err
+		t.switchEndpoint(index, err)
+		if !idempotent && !isRequestNotProcessed(err) {
+			return nil, err
+		}
+	}
+
+	return nil, lastErr
+}
+
+// nonIdempotentRpcMethods are the methods which must not be sent to nodes again once they may be processed
+var nonIdempotentRpcMethods = map[string]bool{
+	"eth_sendRawTransaction": true,
+	"eth_sendTransaction":    true,
+}
+
+// isIdempotentRpcRequest returns false if body is a JSON-RPC request (or batch) including non-idempotent methods
+func isIdempotentRpcRequest(body []byte) bool {
+	type request struct {
+		Method string `json:"method"`
+	}
+	var requests []request
+	if trimmed := bytes.TrimSpace(body); len(trimmed) > 0 && trimmed[0] == '[' {
+		if err := json.Unmarshal(trimmed, &requests); err != nil {
+			return false
+		}
+	} else {
+		var single request
+		if err := json.Unmarshal(trimmed, &single); err != nil {
+			return false
+		}
+		requests = []request{single}
+	}
+	for _, r := range requests {
+		if nonIdempotentRpcMethods[r.Method] {
+			return false
+		}
+	}
+	return true
+}
+
+// rpcRejectedError means the endpoint rejected the request without processing it, e.g. rate limited
+type rpcRejectedError struct {
+	reason string
+}
+
+func (e *rpcRejectedError) Error() string { return e.reason }
+
+// isRequestNotProcessed returns true if err of a request means it's not processed by endpoint: the connection
+// can't be made, or the request is rejected by rate limit
+func isRequestNotProcessed(err error) bool {
+	var rejectedErr *rpcRejectedError
+	if errors.As(err, &rejectedErr) {
+		return true
+	}
+	var opErr *net.OpError
+	return errors.As(err, &opErr) && opErr.Op == "dial"
+}
+
+// checkRetryableResponse returns error if resp means the endpoint is overloaded or broken,
+// the body of resp is kept readable when nil is returned
+func checkRetryableResponse(resp *http.Response) error {
+	if resp.StatusCode == http.StatusTooManyRequests {
+		_ = resp.Body.Close()
+		return &rpcRejectedError{reason: fmt.Sprintf("http status %s", resp.Status)}
+	}
+	if resp.StatusCode >= http.StatusInternalServerError {
+		_ = resp.Body.Close()
+		return fmt.Errorf("http status %s", resp.Status)
+	}
+
+	respBody, err := io.ReadAll(resp.Body)
+	_ = resp.Body.Close()
+	if err != nil {
+		return err
+	}
+	if isRateLimitResponse(respBody) {
+		return &rpcRejectedError{reason: fmt.Sprintf("rate limited: %s", truncateString(string(respBody), 200))}
+	}
+
+	resp.Body = io.NopCloser(bytes.NewReader(respBody))
+	return nil
+}
+
+// isRateLimitResponse returns true if body is a JSON-RPC error response meaning "rate limited". -32005 is "limit
+// exceeded" in EIP-1474, some providers use 429 as error code. Only single responses are checked, an element of
+// batch response may fail for its own reason. The error with data (e.g. revert data of eth_call) is returned by
+// execution, it's never a rate limit.
+func isRateLimitResponse(body []byte) bool {
+	body = bytes.TrimSpace(body)
+	if len(body) == 0 || body[0] != '{' {
+		return false
+	}
+	var resp struct {
+		Error *struct {
+			Code    int             `json:"code"`
+			Message string          `json:"message"`
+			Data    json.RawMessage `json:"data"`
+		} `json:"error"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil || resp.Error == nil {
+		return false
+	}
+	if (len(resp.Error.Data) > 0 && string(resp.Error.Data) != "null") ||
+		strings.HasPrefix(strings.ToLower(resp.Error.Message), "execution reverted") ||
+		resultLimitMessageRE.MatchString(resp.Error.Message) {
+		return false
+	}
+	return resp.Error.Code == -32005 || resp.Error.Code == 429 || rateLimitMessageRE.MatchString(resp.Error.Message)
+}
+
+// truncateString truncates s to at most n bytes, "..." is appended if truncated
+func truncateString(s string, n int) string {
+	if len(s) <= n {
+		return s
+	}
+	return s[:n] + "..."
+}
@@ -0,0 +1,35 @@
+package cmd
+
+import (
+	"net/http/httptest"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// newStubRpcServer starts a json-rpc http server of stub services (namespace => receiver of methods), it's closed
+// when the test ends
+func newStubRpcServer(t *testing.T, services map[string]any) (*rpc.Server, string) {
+	t.Helper()
+	server := rpc.NewServer()
+	for namespace, service := range services {
+		if err := server.RegisterName(namespace, service); err != nil {
+			t.Fatalf("RegisterName %s failed: %v", namespace, err)
+		}
+	}
+	httpServer := httptest.NewServer(server)
+	t.Cleanup(httpServer.Close)
+	return server, httpServer.URL
+}
+
+// dialStubRpc starts a json-rpc server of stub services by newStubRpcServer and dials it
+func dialStubRpc(t *testing.T, services map[string]any) *rpc.Client {
+	t.Helper()
+	_, url := newStubRpcServer(t, services)
+	rpcClient, err := rpc.Dial(url)
+	if err != nil {
+		t.Fatalf("Dial failed: %v", err)
+	}
+	t.Cleanup(rpcClient.Close)
+	return rpcClient
+}
@@ -0,0 +1,227 @@
+package cmd
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/accounts/external"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+	"github.com/tyler-smith/go-bip39"
+)
+
+// The options which specify a signer, it's used in error messages
+const signerOptions = "--private-key, --keystore, --mnemonic or --external-signer"
+
+// Signer signs transactions and messages on behalf of an account.
+// Signatures are 65 bytes in [R || S || V] format where V is 0 or 1.
+type Signer interface {
+	// Address returns the address of the account
+	Address() common.Address
+	// SignHash signs the hash directly
+	SignHash(hash common.Hash) ([]byte, error)
+	// SignText signs EIP191 personal message, i.e. keccak256("\x19Ethereum Signed Message:\n" + len(text) + text)
+	SignText(text []byte) ([]byte, error)
+	// SignTx signs the transaction, the signed transaction is returned
+	SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error)
+	// SignTypedData signs EIP712 typed data
+	SignTypedData(typedData apitypes.TypedData) ([]byte, error)
+}
+
+// keySigner signs with a local private key, the key comes from --private-key, --keystore or --mnemonic
+type keySigner struct {
+	key *ecdsa.PrivateKey
+}
+
+func newKeySigner(key *ecdsa.PrivateKey) *keySigner {
+	return &keySigner{key: key}
+}
+
+func (s *keySigner) Address() common.Address {
+	return crypto.PubkeyToAddress(s.key.PublicKey)
+}
+
+func (s *keySigner) SignHash(hash common.Hash) ([]byte, error) {
+	return crypto.Sign(hash[:], s.key)
+}
+
+func (s *keySigner) SignText(text []byte) ([]byte, error) {
+	return crypto.Sign(accounts.TextHash(text), s.key)
+}
+
+func (s *keySigner) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	return types.SignTx(tx, types.LatestSignerForChainID(chainID), s.key)
+}
+
+func (s *keySigner) SignTypedData(typedData apitypes.TypedData) ([]byte, error) {
+	preHash, err := computePreHash(typedData)
+	if err != nil {
+		return nil, fmt.Errorf("computePreHash failed: %w", err)
+	}
+	return crypto.Sign(preHash[:], s.key)
+}
+
+// externalSigner delegates signing to an external signer (e.g. clef) by json-rpc
+// account_signTransaction and account_signData, the key never leaves the external signer.
+type externalSigner struct {
+	api     *external.ExternalSigner
+	account accounts.Account
+}
+
+// newExternalSigner connects to the external signer, endpoint can be a http url or an ipc path.
+// If from is empty, the external signer must manage exactly one account.
+func newExternalSigner(endpoint string, from string) (*externalSigner, error) {
+	api, err := external.NewExternalSigner(endpoint)
+	if err != nil {
+		return nil, fmt.Errorf("connect external signer %s fail: %w", endpoint, err)
+	}
+
+	var account accounts.Account
+	if from != "" {
+		if !isValidEthAddress(from) {
+			return nil, fmt.Errorf("%v is not a valid eth address", from)
+		}
+		account = accounts.Account{Address: common.HexToAddress(from)}
+	} else {
+		accts := api.Accounts()
+		if len(accts) != 1 {
+			return nil, fmt.Errorf("found %d accounts in external signer, please specify one by --from", len(accts))
+		}
+		account = accts[0]
+	}
+	return &externalSigner{api: api, account: account}, nil
+}
+
+func (s *externalSigner) Address() common.Address {
+	return s.account.Address
+}
+
+func (s *externalSigner) SignHash(hash common.Hash) ([]byte, error) {
+	return nil, errors.New("signing raw hash is not supported by external signer")
+}
+
+func (s *externalSigner) SignText(text []byte) ([]byte, error) {
+	sig, err := s.api.SignText(s.account, text)
+	if err != nil {
+		return nil, fmt.Errorf("external signer account_signData fail: %w", err)
+	}
+	return normalizeSignature(sig)
+}
+
+func (s *externalSigner) SignTx(tx *types.Transaction, chainID *big.Int) (*types.Transaction, error) {
+	if tx.Type() != types.LegacyTxType && tx.Type() != types.DynamicFeeTxType {
+		return nil, fmt.Errorf("tx type %d is not supported by external signer", tx.Type())
+	}
+
+	signedTx, err := s.api.SignTx(s.account, tx, chainID)
+	if err != nil {
+		return nil, fmt.Errorf("external signer account_signTransaction fail: %w", err)
+	}
+	if signedTx == nil {
+		return nil, errors.New("external signer returned no tx")
+	}
+
+	txSigner := types.LatestSignerForChainID(chainID)
+	sender, err := types.Sender(txSigner, signedTx)
+	if err != nil {
+		return nil, fmt.Errorf("invalid signature from external signer: %w", err)
+	}
+	if sender != s.account.Address {
+		return nil, fmt.Errorf("tx signed by %s, expect %s", sender.Hex(), s.account.Address.Hex())
+	}
+	if txSigner.Hash(signedTx) != txSigner.Hash(tx) {
+		log.Printf("warning: tx is modified by external signer")
+	}
+	return signedTx, nil
+}
+
+func (s *externalSigner) SignTypedData(typedData apitypes.TypedData) ([]byte, error) {
+	data, err := json.Marshal(typedData)
+	if err != nil {
+		return nil, err
+	}
+	sig, err := s.api.SignData(s.account, apitypes.DataTyped.Mime, data)
+	if err != nil {
+		return nil, fmt.Errorf("external signer account_signData fail: %w", err)
+	}
+	return normalizeSignature(sig)
+}
+
+// normalizeSignature checks the signature length, and transforms V from 27/28 to 0/1
+func normalizeSignature(sig []byte) ([]byte, error) {
+	if len(sig) != crypto.SignatureLength {
+		return nil, fmt.Errorf("invalid signature length %d", len(sig))
+	}
+	if sig[64] == 27 || sig[64] == 28 {
+		sig[64] -= 27
+	}
+	return sig, nil
+}
+
+// hasSigner returns true if any of --private-key, --keystore, --mnemonic or --external-signer is given
+func hasSigner() bool {
+	resolveProfileSigner()
+	return globalOptPrivateKey != "" || globalOptKeystore != "" || globalOptMnemonic != "" || globalOptExternalSigner != ""
+}
+
+var currentSigner Signer
+
+// loadSigner returns the signer specified by command line options
+func loadSigner() Signer {
+	if currentSigner != nil {
+		return currentSigner
+	}
+
+	if globalOptExternalSigner != "" {
+		signer, err := newExternalSigner(globalOptExternalSigner, globalOptFrom)
+		checkErr(err)
+		currentSigner = signer
+	} else {
+		currentSigner = newKeySigner(loadSignerKey())
+	}
+	return currentSigner
+}
+
+var signerKey *ecdsa.PrivateKey
+
+// loadSignerKey returns the key of --private-key, decrypts the key in --keystore, or derives the key from --mnemonic.
+// For keystore directory, --from selects the key if there are multiple keys.
+func loadSignerKey() *ecdsa.PrivateKey {
+	if signerKey != nil {
+		return signerKey
+	}
+
+	resolveProfileSigner()
+
+	if globalOptPrivateKey != "" {
+		signerKey = hexToPrivateKey(globalOptPrivateKey)
+	} else if globalOptKeystore != "" {
+		file, err := findKeystoreFile(globalOptKeystore, globalOptFrom)
+		checkErr(err)
+		signerKey, err = decryptKeystoreFile(file)
+		checkErr(err)
+	} else if globalOptMnemonic != "" {
+		if !bip39.IsMnemonicValid(globalOptMnemonic) {
+			log.Fatalf("--mnemonic is not a valid mnemonic")
+		}
+		var err error
+		signerKey, err = MnemonicToPrivateKey(globalOptMnemonic, globalOptDerivationPath)
+		checkErr(err)
+	} else if globalOptExternalSigner != "" {
+		log.Fatalf("this command requires a local key, --external-signer is not supported")
+	} else {
+		log.Fatalf("%s is required", signerOptions)
+	}
+
+	if globalOptFrom != "" && crypto.PubkeyToAddress(signerKey.PublicKey) != common.HexToAddress(globalOptFrom) {
+		log.Fatalf("the signer is %s, not the address specified by --from", crypto.PubkeyToAddress(signerKey.PublicKey).Hex())
+	}
+	return signerKey
+}
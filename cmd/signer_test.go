@@ -0,0 +1,141 @@
+package cmd
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/signer/core/apitypes"
+)
+
+// stubClef is a minimal clef-like external signer, it signs everything without confirmation
+type stubClef struct {
+	key *ecdsa.PrivateKey
+}
+
+func (s *stubClef) Version() string {
+	return "6.0.0"
+}
+
+func (s *stubClef) List() []common.Address {
+	return []common.Address{crypto.PubkeyToAddress(s.key.PublicKey)}
+}
+
+func (s *stubClef) SignTransaction(args apitypes.SendTxArgs) (map[string]interface{}, error) {
+	tx, err := args.ToTransaction()
+	if err != nil {
+		return nil, err
+	}
+	signedTx, err := types.SignTx(tx, types.LatestSignerForChainID((*big.Int)(args.ChainID)), s.key)
+	if err != nil {
+		return nil, err
+	}
+	raw, err := signedTx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{"raw": hexutil.Bytes(raw), "tx": signedTx}, nil
+}
+
+func (s *stubClef) SignData(contentType string, addr common.MixedcaseAddress, data hexutil.Bytes) (hexutil.Bytes, error) {
+	var hash []byte
+	switch contentType {
+	case accounts.MimetypeTextPlain:
+		hash = accounts.TextHash(data)
+	case apitypes.DataTyped.Mime:
+		var typedData apitypes.TypedData
+		if err := json.Unmarshal(data, &typedData); err != nil {
+			return nil, err
+		}
+		var err error
+		if hash, _, err = apitypes.TypedDataAndHash(typedData); err != nil {
+			return nil, err
+		}
+	default:
+		return nil, fmt.Errorf("unsupported content type %s", contentType)
+	}
+	sig, err := crypto.Sign(hash, s.key)
+	if err != nil {
+		return nil, err
+	}
+	sig[64] += 27 // clef returns V in 27/28
+	return sig, nil
+}
+
+func TestExternalSigner(t *testing.T) {
+	key := hexToPrivateKey("4f66baf5a1c3a91b6cf8173cdb60d12496e1f572cee6f9f86bc507d87a9790d7")
+
+	_, url := newStubRpcServer(t, map[string]any{"account": &stubClef{key: key}})
+
+	ext, err := newExternalSigner(url, "")
+	if err != nil {
+		t.Fatalf("newExternalSigner failed: %v", err)
+	}
+	local := newKeySigner(key)
+	if ext.Address() != local.Address() {
+		t.Fatalf("expected: %v, got: %v", local.Address(), ext.Address())
+	}
+
+	// signatures of local key and external signer are same, as ecdsa signing is deterministic (RFC6979)
+	text := []byte("hello")
+	localSig, _ := local.SignText(text)
+	extSig, err := ext.SignText(text)
+	if err != nil || hexutil.Encode(extSig) != hexutil.Encode(localSig) {
+		t.Fatalf("SignText expected: %x, got: %x (%v)", localSig, extSig, err)
+	}
+
+	var typedData apitypes.TypedData
+	if err := json.Unmarshal([]byte(`{
+  "types": {
+    "EIP712Domain": [{"name": "name", "type": "string"}, {"name": "chainId", "type": "uint256"}],
+    "Mail": [{"name": "to", "type": "address"}, {"name": "contents", "type": "string"}]
+  },
+  "primaryType": "Mail",
+  "domain": {"name": "Ether Mail", "chainId": 1},
+  "message": {"to": "0xbBbBBBBbbBBBbbbBbbBbbbbBBbBbbbbBbBbbBBbB", "contents": "Hello, Bob!"}
+}`), &typedData); err != nil {
+		t.Fatalf("json.Unmarshal failed: %v", err)
+	}
+	localSig, _ = local.SignTypedData(typedData)
+	extSig, err = ext.SignTypedData(typedData)
+	if err != nil || hexutil.Encode(extSig) != hexutil.Encode(localSig) {
+		t.Fatalf("SignTypedData expected: %x, got: %x (%v)", localSig, extSig, err)
+	}
+
+	to := common.HexToAddress("0x8F36975cdeA2e6E64f85719788C8EFBBe89DFBbb")
+	chainId := big.NewInt(11155111)
+	txs := []*types.Transaction{
+		types.NewTx(&types.LegacyTx{Nonce: 1, To: &to, Value: big.NewInt(1000), Gas: 21000, GasPrice: big.NewInt(1e9)}),
+		types.NewTx(&types.DynamicFeeTx{ChainID: chainId, Nonce: 2, To: &to, Value: big.NewInt(1000), Gas: 21000, GasTipCap: big.NewInt(1e9), GasFeeCap: big.NewInt(2e9), Data: []byte{1, 2}}),
+	}
+	for i, tx := range txs {
+		localTx, _ := local.SignTx(tx, chainId)
+		extTx, err := ext.SignTx(tx, chainId)
+		if err != nil {
+			t.Fatalf("test %d: SignTx failed: %v", i+1, err)
+		}
+		if extTx.Hash() != localTx.Hash() {
+			t.Fatalf("test %d: expected: %v, got: %v", i+1, localTx.Hash(), extTx.Hash())
+		}
+	}
+
+	if _, err := ext.SignHash(common.Hash{}); err == nil {
+		t.Fatalf("expect error for signing raw hash by external signer")
+	}
+
+	// --from must match the external signer if specified
+	other, err := newExternalSigner(url, "0xB2aC853cF815B47903bc19BF4860540306F4f944")
+	if err != nil {
+		t.Fatalf("newExternalSigner failed: %v", err)
+	}
+	if _, err := other.SignTx(txs[0], chainId); err == nil {
+		t.Fatalf("expect error for tx signed by other account")
+	}
+}
@@ -0,0 +1,280 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/shopspring/decimal"
+)
+
+var erc20TransferTopic = crypto.Keccak256Hash([]byte("Transfer(address,address,uint256)"))
+
+// SimulationOutput is the result of --simulate, it's attached to TxOutput in json output
+type SimulationOutput struct {
+	Success       bool                `json:"success"`
+	RevertReason  string              `json:"revertReason,omitempty"`
+	Traced        bool                `json:"traced"`  // false if debug_traceCall is not available, then gasUsed is estimated and logs are unknown
+	GasUsed       uint64              `json:"gasUsed"` // it's from eth_estimateGas if not traced
+	GasLimit      uint64              `json:"gasLimit"`
+	Fee           string              `json:"fee"` // in wei, gasUsed * effective gas price by base fee of the latest block
+	Logs          []*DecodedLogOutput `json:"logs"`
+	BalanceDeltas []BalanceDelta      `json:"balanceDeltas"`
+}
+
+// BalanceDelta is the balance change of an account in simulation
+type BalanceDelta struct {
+	Address string `json:"address"`
+	Token   string `json:"token,omitempty"` // empty for native currency
+	Delta   string `json:"delta"`           // in wei, or in smallest unit of token
+}
+
+// callFrame is a frame of the result of debug_traceCall or debug_traceTransaction with callTracer
+type callFrame struct {
+	Type         string          `json:"type"`
+	From         common.Address  `json:"from"`
+	To           *common.Address `json:"to,omitempty"`
+	Value        *hexutil.Big    `json:"value,omitempty"`
+	Gas          hexutil.Uint64  `json:"gas"`
+	GasUsed      hexutil.Uint64  `json:"gasUsed"`
+	Input        hexutil.Bytes   `json:"input"`
+	Output       hexutil.Bytes   `json:"output,omitempty"`
+	Error        string          `json:"error,omitempty"`
+	RevertReason string          `json:"revertReason,omitempty"`
+	Calls        []*callFrame    `json:"calls,omitempty"`
+	Logs         []*callLog      `json:"logs,omitempty"`
+}
+
+// callLog is an event log in callFrame, Position is the number of sub calls made before the log
+type callLog struct {
+	Address  common.Address `json:"address"`
+	Topics   []common.Hash  `json:"topics"`
+	Data     hexutil.Bytes  `json:"data"`
+	Position hexutil.Uint   `json:"position"`
+}
+
+// callTracerConfig is the config of debug_traceCall with callTracer, the logs are included
+var callTracerConfig = map[string]interface{}{
+	"tracer":       "callTracer",
+	"tracerConfig": map[string]interface{}{"withLog": true},
+}
+
+// txCallMsg builds the call message of the signed tx, it's used to simulate the tx
+func txCallMsg(tx *types.Transaction, from common.Address) ethereum.CallMsg {
+	msg := ethereum.CallMsg{
+		From:  from,
+		To:    tx.To(),
+		Gas:   tx.Gas(),
+		Value: tx.Value(),
+		Data:  tx.Data(),
+	}
+	if tx.Type() == types.LegacyTxType {
+		msg.GasPrice = tx.GasPrice()
+	} else {
+		msg.GasFeeCap, msg.GasTipCap = tx.GasFeeCap(), tx.GasTipCap()
+		msg.AccessList = tx.AccessList()
+	}
+	return msg
+}
+
+// simulateTx executes msg by eth_call at pending state, and by debug_traceCall if the node supports it to get the
+// gas used, event logs and balance deltas. If msg.Gas is 0 (e.g. the gas estimation of tx fails), the gas limit of
+// the latest block is used.
+func simulateTx(rpcClient *rpc.Client, msg ethereum.CallMsg, decoder *eventDecoder) (*SimulationOutput, error) {
+	var latestBlock struct {
+		BaseFee  *hexutil.Big   `json:"baseFeePerGas"`
+		GasLimit hexutil.Uint64 `json:"gasLimit"`
+	}
+	if err := rpcClient.CallContext(context.Background(), &latestBlock, "eth_getBlockByNumber", "latest", false); err != nil {
+		return nil, fmt.Errorf("eth_getBlockByNumber fail: %w", err)
+	}
+	if msg.Gas == 0 {
+		msg.Gas = uint64(latestBlock.GasLimit)
+	}
+	from := msg.From
+
+	var out = &SimulationOutput{
+		Success:       true,
+		GasLimit:      msg.Gas,
+		Logs:          []*DecodedLogOutput{},
+		BalanceDeltas: []BalanceDelta{},
+	}
+
+	var result hexutil.Bytes
+	if err := rpcClient.CallContext(context.Background(), &result, "eth_call", toCallArg(msg), "pending"); err != nil {
+		out.Success = false
+		if data, ok := revertDataFromError(err); ok {
+			out.RevertReason = decodeRevertData(data, revertErrorsABI, GetFuncSig)
+		} else {
+			// not a revert, e.g. insufficient funds for gas * price + value
+			out.RevertReason = err.Error()
+		}
+	}
+
+	// the frames of value transfer and the logs, they are empty if the tx fails
+	var frames []*callFrame
+	var logs []*types.Log
+
+	var frame callFrame
+	if err := rpcClient.CallContext(context.Background(), &frame, "debug_traceCall", toCallArg(msg), "pending", callTracerConfig); err != nil {
+		log.Printf("debug_traceCall is not available (%v), gas used is estimated and logs are unknown", err)
+		if out.Success {
+			var gas hexutil.Uint64
+			if err := rpcClient.CallContext(context.Background(), &gas, "eth_estimateGas", toCallArg(msg), "pending"); err != nil {
+				return nil, fmt.Errorf("eth_estimateGas fail: %w", wrapRevertError(err))
+			}
+			out.GasUsed = uint64(gas)
+			// only the value of tx is known without trace
+			frames = []*callFrame{{Type: "CALL", From: from, To: msg.To, Value: (*hexutil.Big)(msg.Value)}}
+		}
+	} else {
+		out.Traced = true
+		out.GasUsed = uint64(frame.GasUsed)
+		if frame.Error == "" {
+			frames = []*callFrame{&frame}
+			for i, l := range collectCallLogs(&frame) {
+				logs = append(logs, &types.Log{Address: l.Address, Topics: l.Topics, Data: l.Data, Index: uint(i)})
+			}
+			for _, l := range logs {
+				out.Logs = append(out.Logs, decoder.decode(l))
+			}
+		}
+	}
+
+	// the fee is charged even if the tx fails, the base fee of latest block is used as an approximation
+	fee := new(big.Int).Mul(effectiveGasPrice(msg, latestBlock.BaseFee.ToInt()), new(big.Int).SetUint64(out.GasUsed))
+	out.Fee = fee.String()
+
+	out.BalanceDeltas = buildBalanceDeltas(from, fee, frames, logs)
+	return out, nil
+}
+
+// effectiveGasPrice returns the gas price paid by msg at baseFee, it's baseFee if msg has no gas price
+func effectiveGasPrice(msg ethereum.CallMsg, baseFee *big.Int) *big.Int {
+	if baseFee == nil {
+		baseFee = new(big.Int)
+	}
+	if msg.GasPrice != nil {
+		return msg.GasPrice
+	}
+	if msg.GasFeeCap == nil {
+		return baseFee
+	}
+	tip := new(big.Int).Sub(msg.GasFeeCap, baseFee)
+	if msg.GasTipCap != nil && msg.GasTipCap.Cmp(tip) < 0 {
+		tip = msg.GasTipCap
+	}
+	return new(big.Int).Add(baseFee, tip)
+}
+
+// collectCallLogs collects the logs of frame and its sub calls in the order of execution
+func collectCallLogs(frame *callFrame) []*callLog {
+	var logs []*callLog
+	var next = 0
+	for i, sub := range frame.Calls {
+		for next < len(frame.Logs) && int(frame.Logs[next].Position) <= i {
+			logs = append(logs, frame.Logs[next])
+			next++
+		}
+		if sub.Error == "" {
+			logs = append(logs, collectCallLogs(sub)...)
+		}
+	}
+	return append(logs, frame.Logs[next:]...)
+}
+
+// buildBalanceDeltas computes the native balance deltas by the fee and the value of successful call frames, and
+// the ERC20 balance deltas by Transfer events. The deltas of sender are listed first.
+func buildBalanceDeltas(sender common.Address, fee *big.Int, frames []*callFrame, logs []*types.Log) []BalanceDelta {
+	var deltas = []BalanceDelta{}
+	deltas = addBalanceDelta(deltas, sender, "", new(big.Int).Neg(fee))
+
+	var walk func(frames []*callFrame)
+	walk = func(frames []*callFrame) {
+		for _, frame := range frames {
+			if frame.Error != "" {
+				continue
+			}
+			if frame.Value != nil && frame.Value.ToInt().Sign() > 0 && frame.To != nil &&
+				frame.Type != "DELEGATECALL" && frame.Type != "STATICCALL" && frame.Type != "CALLCODE" {
+				deltas = addBalanceDelta(deltas, frame.From, "", new(big.Int).Neg(frame.Value.ToInt()))
+				deltas = addBalanceDelta(deltas, *frame.To, "", frame.Value.ToInt())
+			}
+			walk(frame.Calls)
+		}
+	}
+	walk(frames)
+
+	for _, l := range logs {
+		// ERC721 Transfer has the same topic0, but its tokenId is indexed
+		if len(l.Topics) != 3 || l.Topics[0] != erc20TransferTopic || len(l.Data) != 32 {
+			continue
+		}
+		amount := new(big.Int).SetBytes(l.Data)
+		token := l.Address.Hex()
+		deltas = addBalanceDelta(deltas, common.BytesToAddress(l.Topics[1].Bytes()), token, new(big.Int).Neg(amount))
+		deltas = addBalanceDelta(deltas, common.BytesToAddress(l.Topics[2].Bytes()), token, amount)
+	}
+
+	// drop zero deltas, e.g. a transfer to self
+	var rc = []BalanceDelta{}
+	for _, delta := range deltas {
+		if delta.Delta != "0" {
+			rc = append(rc, delta)
+		}
+	}
+	return rc
+}
+
+// addBalanceDelta adds amount to the delta of addr in token (empty for native currency)
+func addBalanceDelta(deltas []BalanceDelta, addr common.Address, token string, amount *big.Int) []BalanceDelta {
+	for i, delta := range deltas {
+		if delta.Address == addr.Hex() && delta.Token == token {
+			current, _ := new(big.Int).SetString(delta.Delta, 10)
+			deltas[i].Delta = current.Add(current, amount).String()
+			return deltas
+		}
+	}
+	return append(deltas, BalanceDelta{Address: addr.Hex(), Token: token, Delta: amount.String()})
+}
+
+// printSimulation prints the result of simulation in human-readable format
+func printSimulation(w io.Writer, out *SimulationOutput) {
+	if out.Success {
+		fmt.Fprintf(w, "simulation: success\n")
+	} else {
+		fmt.Fprintf(w, "simulation: failed\n")
+		fmt.Fprintf(w, "revert reason: %s\n", out.RevertReason)
+	}
+	if out.Traced {
+		fmt.Fprintf(w, "gas used: %d / %d (gas limit)\n", out.GasUsed, out.GasLimit)
+	} else {
+		fmt.Fprintf(w, "gas used (estimated): %d / %d (gas limit)\n", out.GasUsed, out.GasLimit)
+	}
+	if fee, err := decimal.NewFromString(out.Fee); err == nil {
+		fmt.Fprintf(w, "fee: %s %s (%s wei)\n", wei2Other(fee, unitEther).String(), currentNativeCurrency().Symbol, out.Fee)
+	}
+
+	if out.Traced {
+		fmt.Fprintf(w, "logs: %d\n", len(out.Logs))
+		printDecodedLogs(w, out.Logs)
+	}
+
+	fmt.Fprintf(w, "balance deltas:\n")
+	for _, delta := range out.BalanceDeltas {
+		if delta.Token == "" {
+			amount, _ := decimal.NewFromString(delta.Delta)
+			fmt.Fprintf(w, "  %s %s %s\n", delta.Address, wei2Other(amount, unitEther).String(), currentNativeCurrency().Symbol)
+		} else {
+			fmt.Fprintf(w, "  %s %s (token %s)\n", delta.Address, delta.Delta, delta.Token)
+		}
+	}
+}
@@ -0,0 +1,156 @@
+package cmd
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// simulateEth is a stub of eth namespace for simulation, eth_call reverts with revertData if it's not empty
+type simulateEth struct {
+	revertData string
+	callGas    string // the gas of last eth_call
+}
+
+func (s *simulateEth) Call(args map[string]interface{}, block string) (hexutil.Bytes, error) {
+	if block != "pending" {
+		return nil, fmt.Errorf("expect pending block, got %s", block)
+	}
+	s.callGas, _ = args["gas"].(string)
+	if s.revertData != "" {
+		return nil, &stubRevertError{data: s.revertData}
+	}
+	return hexutil.Bytes{}, nil
+}
+
+func (s *simulateEth) EstimateGas(args map[string]interface{}, block *string) (hexutil.Uint64, error) {
+	if s.revertData != "" {
+		return 0, &stubRevertError{data: s.revertData}
+	}
+	return 60000, nil
+}
+
+func (s *simulateEth) GetBlockByNumber(block string, fullTx bool) (map[string]interface{}, error) {
+	return map[string]interface{}{"baseFeePerGas": "0x2540be400", "gasLimit": "0x1c9c380"}, nil // 10 gwei, 30M gas
+}
+
+func (s *simulateEth) GetTransactionCount(address common.Address, block string) (hexutil.Uint64, error) {
+	return 1, nil
+}
+
+// simulateDebug is a stub of debug namespace, debug_traceCall returns the frame
+type simulateDebug struct {
+	frame *callFrame
+}
+
+func (s *simulateDebug) TraceCall(args map[string]interface{}, block string, config map[string]interface{}) (*callFrame, error) {
+	return s.frame, nil
+}
+
+func TestSimulateTx(t *testing.T) {
+	sender := common.HexToAddress("0x8F36975cdeA2e6E64f85719788C8EFBBe89DFBbb")
+	router := common.HexToAddress("0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D")
+	recipient := common.HexToAddress("0x703662E526D2b71944FBFB9D87f61de3e0F0F290")
+	token := common.HexToAddress("0xdAC17F958D2ee523a2206206994597C13D831ec7")
+
+	tx := types.NewTx(&types.DynamicFeeTx{ChainID: big.NewInt(1), Nonce: 1, GasTipCap: big.NewInt(1e9), GasFeeCap: big.NewInt(100e9),
+		Gas: 100000, To: &router, Value: big.NewInt(1e18), Data: []byte{1, 2, 3, 4}})
+
+	transferLog := func(position uint) *callLog {
+		return &callLog{
+			Address:  token,
+			Topics:   []common.Hash{erc20TransferTopic, common.BytesToHash(sender.Bytes()), common.BytesToHash(recipient.Bytes())},
+			Data:     common.LeftPadBytes(big.NewInt(500).Bytes(), 32),
+			Position: hexutil.Uint(position),
+		}
+	}
+	tracedFrame := &callFrame{
+		Type: "CALL", From: sender, To: &router, Value: (*hexutil.Big)(big.NewInt(1e18)), GasUsed: 50000,
+		Calls: []*callFrame{
+			{Type: "CALL", From: router, To: &token, Logs: []*callLog{transferLog(0)}},
+			{Type: "CALL", From: router, To: &recipient, Value: (*hexutil.Big)(big.NewInt(4e17))},
+			{Type: "CALL", From: router, To: &recipient, Value: (*hexutil.Big)(big.NewInt(1e17)), Error: "execution reverted", Logs: []*callLog{transferLog(0)}},
+		},
+		Logs: []*callLog{{Address: router, Topics: []common.Hash{common.HexToHash("0x01")}, Position: 1}},
+	}
+
+	tests := []struct {
+		revertData     string
+		frame          *callFrame // nil if debug_traceCall is not available
+		success        bool
+		revertReason   string
+		gasUsed        uint64
+		logAddresses   []string
+		expectedDeltas []BalanceDelta
+	}{
+		{"", tracedFrame, true, "", 50000,
+			[]string{token.Hex(), router.Hex()},
+			[]BalanceDelta{
+				{sender.Hex(), "", "-1000550000000000000"}, // value + fee (50000 * 11 gwei)
+				{router.Hex(), "", "600000000000000000"},
+				{recipient.Hex(), "", "400000000000000000"},
+				{sender.Hex(), token.Hex(), "-500"},
+				{recipient.Hex(), token.Hex(), "500"},
+			}},
+		{"", nil, true, "", 60000, nil,
+			[]BalanceDelta{
+				{sender.Hex(), "", "-1000660000000000000"},
+				{router.Hex(), "", "1000000000000000000"},
+			}},
+		{"0x08c379a0" +
+			"0000000000000000000000000000000000000000000000000000000000000020" +
+			"0000000000000000000000000000000000000000000000000000000000000014" +
+			"696e73756666696369656e742062616c616e6365000000000000000000000000",
+			&callFrame{Type: "CALL", From: sender, To: &router, GasUsed: 30000, Error: "execution reverted", Logs: []*callLog{transferLog(0)}},
+			false, "insufficient balance", 30000, nil,
+			[]BalanceDelta{{sender.Hex(), "", "-330000000000000"}}},
+	}
+
+	for i, tc := range tests {
+		var services = map[string]any{"eth": &simulateEth{revertData: tc.revertData}}
+		if tc.frame != nil {
+			services["debug"] = &simulateDebug{frame: tc.frame}
+		}
+		rpcClient := dialStubRpc(t, services)
+
+		out, err := simulateTx(rpcClient, txCallMsg(tx, sender), newEventDecoder(nil))
+		if err != nil {
+			t.Fatalf("test %d: simulateTx failed: %v", i+1, err)
+		}
+		if out.Success != tc.success || out.RevertReason != tc.revertReason || out.GasUsed != tc.gasUsed || out.Traced != (tc.frame != nil) {
+			t.Fatalf("test %d: expected: %v %v %v, got: %v %v %v", i+1, tc.success, tc.revertReason, tc.gasUsed, out.Success, out.RevertReason, out.GasUsed)
+		}
+		var logAddresses []string
+		for _, l := range out.Logs {
+			logAddresses = append(logAddresses, l.Address)
+		}
+		if !reflect.DeepEqual(logAddresses, tc.logAddresses) {
+			t.Fatalf("test %d: expected logs: %v, got: %v", i+1, tc.logAddresses, logAddresses)
+		}
+		if !reflect.DeepEqual(out.BalanceDeltas, tc.expectedDeltas) {
+			t.Fatalf("test %d: expected: %+v, got: %+v", i+1, tc.expectedDeltas, out.BalanceDeltas)
+		}
+	}
+}
+
+func TestSimulateTxWithoutGasLimit(t *testing.T) {
+	router := common.HexToAddress("0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D")
+	stub := &simulateEth{revertData: "0x"}
+	rpcClient := dialStubRpc(t, map[string]any{"eth": stub})
+
+	// the gas estimation of tx fails, the gas limit of latest block is used to simulate it
+	msg := ethereum.CallMsg{From: common.HexToAddress("0x8F36975cdeA2e6E64f85719788C8EFBBe89DFBbb"), To: &router, Value: big.NewInt(0), Data: []byte{1, 2, 3, 4}}
+	out, err := simulateTx(rpcClient, msg, newEventDecoder(nil))
+	if err != nil {
+		t.Fatalf("simulateTx failed: %v", err)
+	}
+	if out.Success || out.RevertReason != "no reason" || out.GasLimit != 30000000 || stub.callGas != "0x1c9c380" {
+		t.Fatalf("expected: failure with gas limit 30000000, got: %+v (gas of eth_call %v)", out, stub.callGas)
+	}
+}
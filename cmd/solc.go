@@ -0,0 +1,404 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/spf13/cobra"
+)
+
+var compilerSolc string
+var compilerOptimize bool
+var compilerOptimizeRuns int
+var compilerEvmVersion string
+var compilerViaIR bool
+var compilerRemappings []string
+
+// addCompilerFlags adds the flags of compiler settings to cmd
+func addCompilerFlags(cmd *cobra.Command) {
+	cmd.Flags().StringVarP(&compilerSolc, "solc", "", "", "the native solc binary to compile --src-file or --standard-json, e.g. solc or /path/to/solc-0.8.28. If it's not specified, solcjs is used")
+	cmd.Flags().BoolVarP(&compilerOptimize, "optimize", "", false, "enable optimizer of compiler")
+	cmd.Flags().IntVarP(&compilerOptimizeRuns, "optimize-runs", "", 200, "the number of runs of optimizer, it implies --optimize")
+	cmd.Flags().StringVarP(&compilerEvmVersion, "evm-version", "", "", "the evm version of compiler, e.g. paris, shanghai, cancun. Default is the default of compiler")
+	cmd.Flags().BoolVarP(&compilerViaIR, "via-ir", "", false, "compile through the IR pipeline of compiler")
+	cmd.Flags().StringArrayVarP(&compilerRemappings, "remapping", "", nil, "import remapping of compiler, format: prefix=path, e.g. @openzeppelin/=lib/openzeppelin-contracts/, can be repeated")
+}
+
+// compilerSettings returns the compiler settings specified by the flags of addCompilerFlags
+func compilerSettings(cmd *cobra.Command) solcSettings {
+	var settings = solcSettings{evmVersion: compilerEvmVersion, remappings: compilerRemappings}
+	if cmd.Flags().Changed("optimize") || cmd.Flags().Changed("optimize-runs") {
+		var optimize = compilerOptimize || cmd.Flags().Changed("optimize-runs")
+		settings.optimize = &optimize
+		settings.optimizeRuns = &compilerOptimizeRuns
+	}
+	if cmd.Flags().Changed("via-ir") {
+		settings.viaIR = &compilerViaIR
+	}
+	return settings
+}
+
+// solcSettings is the compiler settings from command line, nil fields are not specified
+type solcSettings struct {
+	optimize     *bool
+	optimizeRuns *int
+	evmVersion   string
+	viaIR        *bool
+	remappings   []string
+}
+
+// solcOutputSelection selects the outputs required by deploy, verify and code diff
+var solcOutputSelection = map[string]interface{}{
+	"*": map[string]interface{}{
+		"*": []string{"abi", "evm.bytecode.object", "evm.bytecode.linkReferences",
+			"evm.deployedBytecode.object", "evm.deployedBytecode.linkReferences", "evm.deployedBytecode.immutableReferences"},
+	},
+}
+
+// solcLinkReference is the position of a library address placeholder in bytecode, in bytes
+type solcLinkReference struct {
+	Start  int `json:"start"`
+	Length int `json:"length"`
+}
+
+// solcContract is a contract in the output of solc --standard-json
+type solcContract struct {
+	ABI json.RawMessage `json:"abi"`
+	EVM struct {
+		Bytecode struct {
+			Object         string                                    `json:"object"`
+			LinkReferences map[string]map[string][]solcLinkReference `json:"linkReferences"`
+		} `json:"bytecode"`
+		DeployedBytecode struct {
+			Object              string                                    `json:"object"`
+			LinkReferences      map[string]map[string][]solcLinkReference `json:"linkReferences"`
+			ImmutableReferences map[string][]solcLinkReference            `json:"immutableReferences"` // ast id -> positions
+		} `json:"deployedBytecode"`
+	} `json:"evm"`
+}
+
+// solcOutput is the output of solc --standard-json
+type solcOutput struct {
+	Errors []struct {
+		Severity         string `json:"severity"` // error | warning | info
+		FormattedMessage string `json:"formattedMessage"`
+		Message          string `json:"message"`
+	} `json:"errors"`
+	Sources   map[string]struct{}                 `json:"sources"`   // source unit names of all compiled sources, including imports
+	Contracts map[string]map[string]*solcContract `json:"contracts"` // source unit name -> contract name -> contract
+}
+
+// compiledContract is a contract selected from the output of compiler
+type compiledContract struct {
+	File     string // the source unit name
+	Name     string
+	ABI      []byte
+	Bytecode string // hex without 0x prefix, library placeholders are not linked
+	// LinkReferences is the positions of library placeholders, source unit name -> library name -> positions
+	LinkReferences map[string]map[string][]solcLinkReference
+	// DeployedBytecode is the runtime bytecode, its library placeholders and immutables are at the positions of
+	// DeployedLinkReferences and ImmutableReferences
+	DeployedBytecode       string
+	DeployedLinkReferences map[string]map[string][]solcLinkReference
+	ImmutableReferences    map[string][]solcLinkReference
+}
+
+// buildStandardJsonInput builds the standard json input of solc to compile srcFile
+func buildStandardJsonInput(srcFile string, settings solcSettings) ([]byte, error) {
+	content, err := os.ReadFile(srcFile)
+	if err != nil {
+		return nil, err
+	}
+	var input = map[string]interface{}{
+		"language": "Solidity",
+		"sources": map[string]interface{}{
+			sourceUnitName(srcFile): map[string]interface{}{"content": string(content)},
+		},
+		"settings": map[string]interface{}{},
+	}
+	return applySolcSettings(input, settings)
+}
+
+// loadStandardJsonInput reads the standard json input file, and applies the specified settings
+func loadStandardJsonInput(file string, settings solcSettings) ([]byte, error) {
+	content, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+	var input map[string]interface{}
+	if err := json.Unmarshal(content, &input); err != nil {
+		return nil, fmt.Errorf("parse %s fail: %w", file, err)
+	}
+	return applySolcSettings(input, settings)
+}
+
+// applySolcSettings applies the specified settings to the standard json input, the output selection is always
+// replaced by solcOutputSelection
+func applySolcSettings(input map[string]interface{}, settings solcSettings) ([]byte, error) {
+	s, ok := input["settings"].(map[string]interface{})
+	if !ok {
+		s = map[string]interface{}{}
+		input["settings"] = s
+	}
+	if settings.optimize != nil || settings.optimizeRuns != nil {
+		optimizer, ok := s["optimizer"].(map[string]interface{})
+		if !ok {
+			optimizer = map[string]interface{}{}
+			s["optimizer"] = optimizer
+		}
+		if settings.optimize != nil {
+			optimizer["enabled"] = *settings.optimize
+		}
+		if settings.optimizeRuns != nil {
+			optimizer["runs"] = *settings.optimizeRuns
+		}
+	}
+	if settings.evmVersion != "" {
+		s["evmVersion"] = settings.evmVersion
+	}
+	if settings.viaIR != nil {
+		s["viaIR"] = *settings.viaIR
+	}
+	if len(settings.remappings) > 0 {
+		s["remappings"] = settings.remappings
+	}
+	s["outputSelection"] = solcOutputSelection
+	return json.Marshal(input)
+}
+
+// sourceUnitName returns the source unit name of file relative to base path (current directory)
+func sourceUnitName(file string) string {
+	return filepath.ToSlash(filepath.Clean(file))
+}
+
+// compileStandardJson compiles the standard json input by native solc, or by solcjs if solc is empty.
+// Imports are resolved relative to current directory and ./node_modules.
+func compileStandardJson(solc string, input []byte) (*solcOutput, error) {
+	var compiler = solc
+	if compiler == "" {
+		compiler = "solcjs"
+	}
+	var args = []string{"--standard-json", "--base-path", "."}
+	if info, err := os.Stat("node_modules"); err == nil && info.IsDir() {
+		args = append(args, "--include-path", "node_modules")
+	}
+
+	cmd := exec.Command(compiler, args...)
+	log.Printf("executing command %v", cmd.String())
+	var stdout bytes.Buffer
+	var stderr bytes.Buffer
+	cmd.Stdin = bytes.NewReader(input)
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("%v: %v", err, stderr.String())
+	}
+	return parseSolcOutput(stdout.Bytes())
+}
+
+// parseSolcOutput parses the output of solc --standard-json, the warnings are logged and the errors are returned
+func parseSolcOutput(content []byte) (*solcOutput, error) {
+	var output solcOutput
+	if err := json.Unmarshal(content, &output); err != nil {
+		return nil, fmt.Errorf("parse output of compiler fail: %w", err)
+	}
+	var errs []string
+	for _, e := range output.Errors {
+		message := e.FormattedMessage
+		if message == "" {
+			message = e.Message
+		}
+		if e.Severity == "error" {
+			errs = append(errs, message)
+		} else {
+			log.Printf("%s", strings.TrimSpace(message))
+		}
+	}
+	if len(errs) > 0 {
+		return nil, fmt.Errorf("compile fail:\n%s", strings.Join(errs, "\n"))
+	}
+	return &output, nil
+}
+
+// compileContract compiles srcFile or standardJsonFile with the compiler settings, and selects the contract by
+// contractName. The standard json input and the output of compiler are also returned.
+func compileContract(settings solcSettings, srcFile string, standardJsonFile string, contractName string) (*compiledContract, []byte, *solcOutput, error) {
+	var input []byte
+	var err error
+	var mainFile = ""
+	if srcFile != "" {
+		input, err = buildStandardJsonInput(srcFile, settings)
+		mainFile = srcFile
+	} else {
+		input, err = loadStandardJsonInput(standardJsonFile, settings)
+	}
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	output, err := compileStandardJson(compilerSolc, input)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	contract, err := selectContract(output, contractName, mainFile)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	return contract, input, output, nil
+}
+
+// selectContract selects the contract by name, the name is "Name" or "file.sol:Name". If name is empty, the only
+// deployable contract is selected, or the last contract of mainFile (if it's not empty) for compatibility.
+func selectContract(output *solcOutput, name string, mainFile string) (*compiledContract, error) {
+	var candidates []*compiledContract
+	for file, contracts := range output.Contracts {
+		for contractName, contract := range contracts {
+			// interfaces and abstract contracts are not deployable
+			if contract.EVM.Bytecode.Object == "" {
+				continue
+			}
+			candidates = append(candidates, &compiledContract{
+				File:           file,
+				Name:           contractName,
+				ABI:            contract.ABI,
+				Bytecode:       contract.EVM.Bytecode.Object,
+				LinkReferences: contract.EVM.Bytecode.LinkReferences,
+
+				DeployedBytecode:       contract.EVM.DeployedBytecode.Object,
+				DeployedLinkReferences: contract.EVM.DeployedBytecode.LinkReferences,
+				ImmutableReferences:    contract.EVM.DeployedBytecode.ImmutableReferences,
+			})
+		}
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].File+":"+candidates[i].Name < candidates[j].File+":"+candidates[j].Name
+	})
+
+	var fileFilter = ""
+	if name == "" && mainFile != "" {
+		fileFilter = sourceUnitName(mainFile)
+		name = findContractName(mainFile)
+		if name != "" {
+			log.Printf("option --contract-name is not specified, use last contract %v in source file", name)
+		}
+	} else if i := strings.LastIndex(name, ":"); i >= 0 {
+		fileFilter = sourceUnitName(name[:i])
+		name = name[i+1:]
+	}
+
+	var matches []*compiledContract
+	var names []string
+	for _, c := range candidates {
+		names = append(names, c.File+":"+c.Name)
+		if fileFilter != "" && c.File != fileFilter && !strings.HasSuffix(c.File, "/"+fileFilter) {
+			continue
+		}
+		if name != "" && c.Name != name {
+			continue
+		}
+		matches = append(matches, c)
+	}
+
+	if len(matches) == 0 {
+		return nil, fmt.Errorf("contract %s not found, deployable contracts: %s", name, strings.Join(names, ", "))
+	}
+	if len(matches) > 1 {
+		var ambiguous []string
+		for _, c := range matches {
+			ambiguous = append(ambiguous, c.File+":"+c.Name)
+		}
+		return nil, fmt.Errorf("more than one contracts found: %s, specify one by --contract-name file.sol:Name", strings.Join(ambiguous, ", "))
+	}
+	return matches[0], nil
+}
+
+// parseLibraries parses items of --library, format: <[file.sol:]Name>=<address>
+func parseLibraries(items []string) (map[string]common.Address, error) {
+	var libraries = make(map[string]common.Address)
+	for _, item := range items {
+		name, addr, found := strings.Cut(item, "=")
+		if !found || name == "" || !isValidEthAddress(addr) {
+			return nil, fmt.Errorf("invalid --library %s, format is <[file.sol:]Name>=<address>", item)
+		}
+		libraries[name] = common.HexToAddress(addr)
+	}
+	return libraries, nil
+}
+
+// unlinkedPlaceholderRegex matches the library placeholders in bytecode, __$<34 hex chars>$__ since solc 0.5.0
+// and __<file:Name padded to 36 chars>__ before
+var unlinkedPlaceholderRegex = regexp.MustCompile(`__\$[0-9a-fA-F]{34}\$__|__[^_][^\s]{34}__`)
+
+// linkBytecode replaces the library placeholders in bytecode (hex without 0x prefix) with the addresses. The
+// positions in linkReferences are used if present, otherwise the placeholders are computed from the names of
+// libraries, which must be fully qualified (file.sol:Name) for solc >= 0.5.0.
+func linkBytecode(bytecode string, linkReferences map[string]map[string][]solcLinkReference, libraries map[string]common.Address) (string, error) {
+	var linked = []byte(bytecode)
+
+	for file, libs := range linkReferences {
+		for lib, refs := range libs {
+			addr, ok := libraries[file+":"+lib]
+			if !ok {
+				addr, ok = libraries[lib]
+			}
+			if !ok {
+				return "", fmt.Errorf("library %s:%s is not linked, specify its address by --library %s:%s=<address>", file, lib, file, lib)
+			}
+			addrHex := strings.ToLower(remove0xPrefix(addr.Hex()))
+			for _, ref := range refs {
+				if ref.Length != common.AddressLength || ref.Start < 0 || (ref.Start+ref.Length)*2 > len(linked) {
+					return "", fmt.Errorf("invalid link reference of library %s:%s", file, lib)
+				}
+				copy(linked[ref.Start*2:], addrHex)
+			}
+		}
+	}
+
+	var result = string(linked)
+	for name, addr := range libraries {
+		addrHex := strings.ToLower(remove0xPrefix(addr.Hex()))
+		placeholder := "__$" + common.Bytes2Hex(crypto.Keccak256([]byte(name)))[:34] + "$__"
+		result = strings.ReplaceAll(result, placeholder, addrHex)
+		if len(name) <= 36 {
+			legacyPlaceholder := "__" + name + strings.Repeat("_", 36-len(name)) + "__"
+			result = strings.ReplaceAll(result, legacyPlaceholder, addrHex)
+		}
+	}
+
+	if unlinked := unlinkedPlaceholderRegex.FindAllString(result, -1); len(unlinked) > 0 {
+		return "", fmt.Errorf("bytecode contains unlinked library placeholders %v, specify the addresses of libraries by --library", unlinked)
+	}
+	return result, nil
+}
+
+// linkedLibraries returns the addresses of libraries in linkReferences, in the format of settings.libraries of
+// standard json input: source unit name -> library name -> address
+func linkedLibraries(linkReferences map[string]map[string][]solcLinkReference, libraries map[string]common.Address) map[string]map[string]string {
+	var linked = make(map[string]map[string]string)
+	for file, libs := range linkReferences {
+		for lib := range libs {
+			addr, ok := libraries[file+":"+lib]
+			if !ok {
+				addr, ok = libraries[lib]
+			}
+			if !ok {
+				continue
+			}
+			if linked[file] == nil {
+				linked[file] = make(map[string]string)
+			}
+			linked[file][lib] = addr.Hex()
+		}
+	}
+	return linked
+}
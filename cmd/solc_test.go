@@ -0,0 +1,134 @@
+package cmd
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestBuildStandardJsonInput(t *testing.T) {
+	srcFile := filepath.Join(t.TempDir(), "Counter.sol")
+	if err := os.WriteFile(srcFile, []byte("contract Counter {}"), 0644); err != nil {
+		t.Fatalf("WriteFile failed: %v", err)
+	}
+
+	runs := 1000
+	viaIR := true
+	input, err := buildStandardJsonInput(srcFile, solcSettings{optimizeRuns: &runs, evmVersion: "paris", viaIR: &viaIR, remappings: []string{"@oz/=lib/oz/"}})
+	if err != nil {
+		t.Fatalf("buildStandardJsonInput failed: %v", err)
+	}
+
+	var got struct {
+		Language string                       `json:"language"`
+		Sources  map[string]map[string]string `json:"sources"`
+		Settings map[string]interface{}       `json:"settings"`
+	}
+	if err := json.Unmarshal(input, &got); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	if got.Language != "Solidity" || got.Sources[sourceUnitName(srcFile)]["content"] != "contract Counter {}" {
+		t.Fatalf("unexpected sources: %s", input)
+	}
+	expected := map[string]interface{}{
+		"optimizer":       map[string]interface{}{"runs": float64(1000)},
+		"evmVersion":      "paris",
+		"viaIR":           true,
+		"remappings":      []interface{}{"@oz/=lib/oz/"},
+		"outputSelection": map[string]interface{}{"*": map[string]interface{}{"*": []interface{}{"abi", "evm.bytecode.object", "evm.bytecode.linkReferences", "evm.deployedBytecode.object", "evm.deployedBytecode.linkReferences", "evm.deployedBytecode.immutableReferences"}}},
+	}
+	if !reflect.DeepEqual(got.Settings, expected) {
+		t.Fatalf("expected: %v, got: %v", expected, got.Settings)
+	}
+}
+
+const testSolcOutput = `{
+  "errors": [{"severity": "warning", "formattedMessage": "Warning: SPDX license identifier not provided"}],
+  "contracts": {
+    "contracts/Token.sol": {
+      "IToken": {"abi": [], "evm": {"bytecode": {"object": "", "linkReferences": {}}}},
+      "Token": {"abi": [{"type": "constructor", "inputs": [{"name": "supply", "type": "uint256"}]}], "evm": {"bytecode": {"object": "6001", "linkReferences": {}}}}
+    },
+    "contracts/Vault.sol": {
+      "Token": {"abi": [], "evm": {"bytecode": {"object": "6002", "linkReferences": {}}}},
+      "Vault": {"abi": [], "evm": {"bytecode": {"object": "6003", "linkReferences": {}}}}
+    }
+  }
+}`
+
+func TestSelectContract(t *testing.T) {
+	output, err := parseSolcOutput([]byte(testSolcOutput))
+	if err != nil {
+		t.Fatalf("parseSolcOutput failed: %v", err)
+	}
+
+	tests := []struct {
+		name     string
+		expected string // bytecode, empty if error is expected
+	}{
+		{"Vault", "6003"},
+		{"contracts/Token.sol:Token", "6001"},
+		{"Vault.sol:Token", "6002"},
+		{"Token", ""},   // ambiguous
+		{"IToken", ""},  // not deployable
+		{"Unknown", ""}, // not found
+		{"", ""},        // more than one deployable contracts
+	}
+
+	for i, tc := range tests {
+		got, err := selectContract(output, tc.name, "")
+		if tc.expected == "" {
+			if err == nil {
+				t.Fatalf("test %d: expect error, got: %v:%v", i+1, got.File, got.Name)
+			}
+			continue
+		}
+		if err != nil || got.Bytecode != tc.expected {
+			t.Fatalf("test %d: expected: %v, got: %+v (%v)", i+1, tc.expected, got, err)
+		}
+	}
+
+	if _, err := parseSolcOutput([]byte(`{"errors": [{"severity": "error", "formattedMessage": "ParserError: Expected ';'"}]}`)); err == nil {
+		t.Fatalf("expect error if compile fails")
+	}
+}
+
+func TestLinkBytecode(t *testing.T) {
+	lib := common.HexToAddress("0x8F36975cdeA2e6E64f85719788C8EFBBe89DFBbb")
+	libHex := "8f36975cdea2e6e64f85719788c8efbbe89dfbbb"
+	placeholder := "__$8993bdaddfdf107d1084f5374a9bc3224a$__"        // placeholder of math.sol:Math
+	legacyPlaceholder := "__math.sol:Math" + strings.Repeat("_", 25) // 40 chars
+
+	tests := []struct {
+		bytecode       string
+		linkReferences map[string]map[string][]solcLinkReference
+		libraries      map[string]common.Address
+		expected       string // empty if error is expected
+	}{
+		{"6001" + placeholder + "6002", map[string]map[string][]solcLinkReference{"math.sol": {"Math": {{Start: 2, Length: 20}}}},
+			map[string]common.Address{"Math": lib}, "6001" + libHex + "6002"},
+		{"6001" + placeholder + "6002" + placeholder, nil, map[string]common.Address{"math.sol:Math": lib}, "6001" + libHex + "6002" + libHex},
+		{"6001" + legacyPlaceholder, nil, map[string]common.Address{"math.sol:Math": lib}, "6001" + libHex},
+		{"6001" + placeholder, nil, map[string]common.Address{"Math": lib}, ""},
+		{"6001" + placeholder, map[string]map[string][]solcLinkReference{"math.sol": {"Math": {{Start: 2, Length: 20}}}}, nil, ""},
+		{"6001", nil, nil, "6001"},
+	}
+
+	for i, tc := range tests {
+		got, err := linkBytecode(tc.bytecode, tc.linkReferences, tc.libraries)
+		if tc.expected == "" {
+			if err == nil {
+				t.Fatalf("test %d: expect error, got: %v", i+1, got)
+			}
+			continue
+		}
+		if err != nil || got != tc.expected {
+			t.Fatalf("test %d: expected: %v, got: %v (%v)", i+1, tc.expected, got, err)
+		}
+	}
+}
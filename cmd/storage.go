@@ -0,0 +1,147 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/spf13/cobra"
+)
+
+var storageCmdKeys []string
+var storageCmdIndex int64
+var storageCmdElementSlots int64
+
+func init() {
+	storageCmd.Flags().StringArrayVarP(&storageCmdKeys, "key", "", nil, "the mapping key, format: [type:]value, e.g. address:0x.., uint256:1, bytes32:0x.., string:abc. Type is inferred if omitted (address, bytes32 or uint256). Repeat it for nested mappings")
+	storageCmd.Flags().Int64VarP(&storageCmdIndex, "index", "", -1, "the index of dynamic array element, it's applied after --key")
+	storageCmd.Flags().Int64VarP(&storageCmdElementSlots, "element-slots", "", 1, "the number of slots of each dynamic array element, it's used with --index")
+}
+
+// StorageOutput is the json output (--output json) of storage command
+type StorageOutput struct {
+	Address   string `json:"address"`
+	Slot      string `json:"slot"` // the slot derived by --key and --index
+	Value     string `json:"value"`
+	AsUint256 string `json:"asUint256"`
+	AsAddress string `json:"asAddress,omitempty"` // only if the value looks like an address
+}
+
+var storageCmd = &cobra.Command{
+	Use:   "storage <address> <slot>",
+	Short: "Read storage slot of contract, the slot of mapping value and array element can be derived",
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 2 {
+			return fmt.Errorf("requires address and slot")
+		}
+		if !isValidEthAddress(args[0]) {
+			return fmt.Errorf("%v is not a valid eth address", args[0])
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		baseSlot, err := ParseBigInt(args[1])
+		checkErr(err)
+		if storageCmdElementSlots <= 0 {
+			checkErr(fmt.Errorf("--element-slots must be positive"))
+		}
+
+		slot, err := deriveStorageSlot(common.BigToHash(baseSlot), storageCmdKeys, storageCmdIndex, storageCmdElementSlots)
+		checkErr(err)
+
+		InitGlobalClient(globalOptNodeUrl)
+		value, err := storageAt(globalClient.RpcClient, common.HexToAddress(args[0]), slot)
+		checkErr(err)
+
+		var out = StorageOutput{
+			Address:   args[0],
+			Slot:      slot.Hex(),
+			Value:     value.Hex(),
+			AsUint256: value.Big().String(),
+		}
+		if value.Big().Sign() > 0 && value.Big().BitLen() <= 160 {
+			out.AsAddress = common.BytesToAddress(value.Bytes()).Hex()
+		}
+
+		if isJsonOutput() {
+			printJson(out)
+			return
+		}
+		if globalOptTerseOutput {
+			fmt.Println(out.Value)
+			return
+		}
+		fmt.Printf("slot: %s\n", out.Slot)
+		fmt.Printf("value: %s\n", out.Value)
+		fmt.Printf("as uint256: %s\n", out.AsUint256)
+		if out.AsAddress != "" {
+			fmt.Printf("as address: %s\n", out.AsAddress)
+		}
+	},
+}
+
+// storageAt returns the value of storage slot at the block of --block
+func storageAt(rpcClient *rpc.Client, address common.Address, slot common.Hash) (common.Hash, error) {
+	var result hexutil.Bytes
+	if err := rpcClient.CallContext(context.Background(), &result, "eth_getStorageAt", address, slot, blockArg()); err != nil {
+		return common.Hash{}, err
+	}
+	return common.BytesToHash(result), nil
+}
+
+// deriveStorageSlot derives the slot by the solidity storage layout, the mapping keys are applied in order,
+// then the index of dynamic array if it's not negative.
+// See https://docs.soliditylang.org/en/latest/internals/layout_in_storage.html#mappings-and-dynamic-arrays
+func deriveStorageSlot(slot common.Hash, keys []string, index int64, elementSlots int64) (common.Hash, error) {
+	for _, key := range keys {
+		encodedKey, err := encodeMappingKey(key)
+		if err != nil {
+			return common.Hash{}, fmt.Errorf("invalid --key %s: %w", key, err)
+		}
+		// the value of mapping key k is at keccak256(h(k) . p)
+		slot = crypto.Keccak256Hash(encodedKey, slot.Bytes())
+	}
+
+	if index >= 0 {
+		// the elements of dynamic array start at keccak256(p)
+		start := crypto.Keccak256Hash(slot.Bytes()).Big()
+		offset := new(big.Int).Mul(big.NewInt(index), big.NewInt(elementSlots))
+		element := new(big.Int).Add(start, offset)
+		// the slot wraps around 2^256
+		element.And(element, new(big.Int).Sub(new(big.Int).Lsh(big.NewInt(1), 256), big.NewInt(1)))
+		slot = common.BigToHash(element)
+	}
+	return slot, nil
+}
+
+// encodeMappingKey encodes mapping key, value types are padded to 32 bytes, string and bytes are not padded
+func encodeMappingKey(key string) ([]byte, error) {
+	keyType, value, found := strings.Cut(key, ":")
+	if !found {
+		value = key
+		switch {
+		case isValidEthAddress(key):
+			keyType = "address"
+		case has0xPrefix(key) && len(key) == 66:
+			keyType = "bytes32"
+		default:
+			if _, err := ParseBigInt(key); err != nil {
+				return nil, fmt.Errorf("can not infer type of key, specify it like string:%s", key)
+			}
+			keyType = "uint256"
+		}
+	}
+
+	switch keyType {
+	case "string":
+		return []byte(value), nil
+	case "bytes":
+		return hexutil.Decode(value)
+	}
+	return encodeParameters([]string{keyType}, []string{value})
+}
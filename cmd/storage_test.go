@@ -0,0 +1,41 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+func TestDeriveStorageSlot(t *testing.T) {
+	slot0 := common.BigToHash(common.Big0)
+	tests := []struct {
+		slot         common.Hash
+		keys         []string
+		index        int64
+		elementSlots int64
+		expected     common.Hash
+	}{
+		{common.BigToHash(common.Big3), nil, -1, 1, common.BigToHash(common.Big3)},
+		{slot0, []string{"0"}, -1, 1, common.HexToHash("0xad3228b676f7d3cd4284a5443f17f1962b36e491b30a40b2405849e597ba5fb5")},
+		{slot0, []string{"uint256:0"}, -1, 1, common.HexToHash("0xad3228b676f7d3cd4284a5443f17f1962b36e491b30a40b2405849e597ba5fb5")},
+		{slot0, nil, 0, 1, common.HexToHash("0x290decd9548b62a8d60345a988386fc84ba6bc95484008f6362f93160ef3e563")},
+		{common.BigToHash(common.Big1), nil, 2, 2, common.HexToHash("0xb10e2d527612073b26eecdfd717e6a320cf44b4afac2b0732d9fcbe2b7fa0cfa")},
+		{common.BigToHash(common.Big2), []string{"0x8F36975cdeA2e6E64f85719788C8EFBBe89DFBbb"}, -1, 1,
+			crypto.Keccak256Hash(common.LeftPadBytes(common.FromHex("0x8F36975cdeA2e6E64f85719788C8EFBBe89DFBbb"), 32), common.BigToHash(common.Big2).Bytes())},
+		{slot0, []string{"string:abc"}, -1, 1, crypto.Keccak256Hash([]byte("abc"), slot0.Bytes())},
+		{slot0, []string{"0", "1"}, 0, 1, crypto.Keccak256Hash(crypto.Keccak256Hash(common.BigToHash(common.Big1).Bytes(),
+			common.HexToHash("0xad3228b676f7d3cd4284a5443f17f1962b36e491b30a40b2405849e597ba5fb5").Bytes()).Bytes())},
+	}
+
+	for i, tc := range tests {
+		got, err := deriveStorageSlot(tc.slot, tc.keys, tc.index, tc.elementSlots)
+		if err != nil || got != tc.expected {
+			t.Fatalf("test %d: expected: %v, got: %v (%v)", i+1, tc.expected.Hex(), got.Hex(), err)
+		}
+	}
+
+	if _, err := deriveStorageSlot(slot0, []string{"abc"}, -1, 1); err == nil {
+		t.Fatalf("expect error if type of key can not be inferred")
+	}
+}
@@ -0,0 +1,202 @@
+package cmd
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"math/big"
+	"sort"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+)
+
+// balanceOf costs more gas than getEthBalance, a smaller default batch keeps aggregate3 under the gas cap of eth_call
+const tokenBalanceDefaultBatch = 1000
+
+// tokenInfo is the decimals and symbol of ERC20 token, they are queried once per token
+type tokenInfo struct {
+	address  common.Address
+	decimals int32
+	symbol   string
+}
+
+// tokenBalance is the balance of an address in a token
+type tokenBalance struct {
+	addr    string
+	token   *tokenInfo
+	balance *big.Int // in smallest unit of token
+}
+
+// amount returns balance in token unit, e.g. 1.5 USDT
+func (b tokenBalance) amount() decimal.Decimal {
+	return decimal.NewFromBigInt(b.balance, -b.token.decimals)
+}
+
+// queryTokenInfos queries decimals and symbol of tokens, symbol of bytes32 type (e.g. MKR) is also supported
+func queryTokenInfos(rpcClient *rpc.Client, aggregate aggregateFunc, tokens []common.Address, batchSize int) ([]*tokenInfo, error) {
+	decimalsData, err := buildTxInputData(erc20FuncSignature["decimals"], nil)
+	if err != nil {
+		return nil, err
+	}
+	symbolData, err := buildTxInputData(erc20FuncSignature["symbol"], nil)
+	if err != nil {
+		return nil, err
+	}
+	symbolReturnArgs, err := buildReturnArgs(erc20FuncSignature["symbol"])
+	if err != nil {
+		return nil, err
+	}
+
+	var calls []multicall3Call
+	for _, token := range tokens {
+		calls = append(calls,
+			multicall3Call{Target: token, AllowFailure: true, CallData: decimalsData},
+			multicall3Call{Target: token, AllowFailure: true, CallData: symbolData})
+	}
+	results, err := aggregateInBatches(rpcClient, aggregate, calls, batchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	var infos []*tokenInfo
+	for i, token := range tokens {
+		decimalsResult, symbolResult := results[2*i], results[2*i+1]
+		if !decimalsResult.Success || len(decimalsResult.ReturnData) != 32 {
+			return nil, fmt.Errorf("decimals() of token %s fail, is it an ERC20 token?", token.Hex())
+		}
+		var info = &tokenInfo{
+			address:  token,
+			decimals: int32(new(big.Int).SetBytes(decimalsResult.ReturnData).Int64()),
+			symbol:   token.Hex(),
+		}
+
+		if symbolResult.Success {
+			if values, err := symbolReturnArgs.Unpack(symbolResult.ReturnData); err == nil {
+				info.symbol = values[0].(string)
+			} else if len(symbolResult.ReturnData) == 32 {
+				// symbol of some old tokens is bytes32
+				info.symbol = string(bytes.TrimRight(symbolResult.ReturnData, "\x00"))
+			}
+		} else {
+			log.Printf("warning: symbol() of token %s fail, use its address as symbol", token.Hex())
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// queryTokenBalances queries balanceOf of every address and token pair, the result is ordered by address then token
+func queryTokenBalances(rpcClient *rpc.Client, aggregate aggregateFunc, addresses []string, tokens []*tokenInfo, batchSize int) ([]tokenBalance, error) {
+	var calls []multicall3Call
+	for _, addr := range addresses {
+		callData, err := buildTxInputData(erc20FuncSignature["balanceOf"], []string{addr})
+		if err != nil {
+			return nil, err
+		}
+		for _, token := range tokens {
+			calls = append(calls, multicall3Call{Target: token.address, AllowFailure: true, CallData: callData})
+		}
+	}
+	results, err := aggregateInBatches(rpcClient, aggregate, calls, batchSize)
+	if err != nil {
+		return nil, err
+	}
+
+	var balances []tokenBalance
+	for i, addr := range addresses {
+		for j, token := range tokens {
+			result := results[i*len(tokens)+j]
+			if !result.Success || len(result.ReturnData) != 32 {
+				return nil, fmt.Errorf("balanceOf(%s) of token %s fail: %s", addr, token.address.Hex(), decodeRevertData(result.ReturnData, nil, nil))
+			}
+			balances = append(balances, tokenBalance{addr: addr, token: token, balance: new(big.Int).SetBytes(result.ReturnData)})
+		}
+	}
+	return balances, nil
+}
+
+// printTokenBalances prints balances of tokens, --sort, --only-positive and --terse are honored same as native balances
+func printTokenBalances(balances []tokenBalance, multipleTokens bool) {
+	if balanceSortOpt != sortNo {
+		// balances of different tokens are not comparable, so they are sorted in each token
+		var tokenOrder = make(map[*tokenInfo]int)
+		for _, b := range balances {
+			if _, ok := tokenOrder[b.token]; !ok {
+				tokenOrder[b.token] = len(tokenOrder)
+			}
+		}
+		sort.SliceStable(balances, func(i, j int) bool {
+			if balances[i].token != balances[j].token {
+				return tokenOrder[balances[i].token] < tokenOrder[balances[j].token]
+			}
+			if balanceSortOpt == sortAsc {
+				return balances[i].balance.Cmp(balances[j].balance) < 0
+			}
+			return balances[i].balance.Cmp(balances[j].balance) > 0
+		})
+	}
+
+	if isJsonOutput() {
+		var outputs = make([]BalanceOutput, 0, len(balances))
+		for _, b := range balances {
+			if balanceOnlyOutputWhenPositive && b.balance.Sign() <= 0 {
+				continue
+			}
+			outputs = append(outputs, BalanceOutput{
+				Address:    b.addr,
+				Token:      b.token.address.Hex(),
+				Balance:    b.amount().String(),
+				Unit:       b.token.symbol,
+				BalanceWei: b.balance.String(),
+			})
+		}
+		printJson(outputs)
+		return
+	}
+
+	for _, b := range balances {
+		if balanceOnlyOutputWhenPositive && b.balance.Sign() <= 0 {
+			// skip output when balance is zero or negative
+			continue
+		}
+		if globalOptTerseOutput && multipleTokens {
+			fmt.Printf("%v %s %s\n", b.addr, b.amount().String(), b.token.symbol)
+		} else if globalOptTerseOutput {
+			fmt.Printf("%v %s\n", b.addr, b.amount().String())
+		} else {
+			fmt.Printf("addr %v, balance %s %s\n", b.addr, b.amount().String(), b.token.symbol)
+		}
+	}
+}
+
+// queryAndPrintTokenBalances queries balances of tokens specified by --token for addresses, it uses Multicall if
+// deployed, otherwise json-rpc batch requests of eth_call
+func queryAndPrintTokenBalances(cmd *cobra.Command) {
+	var aggregate aggregateFunc = aggregate3
+	var batchSize = int(balanceAddressesBatchNumber)
+	if !cmd.Flags().Changed("batch") {
+		batchSize = tokenBalanceDefaultBatch
+	}
+	if balanceNoMulticall || !isMulticallDeployed(globalClient.EthClient) {
+		if !balanceNoMulticall {
+			log.Printf("Multicall contract is not deployed on chain %s, query balance by json-rpc batch requests", globalChainId)
+		}
+		aggregate = batchCalls
+		batchSize = balanceRpcBatchSize
+	}
+
+	var tokens []common.Address
+	for _, token := range balanceTokens {
+		tokens = append(tokens, common.HexToAddress(token))
+	}
+	infos, err := queryTokenInfos(globalClient.RpcClient, aggregate, tokens, batchSize)
+	checkErr(err)
+
+	balances, err := queryTokenBalances(globalClient.RpcClient, aggregate, addresses, infos, batchSize)
+	checkErr(err)
+
+	printTokenBalances(balances, len(infos) > 1)
+}
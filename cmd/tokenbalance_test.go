@@ -0,0 +1,43 @@
+package cmd
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestQueryTokenBalances(t *testing.T) {
+	token := common.HexToAddress("0xdAC17F958D2ee523a2206206994597C13D831ec7")
+	addresses := []string{"0x8F36975cdeA2e6E64f85719788C8EFBBe89DFBbb", "0x0000000000000000000000000000000000000001"}
+
+	for _, multicallDeployed := range []bool{true, false} {
+		rpcClient := dialStubRpc(t, map[string]any{"eth": &stubMulticallEth{multicallDeployed: multicallDeployed, token: token}})
+		var aggregate aggregateFunc = batchCalls
+		if multicallDeployed {
+			aggregate = aggregate3
+		}
+
+		infos, err := queryTokenInfos(rpcClient, aggregate, []common.Address{token}, 1)
+		if err != nil {
+			t.Fatalf("queryTokenInfos (deployed: %v) failed: %v", multicallDeployed, err)
+		}
+		if len(infos) != 1 || infos[0].decimals != 6 || infos[0].symbol != "USDT" {
+			t.Fatalf("expected: decimals 6, symbol USDT, got: %+v", infos[0])
+		}
+
+		balances, err := queryTokenBalances(rpcClient, aggregate, addresses, infos, 1)
+		if err != nil {
+			t.Fatalf("queryTokenBalances (deployed: %v) failed: %v", multicallDeployed, err)
+		}
+		for i, balance := range balances {
+			if balance.addr != addresses[i] || balance.amount().String() != "0.001" {
+				t.Fatalf("test %d (deployed: %v): expected: %v 0.001, got: %v %v", i+1, multicallDeployed, addresses[i], balance.addr, balance.amount())
+			}
+		}
+
+		// decimals() fails if the address is not a token
+		if _, err := queryTokenInfos(rpcClient, aggregate, []common.Address{common.HexToAddress(addresses[0])}, 1); err == nil {
+			t.Fatalf("expect error for non-token address (deployed: %v)", multicallDeployed)
+		}
+	}
+}
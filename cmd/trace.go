@@ -0,0 +1,255 @@
+package cmd
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+)
+
+var traceCmdABIFiles []string
+
+func init() {
+	traceCmd.Flags().StringArrayVarP(&traceCmdABIFiles, "abi-file", "", nil, "the path of abi file, its functions and errors are preferred when decoding calls, can be repeated for multiple contracts")
+}
+
+var traceCmd = &cobra.Command{
+	Use:   "trace <tx-hash>",
+	Short: "Show call tree of tx by debug_traceTransaction, calls are decoded by --abi-file or online signature lookup",
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("requires tx-hash")
+		}
+		if len(remove0xPrefix(args[0])) != 64 || !isValidHexString(args[0]) {
+			return fmt.Errorf("invalid tx-hash %s", args[0])
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		var abiContents [][]byte
+		for _, abiFile := range traceCmdABIFiles {
+			abiContent, err := os.ReadFile(abiFile)
+			checkErr(err)
+			abiContents = append(abiContents, abiContent)
+		}
+		decoder, err := newTraceDecoder(abiContents, GetFuncSig)
+		checkErr(err)
+
+		InitGlobalClient(globalOptNodeUrl)
+		frame, err := traceTransaction(globalClient.RpcClient, common.HexToHash(args[0]))
+		checkErr(err)
+
+		out := buildTraceOutput(frame, decoder)
+		if isJsonOutput() {
+			printJson(out)
+			return
+		}
+		printTrace(os.Stdout, out, 0)
+	},
+}
+
+// TraceOutput is the json output (--output json) of trace command, it's a call frame and its sub calls
+type TraceOutput struct {
+	Type         string         `json:"type"` // CALL | STATICCALL | DELEGATECALL | CREATE | CREATE2 | SELFDESTRUCT ...
+	From         string         `json:"from"`
+	To           string         `json:"to,omitempty"`
+	Value        string         `json:"value,omitempty"` // in wei
+	Gas          uint64         `json:"gas"`
+	GasUsed      uint64         `json:"gasUsed"`
+	Input        string         `json:"input"`
+	Function     string         `json:"function,omitempty"`  // empty if the input can not be decoded
+	SigSource    string         `json:"sigSource,omitempty"` // abi-file | online
+	Params       map[string]any `json:"params,omitempty"`
+	Output       string         `json:"output,omitempty"`
+	Returns      []ReturnValue  `json:"returns,omitempty"` // only if the function is in --abi-file
+	Error        string         `json:"error,omitempty"`
+	RevertReason string         `json:"revertReason,omitempty"`
+	Calls        []*TraceOutput `json:"calls,omitempty"`
+
+	argNames []string // names of Params in the order of function inputs
+}
+
+// traceTransaction gets the call frames of a mined tx by debug_traceTransaction with callTracer
+func traceTransaction(rpcClient *rpc.Client, txHash common.Hash) (*callFrame, error) {
+	var frame callFrame
+	if err := rpcClient.CallContext(context.Background(), &frame, "debug_traceTransaction", txHash, callTracerConfig); err != nil {
+		return nil, fmt.Errorf("debug_traceTransaction fail (the node must support debug namespace): %w", err)
+	}
+	return &frame, nil
+}
+
+// traceDecoder decodes input, output and revert data of call frames, the functions and errors in abi files are
+// tried first, then the signatures from online lookup
+type traceDecoder struct {
+	abis        []abi.ABI
+	errorsABI   *abi.ABI // the errors of all abi files
+	lookupFn    funcSigLookup
+	lookupCache map[string][]string
+}
+
+func newTraceDecoder(abiContents [][]byte, lookupFn funcSigLookup) (*traceDecoder, error) {
+	var d = &traceDecoder{
+		errorsABI:   &abi.ABI{Errors: make(map[string]abi.Error)},
+		lookupCache: make(map[string][]string),
+	}
+	for _, abiContent := range abiContents {
+		contractABI, err := parseContractABI(abiContent)
+		if err != nil {
+			return nil, err
+		}
+		d.abis = append(d.abis, contractABI)
+		for name, abiError := range contractABI.Errors {
+			d.errorsABI.Errors[name] = abiError
+		}
+	}
+	if lookupFn != nil {
+		// the same selector is often called many times in a tx, cache the lookup
+		d.lookupFn = func(selector string) ([]string, error) {
+			if sigs, ok := d.lookupCache[selector]; ok {
+				return sigs, nil
+			}
+			sigs, err := lookupFn(selector)
+			if err == nil {
+				d.lookupCache[selector] = sigs
+			}
+			return sigs, err
+		}
+	}
+	return d, nil
+}
+
+// decodeCall decodes the input and output of a call into out
+func (d *traceDecoder) decodeCall(out *TraceOutput, input []byte, output []byte) {
+	if len(input) < 4 || out.Type == "CREATE" || out.Type == "CREATE2" {
+		return
+	}
+
+	for _, contractABI := range d.abis {
+		method, err := contractABI.MethodById(input[:4])
+		if err != nil {
+			continue
+		}
+		values, err := method.Inputs.Unpack(input[4:])
+		if err != nil {
+			continue
+		}
+		out.Function = method.Sig
+		out.SigSource = "abi-file"
+		out.argNames = buildArgNames(method.Inputs)
+		out.Params = make(map[string]any, len(values))
+		for i, value := range values {
+			out.Params[out.argNames[i]] = normalizeDecodedValue(value)
+		}
+
+		if out.Error == "" && len(method.Outputs) > 0 {
+			if values, err := method.Outputs.Unpack(output); err == nil {
+				for i, returnArg := range method.Outputs {
+					out.Returns = append(out.Returns, ReturnValue{Name: returnArg.Name, Type: returnArg.Type.String(), Value: normalizeDecodedValue(values[i])})
+				}
+			}
+		}
+		return
+	}
+
+	if d.lookupFn == nil {
+		return
+	}
+	decoded, err := decodeCalldata(hexutil.Encode(input), "", "", d.lookupFn)
+	if err != nil {
+		return
+	}
+	out.Function = decoded.Signature
+	out.SigSource = decoded.SigSource
+	out.Params = decoded.Params
+	for i := 0; i < len(decoded.Params); i++ {
+		out.argNames = append(out.argNames, fmt.Sprintf("arg%d", i))
+	}
+}
+
+// buildTraceOutput builds the output of call frame and its sub calls
+func buildTraceOutput(frame *callFrame, decoder *traceDecoder) *TraceOutput {
+	var out = &TraceOutput{
+		Type:    frame.Type,
+		From:    frame.From.Hex(),
+		Gas:     uint64(frame.Gas),
+		GasUsed: uint64(frame.GasUsed),
+		Input:   hexutil.Encode(frame.Input),
+		Error:   frame.Error,
+	}
+	if frame.To != nil {
+		out.To = frame.To.Hex()
+	}
+	if frame.Value != nil && frame.Value.ToInt().Sign() > 0 {
+		out.Value = frame.Value.ToInt().String()
+	}
+	if len(frame.Output) > 0 {
+		out.Output = hexutil.Encode(frame.Output)
+	}
+	if frame.Error == "execution reverted" {
+		out.RevertReason = decodeRevertData(frame.Output, decoder.errorsABI, decoder.lookupFn)
+	}
+	decoder.decodeCall(out, frame.Input, frame.Output)
+
+	for _, sub := range frame.Calls {
+		out.Calls = append(out.Calls, buildTraceOutput(sub, decoder))
+	}
+	return out
+}
+
+// printTrace prints the call tree in human-readable format, sub calls are indented by depth
+func printTrace(w io.Writer, out *TraceOutput, depth int) {
+	indent := strings.Repeat("  ", depth)
+
+	line := fmt.Sprintf("%s[%s] %s -> %s", indent, out.Type, out.From, out.To)
+	if value, err := decimal.NewFromString(out.Value); err == nil {
+		line += fmt.Sprintf(", value: %s %s", wei2Other(value, unitEther).String(), currentNativeCurrency().Symbol)
+	}
+	line += fmt.Sprintf(", gas used: %d/%d", out.GasUsed, out.Gas)
+	fmt.Fprintln(w, line)
+
+	if out.Function != "" {
+		fmt.Fprintf(w, "%s  function: %s\n", indent, out.Function)
+		for _, name := range out.argNames {
+			value, _ := json.Marshal(out.Params[name])
+			fmt.Fprintf(w, "%s    %s: %s\n", indent, name, strings.Trim(string(value), `"`))
+		}
+	} else if out.Type == "CREATE" || out.Type == "CREATE2" {
+		fmt.Fprintf(w, "%s  init code: %d bytes\n", indent, len(remove0xPrefix(out.Input))/2)
+	} else if out.Input != "0x" {
+		fmt.Fprintf(w, "%s  input: %s\n", indent, out.Input)
+	}
+
+	if out.Error != "" {
+		if out.RevertReason != "" {
+			fmt.Fprintf(w, "%s  error: %s, revert reason: %s\n", indent, out.Error, out.RevertReason)
+		} else {
+			fmt.Fprintf(w, "%s  error: %s\n", indent, out.Error)
+		}
+	} else if len(out.Returns) > 0 {
+		var returns []string
+		for i, ret := range out.Returns {
+			name := ret.Name
+			if name == "" {
+				name = fmt.Sprintf("ret%d", i)
+			}
+			value, _ := json.Marshal(ret.Value)
+			returns = append(returns, fmt.Sprintf("%s=%s", name, strings.Trim(string(value), `"`)))
+		}
+		fmt.Fprintf(w, "%s  returns: %s\n", indent, strings.Join(returns, ", "))
+	} else if out.Output != "" && out.Type != "CREATE" && out.Type != "CREATE2" {
+		fmt.Fprintf(w, "%s  output: %s\n", indent, out.Output)
+	}
+
+	for _, sub := range out.Calls {
+		printTrace(w, sub, depth+1)
+	}
+}
@@ -0,0 +1,66 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"testing"
+)
+
+const traceFixturePath = "../testdata/trace_calltracer.json"
+
+func TestTraceOutput(t *testing.T) {
+	content, err := os.ReadFile(traceFixturePath)
+	if err != nil {
+		t.Fatalf("read fixture failed: %v", err)
+	}
+	var frame callFrame
+	if err := json.Unmarshal(content, &frame); err != nil {
+		t.Fatalf("unmarshal fixture failed: %v", err)
+	}
+
+	routerABI := []byte(`[{"type":"function","name":"swap","inputs":[{"name":"amount","type":"uint256"}],"outputs":[]},` +
+		`{"type":"function","name":"balanceOf","inputs":[{"name":"owner","type":"address"}],"outputs":[{"name":"balance","type":"uint256"}]}]`)
+	lookupFn := func(selector string) ([]string, error) {
+		if selector == "0xa9059cbb" {
+			return []string{"transfer(address,uint256)"}, nil
+		}
+		return nil, nil
+	}
+	decoder, err := newTraceDecoder([][]byte{routerABI}, lookupFn)
+	if err != nil {
+		t.Fatalf("newTraceDecoder failed: %v", err)
+	}
+
+	out := buildTraceOutput(&frame, decoder)
+	var buf bytes.Buffer
+	printTrace(&buf, out, 0)
+
+	expected := `[CALL] 0x8F36975cdeA2e6E64f85719788C8EFBBe89DFBbb -> 0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D, value: 0.1 ETH, gas used: 120000/200000
+  input: 0x123456780000000000000000000000000000000000000000000000000000000000000001
+  [STATICCALL] 0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D -> 0xdAC17F958D2ee523a2206206994597C13D831ec7, gas used: 2700/10000
+    function: balanceOf(address)
+      owner: 0x8F36975cdeA2e6E64f85719788C8EFBBe89DFBbb
+    returns: balance=1000
+  [CALL] 0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D -> 0xdAC17F958D2ee523a2206206994597C13D831ec7, gas used: 30000/100000
+    function: transfer(address,uint256)
+      arg0: 0x703662e526d2b71944Fbfb9d87f61dE3e0f0F290
+      arg1: 1000000
+    output: 0x0000000000000000000000000000000000000000000000000000000000000001
+  [CALL] 0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D -> 0x703662e526d2b71944Fbfb9d87f61dE3e0f0F290, value: 0.01 ETH, gas used: 0/2300
+  [DELEGATECALL] 0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D -> 0xdAC17F958D2ee523a2206206994597C13D831ec7, gas used: 500/10000
+    function: transfer(address,uint256)
+      arg0: 0x703662e526d2b71944Fbfb9d87f61dE3e0f0F290
+      arg1: 1000000
+    error: execution reverted, revert reason: insufficient balance
+  [CREATE2] 0x7a250d5630B4cF539739dF2C5dAcb4c659F2488D -> 0x1111111111111111111111111111111111111111, gas used: 500/10000
+    init code: 5 bytes
+`
+	if buf.String() != expected {
+		t.Fatalf("expected:\n%v\ngot:\n%v", expected, buf.String())
+	}
+
+	if len(out.Calls) != 4+1 || out.Calls[0].Returns[0].Value != "1000" || out.Calls[1].SigSource != "online" || out.Calls[3].RevertReason != "insufficient balance" {
+		t.Fatalf("unexpected json output: %+v", out)
+	}
+}
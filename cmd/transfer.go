@@ -20,20 +20,15 @@ var transferNotCheck bool
 var transferHexData string
 
 func init() {
-	transferCmd.Flags().StringVarP(&transferUnit, "unit", "u", "ether", "wei | gwei | ether, unit of amount")
+	transferCmd.Flags().StringVarP(&transferUnit, "unit", "u", "ether", "wei | gwei | ether, unit of amount, ether means the native currency of chain (its symbol is also accepted, e.g. POL)")
 	transferCmd.Flags().BoolVarP(&transferNotCheck, "not-check", "", false, "don't check result, return immediately after send transaction")
 	transferCmd.Flags().StringVarP(&transferHexData, "hex-data", "", "", "the payload hex data when transfer")
 }
 
 func validationTransferCmdOpts() bool {
 	// validation
-	if !contains([]string{unitWei, unitGwei, unitEther}, transferUnit) {
-		log.Fatalf("invalid option for --unit: %v", transferUnit)
-		return false
-	}
-
-	if globalOptPrivateKey == "" {
-		log.Fatalf("--private-key is required for transfer command")
+	if !hasSigner() {
+		log.Fatalf("%s is required for transfer command", signerOptions)
 		return false
 	}
 
@@ -76,7 +71,7 @@ func getGasPrice(client *ethclient.Client) (*big.Int, error) {
 var transferCmd = &cobra.Command{
 	Use:   "transfer <target-address> <amount>",
 	Short: "Transfer native token",
-	Long:  "Transfer AMOUNT of eth to TARGET-ADDRESS, special word `all` is valid amount. unit is ether, can be changed by --unit.",
+	Long:  "Transfer AMOUNT of native token to TARGET-ADDRESS, special word `all` is valid amount. unit is ether (i.e. the native currency of chain, such as ETH or POL), can be changed by --unit.",
 	Args: func(cmd *cobra.Command, args []string) error {
 		if len(args) < 1 {
 			return fmt.Errorf("requires target-address and amount")
@@ -117,6 +112,11 @@ var transferCmd = &cobra.Command{
 
 		InitGlobalClient(globalOptNodeUrl)
 
+		// the symbol of native currency may be known only after connected
+		if !isValidUnit(transferUnit) {
+			log.Fatalf("invalid option for --unit: %v", transferUnit)
+		}
+
 		ctx := context.Background()
 
 		gasPrice, err := getGasPrice(globalClient.EthClient)
@@ -130,7 +130,7 @@ var transferCmd = &cobra.Command{
 			globalOptTxType = txTypeEip155
 
 			// transfer all balance (only reserve some gas just pay for this tx) to target address
-			fromAddr := extractAddressFromPrivateKey(hexToPrivateKey(globalOptPrivateKey))
+			fromAddr := loadSigner().Address()
 
 			// Get current balance
 			balance, err := globalClient.EthClient.BalanceAt(ctx, fromAddr, nil)
@@ -148,7 +148,7 @@ var transferCmd = &cobra.Command{
 					Data:  common.FromHex(transferHexData),
 				})
 				if err != nil {
-					log.Fatalf("EstimateGas fail: %v", err)
+					log.Fatalf("EstimateGas fail: %v", wrapRevertError(err))
 				}
 				gasLimit = estimateGasLimit
 			}
@@ -166,10 +166,10 @@ var transferCmd = &cobra.Command{
 			// We must subtract `L2 fee + L1 fee` if use want transfer 'all' native token
 			if l1GasPriceOracleExisted {
 				// Estimate L1 Fee
-				privateKey := hexToPrivateKey(globalOptPrivateKey)
-				fromAddress := extractAddressFromPrivateKey(privateKey)
+				signer := loadSigner()
+				fromAddress := signer.Address()
 				toAddr := common.HexToAddress(targetAddress)
-				signedTx, err := BuildSignedTx(globalClient.EthClient, privateKey, &fromAddress, &toAddr, big.NewInt(0).Sub(balance, gasMayUsed), gasPrice, common.FromHex(transferHexData), nil)
+				signedTx, err := BuildSignedTx(globalClient.EthClient, signer, &fromAddress, &toAddr, big.NewInt(0).Sub(balance, gasMayUsed), gasPrice, common.FromHex(transferHexData), nil)
 				if err != nil {
 					log.Fatalf("BuildSignedTx fail: %v", err)
 				}
@@ -196,22 +196,25 @@ var transferCmd = &cobra.Command{
 			amountInWei = unify2Wei(amount, transferUnit)
 		}
 
-		if tx, err := TransferHelper(globalClient.RpcClient, globalClient.EthClient, globalOptPrivateKey, targetAddress, amountInWei.BigInt(), gasPrice, common.FromHex(transferHexData)); err != nil {
+		if out, err := TransferHelper(globalClient.RpcClient, globalClient.EthClient, loadSigner(), targetAddress, amountInWei.BigInt(), gasPrice, common.FromHex(transferHexData)); err != nil {
 			log.Fatalf("transfer fail: %v", err)
+		} else if isJsonOutput() {
+			printJson(out)
 		} else {
-			log.Printf("transfer finished, tx = %v", tx)
+			log.Printf("transfer finished, tx = %v", out.TxHash)
 		}
 	},
 }
 
-func TransferHelper(rcpClient *rpc.Client, client *ethclient.Client, privateKeyHex string, toAddress string, amountInWei *big.Int, gasPrice *big.Int, data []byte) (string, error) {
-	log.Printf("transfer %v ether (%v wei) from %v to %v",
+func TransferHelper(rcpClient *rpc.Client, client *ethclient.Client, signer Signer, toAddress string, amountInWei *big.Int, gasPrice *big.Int, data []byte) (*TxOutput, error) {
+	log.Printf("transfer %v %s (%v wei) from %v to %v",
 		wei2Other(bigIntToDecimal(amountInWei), unitEther).String(),
+		currentNativeCurrency().Symbol,
 		amountInWei.String(),
-		extractAddressFromPrivateKey(hexToPrivateKey(privateKeyHex)).String(),
+		signer.Address().String(),
 		toAddress)
 	var toAddr = common.HexToAddress(toAddress)
-	return Transact(rcpClient, client, hexToPrivateKey(privateKeyHex), &toAddr, amountInWei, gasPrice, data)
+	return TransactTx(rcpClient, client, signer, &toAddr, amountInWei, gasPrice, data)
 }
 
 // getL1Fee call contract function getL1Fee to get the L1 fee
@@ -0,0 +1,384 @@
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math/big"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/shopspring/decimal"
+	"github.com/spf13/cobra"
+)
+
+var txPrepareValue string
+var txPrepareUnit string
+var txOutFile string
+
+// txCmd represents the tx command
+var txCmd = &cobra.Command{
+	Use:   "tx",
+	Short: "Prepare and sign transaction offline, speed up or cancel pending transaction",
+	Run: func(cmd *cobra.Command, args []string) {
+		_ = cmd.Help()
+		os.Exit(1)
+	},
+}
+
+var txPrepareCmd = &cobra.Command{
+	Use:   "prepare <to-address> [hex-data]",
+	Short: "Build unsigned transaction (nonce, gas and fees are resolved online), it can be signed offline by tx sign",
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) < 1 || len(args) > 2 {
+			return fmt.Errorf("requires <to-address> [hex-data]")
+		}
+		if !isValidEthAddress(args[0]) {
+			return fmt.Errorf("%v is not a valid eth address", args[0])
+		}
+		if len(args) > 1 && !isValidHexString(args[1]) {
+			return fmt.Errorf("invalid hex data: %s", args[1])
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		InitGlobalClient(globalOptNodeUrl)
+
+		if !isValidUnit(txPrepareUnit) {
+			log.Fatalf("invalid unit %v", txPrepareUnit)
+		}
+
+		var fromAddress common.Address
+		if globalOptFrom != "" {
+			if !isValidEthAddress(globalOptFrom) {
+				log.Fatalf("%v is not a valid eth address", globalOptFrom)
+			}
+			fromAddress = common.HexToAddress(globalOptFrom)
+		} else if hasSigner() {
+			fromAddress = loadSigner().Address()
+		} else {
+			log.Fatalf("--from is required for this command")
+		}
+
+		var toAddress = common.HexToAddress(args[0])
+		var data []byte
+		if len(args) > 1 {
+			data = common.FromHex(args[1])
+		}
+
+		value, err := decimal.NewFromString(txPrepareValue)
+		if err != nil {
+			log.Fatalf("invalid --value: %v", txPrepareValue)
+		}
+		valueInWei := unify2Wei(value, txPrepareUnit)
+
+		tx, err := BuildTx(globalClient.EthClient, fromAddress, &toAddress, valueInWei.BigInt(), nil, data)
+		checkErr(err)
+
+		chainId, _ := new(big.Int).SetString(globalChainId, 10)
+		txFile := newTxFile(tx, chainId, fromAddress)
+		printTxSummary(os.Stderr, txFile, GetFuncSig)
+		checkErr(writeTxFile(txFile, txOutFile))
+	},
+}
+
+var txSignCmd = &cobra.Command{
+	Use:   "sign <unsigned-tx-file>",
+	Short: "Sign the transaction produced by tx prepare, no rpc is needed, so it can be done on an air-gapped machine",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if !hasSigner() {
+			log.Fatalf("%s is required for this command", signerOptions)
+		}
+
+		txFile, err := readTxFile(args[0])
+		checkErr(err)
+		if txFile.RawTx != "" {
+			log.Fatalf("%s is already signed", args[0])
+		}
+		tx, chainId, err := txFile.toTransaction()
+		checkErr(err)
+
+		// No rpc connection, the chain is identified by the tx file
+		globalChainId = txFile.ChainId
+
+		signer := loadSigner()
+		if signer.Address() != common.HexToAddress(txFile.From) {
+			log.Fatalf("the signer is %s, but the tx is from %s", signer.Address().Hex(), txFile.From)
+		}
+
+		// Only known function signatures are used to decode calldata, as there is no network
+		printTxSummary(os.Stderr, txFile, lookupKnownFuncSig)
+
+		if globalOptShowPreHash {
+			printPreHash(types.LatestSignerForChainID(chainId).Hash(tx))
+		}
+		signedTx, err := signer.SignTx(tx, chainId)
+		checkErr(err)
+
+		checkErr(writeTxFile(newTxFile(signedTx, chainId, signer.Address()), txOutFile))
+	},
+}
+
+func init() {
+	txPrepareCmd.Flags().StringVarP(&txPrepareValue, "value", "", "0", "the value of tx, its unit is specified by --unit")
+	txPrepareCmd.Flags().StringVarP(&txPrepareUnit, "unit", "u", unitEther, "wei | gwei | ether, unit of --value, ether means the native currency of chain (its symbol is also accepted, e.g. POL)")
+	txPrepareCmd.Flags().StringVarP(&txOutFile, "out", "", "", "the file to save the unsigned tx, print to stdout if not given")
+	txSignCmd.Flags().StringVarP(&txOutFile, "out", "", "", "the file to save the signed tx, print to stdout if not given")
+
+	txCmd.AddCommand(txPrepareCmd)
+	txCmd.AddCommand(txSignCmd)
+}
+
+// TxFile is the transaction file of offline signing workflow, it's produced by tx prepare (unsigned) and tx sign (signed).
+// All amounts are in wei.
+type TxFile struct {
+	ChainId              string `json:"chainId"`
+	Type                 string `json:"type"` // eip155 | eip1559
+	From                 string `json:"from"`
+	To                   string `json:"to,omitempty"` // empty means contract creation
+	Nonce                uint64 `json:"nonce"`
+	Value                string `json:"value"`
+	Gas                  uint64 `json:"gas"`
+	GasPrice             string `json:"gasPrice,omitempty"`             // only for eip155
+	MaxFeePerGas         string `json:"maxFeePerGas,omitempty"`         // only for eip1559
+	MaxPriorityFeePerGas string `json:"maxPriorityFeePerGas,omitempty"` // only for eip1559
+	Data                 string `json:"data"`
+	Hash                 string `json:"hash,omitempty"`  // only for signed tx
+	RawTx                string `json:"rawTx,omitempty"` // only for signed tx, it can be sent by eth_sendRawTransaction
+}
+
+// newTxFile builds TxFile from tx, RawTx and Hash are filled if tx is signed
+func newTxFile(tx *types.Transaction, chainId *big.Int, from common.Address) *TxFile {
+	var txFile = &TxFile{
+		ChainId: chainId.String(),
+		From:    from.Hex(),
+		Nonce:   tx.Nonce(),
+		Value:   tx.Value().String(),
+		Gas:     tx.Gas(),
+		Data:    hexutil.Encode(tx.Data()),
+	}
+	if tx.To() != nil {
+		txFile.To = tx.To().Hex()
+	}
+	if tx.Type() != types.LegacyTxType && tx.Type() != types.AccessListTxType {
+		txFile.Type = txTypeEip1559
+		txFile.MaxFeePerGas = tx.GasFeeCap().String()
+		txFile.MaxPriorityFeePerGas = tx.GasTipCap().String()
+	} else {
+		txFile.Type = txTypeEip155
+		txFile.GasPrice = tx.GasPrice().String()
+	}
+
+	if isSignedTx(tx) {
+		rawTx, err := GenRawTx(tx)
+		checkErr(err)
+		txFile.RawTx = rawTx
+		txFile.Hash = tx.Hash().Hex()
+	}
+	return txFile
+}
+
+// isSignedTx returns true if signature values of tx are not all zero
+func isSignedTx(tx *types.Transaction) bool {
+	v, r, s := tx.RawSignatureValues()
+	return (v != nil && v.Sign() != 0) || (r != nil && r.Sign() != 0) || (s != nil && s.Sign() != 0)
+}
+
+// toTransaction builds the unsigned transaction
+func (f *TxFile) toTransaction() (*types.Transaction, *big.Int, error) {
+	chainId, ok := new(big.Int).SetString(f.ChainId, 10)
+	if !ok {
+		return nil, nil, fmt.Errorf("invalid chainId %v", f.ChainId)
+	}
+	if !isValidEthAddress(f.From) {
+		return nil, nil, fmt.Errorf("invalid from %v", f.From)
+	}
+	var to *common.Address
+	if f.To != "" {
+		if !isValidEthAddress(f.To) {
+			return nil, nil, fmt.Errorf("invalid to %v", f.To)
+		}
+		toAddress := common.HexToAddress(f.To)
+		to = &toAddress
+	}
+	value, err := parseWei("value", f.Value)
+	if err != nil {
+		return nil, nil, err
+	}
+	data, err := hexutil.Decode(f.Data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("invalid data: %w", err)
+	}
+
+	switch f.Type {
+	case txTypeEip1559:
+		maxFeePerGas, err := parseWei("maxFeePerGas", f.MaxFeePerGas)
+		if err != nil {
+			return nil, nil, err
+		}
+		maxPriorityFeePerGas, err := parseWei("maxPriorityFeePerGas", f.MaxPriorityFeePerGas)
+		if err != nil {
+			return nil, nil, err
+		}
+		return types.NewTx(&types.DynamicFeeTx{
+			ChainID:   chainId,
+			Nonce:     f.Nonce,
+			To:        to,
+			Value:     value,
+			Gas:       f.Gas,
+			GasTipCap: maxPriorityFeePerGas,
+			GasFeeCap: maxFeePerGas,
+			Data:      data,
+		}), chainId, nil
+	case txTypeEip155:
+		gasPrice, err := parseWei("gasPrice", f.GasPrice)
+		if err != nil {
+			return nil, nil, err
+		}
+		return types.NewTx(&types.LegacyTx{
+			Nonce:    f.Nonce,
+			To:       to,
+			Value:    value,
+			Gas:      f.Gas,
+			GasPrice: gasPrice,
+			Data:     data,
+		}), chainId, nil
+	default:
+		return nil, nil, fmt.Errorf("invalid type %v, must be %s or %s", f.Type, txTypeEip155, txTypeEip1559)
+	}
+}
+
+func parseWei(name string, value string) (*big.Int, error) {
+	rc, ok := new(big.Int).SetString(value, 10)
+	if !ok || rc.Sign() < 0 {
+		return nil, fmt.Errorf("invalid %s %v", name, value)
+	}
+	return rc, nil
+}
+
+// readTxFile reads the file produced by tx prepare or tx sign
+func readTxFile(path string) (*TxFile, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var txFile TxFile
+	if err := json.Unmarshal(content, &txFile); err != nil {
+		return nil, fmt.Errorf("parse tx file %s failed: %w", path, err)
+	}
+	return &txFile, nil
+}
+
+// writeTxFile saves txFile into path, or prints it to stdout if path is empty
+func writeTxFile(txFile *TxFile, path string) error {
+	if path == "" {
+		printJson(txFile)
+		return nil
+	}
+	content, err := json.MarshalIndent(txFile, "", "  ")
+	if err != nil {
+		return err
+	}
+	if err := os.WriteFile(path, append(content, '\n'), 0644); err != nil {
+		return err
+	}
+	log.Printf("tx saved to %s", path)
+	return nil
+}
+
+// printTxSummary prints the transaction in human-readable form for review, calldata is decoded if its signature is found by lookupFn
+func printTxSummary(w io.Writer, txFile *TxFile, lookupFn funcSigLookup) {
+	symbol := currentNativeCurrency().Symbol
+	formatAmount := func(wei string) string {
+		amount, err := decimal.NewFromString(wei)
+		if err != nil {
+			return wei
+		}
+		return fmt.Sprintf("%s %s", wei2Other(amount, unitEther).String(), symbol)
+	}
+	formatGwei := func(wei string) string {
+		amount, err := decimal.NewFromString(wei)
+		if err != nil {
+			return wei
+		}
+		return fmt.Sprintf("%s gwei", wei2Other(amount, unitGwei).String())
+	}
+
+	var to = txFile.To
+	if to == "" {
+		to = "(contract creation)"
+	}
+	fmt.Fprintf(w, "chain id: %s\n", txFile.ChainId)
+	fmt.Fprintf(w, "from: %s\n", txFile.From)
+	fmt.Fprintf(w, "to: %s\n", to)
+	fmt.Fprintf(w, "value: %s (%s wei)\n", formatAmount(txFile.Value), txFile.Value)
+	fmt.Fprintf(w, "nonce: %d\n", txFile.Nonce)
+	fmt.Fprintf(w, "gas limit: %d\n", txFile.Gas)
+
+	var feePerGas string
+	if txFile.Type == txTypeEip1559 {
+		feePerGas = txFile.MaxFeePerGas
+		fmt.Fprintf(w, "max fee per gas: %s\n", formatGwei(txFile.MaxFeePerGas))
+		fmt.Fprintf(w, "max priority fee per gas: %s\n", formatGwei(txFile.MaxPriorityFeePerGas))
+	} else {
+		feePerGas = txFile.GasPrice
+		fmt.Fprintf(w, "gas price: %s\n", formatGwei(txFile.GasPrice))
+	}
+	if fee, ok := new(big.Int).SetString(feePerGas, 10); ok {
+		fee.Mul(fee, new(big.Int).SetUint64(txFile.Gas))
+		fmt.Fprintf(w, "max tx fee: %s\n", formatAmount(fee.String()))
+	}
+
+	if data := remove0xPrefix(txFile.Data); len(data) > 0 {
+		fmt.Fprintf(w, "data: %s\n", txFile.Data)
+		if txFile.To != "" && len(data) >= 8 {
+			if decoded, err := decodeCalldata(txFile.Data, "", "", lookupFn); err == nil {
+				fmt.Fprintf(w, "function: %s\n", decoded.Signature)
+				var names []string
+				for name := range decoded.Params {
+					names = append(names, name)
+				}
+				sort.Strings(names)
+				for _, name := range names {
+					value, _ := json.Marshal(decoded.Params[name])
+					fmt.Fprintf(w, "  %s: %s\n", name, strings.Trim(string(value), `"`))
+				}
+			} else {
+				fmt.Fprintf(w, "function: unknown (%v)\n", err)
+			}
+		}
+	}
+	if txFile.Hash != "" {
+		fmt.Fprintf(w, "tx hash: %s\n", txFile.Hash)
+	}
+}
+
+// knownFuncSigs are signatures of common functions, they are used to decode calldata without network
+var knownFuncSigs = []string{
+	"transfer(address,uint256)",
+	"approve(address,uint256)",
+	"transferFrom(address,address,uint256)",
+	"setApprovalForAll(address,bool)",
+	"safeTransferFrom(address,address,uint256)",
+	"safeTransferFrom(address,address,uint256,bytes)",
+	"deposit()",
+	"withdraw(uint256)",
+}
+
+// lookupKnownFuncSig looks up function signature in knownFuncSigs
+func lookupKnownFuncSig(selector string) ([]string, error) {
+	var rc []string
+	for _, sig := range knownFuncSigs {
+		if hexutil.Encode(crypto.Keccak256([]byte(sig))[:4]) == strings.ToLower(selector) {
+			rc = append(rc, sig)
+		}
+	}
+	return rc, nil
+}
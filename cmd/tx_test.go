@@ -0,0 +1,89 @@
+package cmd
+
+import (
+	"bytes"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestTxFileSignAndDecode(t *testing.T) {
+	signer := newKeySigner(hexToPrivateKey("4f66baf5a1c3a91b6cf8173cdb60d12496e1f572cee6f9f86bc507d87a9790d7"))
+	to := common.HexToAddress("0x8F36975cdeA2e6E64f85719788C8EFBBe89DFBbb")
+	chainId := big.NewInt(11155111)
+
+	tests := []*types.Transaction{
+		types.NewTx(&types.LegacyTx{Nonce: 1, To: &to, Value: big.NewInt(1000), Gas: 21000, GasPrice: big.NewInt(1e9)}),
+		types.NewTx(&types.DynamicFeeTx{ChainID: chainId, Nonce: 2, To: &to, Value: big.NewInt(0), Gas: 60000, GasTipCap: big.NewInt(1e9), GasFeeCap: big.NewInt(2e9), Data: []byte{1, 2, 3, 4}}),
+		types.NewTx(&types.DynamicFeeTx{ChainID: chainId, Nonce: 3, Value: big.NewInt(0), Gas: 100000, GasTipCap: big.NewInt(1e9), GasFeeCap: big.NewInt(2e9), Data: []byte{0x60, 0x80}}), // contract creation
+	}
+
+	for i, tx := range tests {
+		unsigned := newTxFile(tx, chainId, signer.Address())
+		if unsigned.RawTx != "" || unsigned.Hash != "" {
+			t.Fatalf("test %d: unexpected rawTx of unsigned tx", i+1)
+		}
+
+		rebuilt, rebuiltChainId, err := unsigned.toTransaction()
+		if err != nil {
+			t.Fatalf("test %d: toTransaction failed: %v", i+1, err)
+		}
+		txSigner := types.LatestSignerForChainID(chainId)
+		if rebuiltChainId.Cmp(chainId) != 0 || txSigner.Hash(rebuilt) != txSigner.Hash(tx) {
+			t.Fatalf("test %d: expected: %v, got: %v", i+1, txSigner.Hash(tx), txSigner.Hash(rebuilt))
+		}
+
+		signedTx, err := signer.SignTx(rebuilt, rebuiltChainId)
+		if err != nil {
+			t.Fatalf("test %d: SignTx failed: %v", i+1, err)
+		}
+		signed := newTxFile(signedTx, chainId, signer.Address())
+		if signed.RawTx == "" || signed.Hash != signedTx.Hash().Hex() {
+			t.Fatalf("test %d: expected hash: %v, got: %v", i+1, signedTx.Hash().Hex(), signed.Hash)
+		}
+
+		decoded, err := decodeSignedTx(signed.RawTx)
+		if err != nil {
+			t.Fatalf("test %d: decodeSignedTx failed: %v", i+1, err)
+		}
+		if !reflect.DeepEqual(decoded, signed) {
+			t.Fatalf("test %d: expected: %+v, got: %+v", i+1, signed, decoded)
+		}
+	}
+}
+
+func TestPrintTxSummary(t *testing.T) {
+	txFile := &TxFile{
+		ChainId:              "1",
+		Type:                 txTypeEip1559,
+		From:                 "0x8F36975cdeA2e6E64f85719788C8EFBBe89DFBbb",
+		To:                   "0xdAC17F958D2ee523a2206206994597C13D831ec7",
+		Nonce:                5,
+		Value:                "0",
+		Gas:                  21000,
+		MaxFeePerGas:         "2000000000",
+		MaxPriorityFeePerGas: "1000000000",
+		Data:                 "0xa9059cbb0000000000000000000000008f36975cdea2e6e64f85719788c8efbbe89dfbbb00000000000000000000000000000000000000000000000000000000000f4240",
+	}
+
+	var buf bytes.Buffer
+	printTxSummary(&buf, txFile, lookupKnownFuncSig)
+	summary := buf.String()
+
+	for i, expected := range []string{
+		"to: 0xdAC17F958D2ee523a2206206994597C13D831ec7\n",
+		"max fee per gas: 2 gwei\n",
+		"max priority fee per gas: 1 gwei\n",
+		"max tx fee: 0.000042 ETH\n",
+		"function: transfer(address,uint256)\n",
+		"1000000\n",
+	} {
+		if !strings.Contains(summary, expected) {
+			t.Fatalf("test %d: expected: %q in summary, got: %s", i+1, expected, summary)
+		}
+	}
+}
@@ -0,0 +1,296 @@
+package cmd
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"math/big"
+	"os"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/spf13/cobra"
+)
+
+// The gas used by a plain transfer of native token
+const gasUsedByTransferEth = 21000
+
+// geth rejects a replacement tx unless its fees are bumped by at least 10% (txpool.pricebump), most nodes follow it
+const minReplaceBumpPercent = 10
+
+var txReplaceBumpPercent uint64
+var txReplaceNotCheck bool
+
+var txSpeedUpCmd = &cobra.Command{
+	Use:   "speed-up <tx-hash>",
+	Short: "Speed up a pending tx, it's re-signed at the same nonce with bumped fees",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		replacePendingTx(args[0], false)
+	},
+}
+
+var txCancelCmd = &cobra.Command{
+	Use:   "cancel <tx-hash>",
+	Short: "Cancel a pending tx, a 0-value self transfer is sent at the same nonce with bumped fees",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		replacePendingTx(args[0], true)
+	},
+}
+
+func init() {
+	for _, c := range []*cobra.Command{txSpeedUpCmd, txCancelCmd} {
+		c.Flags().Uint64VarP(&txReplaceBumpPercent, "bump-percent", "", minReplaceBumpPercent, "the fees of original tx are bumped by this percentage at least, the current network fees are used if they are higher")
+		c.Flags().BoolVarP(&txReplaceNotCheck, "not-check", "", false, "don't check result, return immediately after send transaction")
+	}
+
+	txCmd.AddCommand(txSpeedUpCmd)
+	txCmd.AddCommand(txCancelCmd)
+}
+
+// ReplaceTxOutput is the json output (--output json) of tx speed-up and tx cancel
+type ReplaceTxOutput struct {
+	OriginalTxHash       string `json:"originalTxHash"`
+	TxHash               string `json:"txHash"` // the replacement tx
+	From                 string `json:"from"`
+	Nonce                uint64 `json:"nonce"`
+	GasPrice             string `json:"gasPrice,omitempty"`             // in wei, only for legacy tx
+	MaxFeePerGas         string `json:"maxFeePerGas,omitempty"`         // in wei, only for eip1559 tx
+	MaxPriorityFeePerGas string `json:"maxPriorityFeePerGas,omitempty"` // in wei, only for eip1559 tx
+	Broadcasted          bool   `json:"broadcasted"`                    // false if --dry-run
+	Status               string `json:"status,omitempty"`               // replaced | original-mined | other-mined | pending (--not-check)
+	MinedTxHash          string `json:"minedTxHash,omitempty"`          // the tx mined at the nonce
+	BlockNumber          string `json:"blockNumber,omitempty"`
+}
+
+// replacePendingTx replaces the pending tx by a tx with same nonce and bumped fees.
+// If cancel is true, the replacement is a 0-value self transfer, otherwise it's the original tx.
+func replacePendingTx(txHashStr string, cancel bool) {
+	if !hasSigner() {
+		log.Fatalf("%s is required for this command", signerOptions)
+	}
+	if txReplaceBumpPercent < minReplaceBumpPercent {
+		log.Printf("warning: --bump-percent %d is less than %d, the replacement may be rejected by node", txReplaceBumpPercent, minReplaceBumpPercent)
+	}
+	InitGlobalClient(globalOptNodeUrl)
+	ctx := context.Background()
+
+	origTxHash := common.HexToHash(txHashStr)
+	origTx, isPending, err := globalClient.EthClient.TransactionByHash(ctx, origTxHash)
+	if err != nil {
+		log.Fatalf("TransactionByHash fail: %v", err)
+	}
+	if !isPending {
+		log.Fatalf("tx %s is already mined, it can not be replaced", origTxHash.Hex())
+	}
+
+	chainId := origTx.ChainId()
+	if !origTx.Protected() {
+		chainId, _ = new(big.Int).SetString(globalChainId, 10)
+	}
+	from, err := types.Sender(types.LatestSignerForChainID(chainId), origTx)
+	checkErr(err)
+
+	signer := loadSigner()
+	if signer.Address() != from {
+		log.Fatalf("the signer is %s, but tx %s is from %s", signer.Address().Hex(), origTxHash.Hex(), from.Hex())
+	}
+
+	gasPrice, maxFeePerGas, maxPriorityFeePerGas, err := currentNetworkFees(globalClient.EthClient, origTx)
+	checkErr(err)
+	newTx, err := buildReplacementTx(origTx, from, cancel, txReplaceBumpPercent, gasPrice, maxFeePerGas, maxPriorityFeePerGas)
+	checkErr(err)
+
+	printTxSummary(os.Stderr, newTxFile(newTx, chainId, from), GetFuncSig)
+	signedTx, err := signer.SignTx(newTx, chainId)
+	checkErr(err)
+
+	var out = &ReplaceTxOutput{
+		OriginalTxHash: origTxHash.Hex(),
+		TxHash:         signedTx.Hash().Hex(),
+		From:           from.Hex(),
+		Nonce:          signedTx.Nonce(),
+	}
+	if signedTx.Type() == types.DynamicFeeTxType {
+		out.MaxFeePerGas = signedTx.GasFeeCap().String()
+		out.MaxPriorityFeePerGas = signedTx.GasTipCap().String()
+	} else {
+		out.GasPrice = signedTx.GasPrice().String()
+	}
+
+	if globalOptShowRawTx {
+		rawTx, err := GenRawTx(signedTx)
+		checkErr(err)
+		log.Printf("raw tx = %v", rawTx)
+	}
+
+	if !globalOptDryRun {
+		rpcReturnTx, err := SendSignedTx(globalClient.RpcClient, signedTx)
+		if err != nil {
+			log.Fatalf("SendSignedTx fail: %v", err)
+		}
+		out.Broadcasted = true
+		log.Printf("replacement tx %s is broadcasted", rpcReturnTx.Hex())
+
+		if txReplaceNotCheck {
+			out.Status = "pending"
+		} else {
+			receipt, err := waitNonceMined(globalClient.EthClient, from, signedTx.Nonce(), []common.Hash{signedTx.Hash(), origTxHash})
+			checkErr(err)
+			if receipt == nil {
+				out.Status = "other-mined"
+				log.Printf("nonce %d is used by another tx", signedTx.Nonce())
+			} else {
+				out.MinedTxHash = receipt.TxHash.Hex()
+				out.BlockNumber = receipt.BlockNumber.String()
+				if receipt.TxHash == signedTx.Hash() {
+					out.Status = "replaced"
+					log.Printf("replacement tx %s is mined in block %v", receipt.TxHash.Hex(), receipt.BlockNumber)
+				} else {
+					out.Status = "original-mined"
+					log.Printf("original tx %s is mined in block %v, the replacement is dropped", receipt.TxHash.Hex(), receipt.BlockNumber)
+				}
+				if receipt.Status != types.ReceiptStatusSuccessful {
+					log.Printf("warning: status of tx %s is failed", receipt.TxHash.Hex())
+				}
+				if !globalOptTerseOutput {
+					printTxExplorerUrl(receipt.TxHash.Hex())
+				}
+			}
+		}
+	}
+
+	if isJsonOutput() {
+		printJson(out)
+		return
+	}
+	fmt.Printf("replacement tx: %s\n", out.TxHash)
+	if out.MinedTxHash != "" {
+		fmt.Printf("mined tx: %s (%s)\n", out.MinedTxHash, out.Status)
+	}
+}
+
+// currentNetworkFees returns the fees of current network, --gas-price, --max-fee-per-gas and --max-priority-fee-per-gas are respected
+func currentNetworkFees(client *ethclient.Client, origTx *types.Transaction) (gasPrice, maxFeePerGas, maxPriorityFeePerGas *big.Int, err error) {
+	if origTx.Type() == types.DynamicFeeTxType {
+		maxFeePerGas, maxPriorityFeePerGas, err = getEIP1559Fees(client)
+		return nil, maxFeePerGas, maxPriorityFeePerGas, err
+	}
+	gasPrice, err = getGasPrice(client)
+	return gasPrice, nil, nil, err
+}
+
+// bumpFee returns the larger one of fee * (100 + percent) / 100 (rounded up) and minFee
+func bumpFee(fee *big.Int, percent uint64, minFee *big.Int) *big.Int {
+	bumped := new(big.Int).Mul(fee, new(big.Int).SetUint64(100+percent))
+	bumped.Add(bumped, big.NewInt(99))
+	bumped.Div(bumped, big.NewInt(100))
+	if minFee != nil && minFee.Cmp(bumped) > 0 {
+		return new(big.Int).Set(minFee)
+	}
+	return bumped
+}
+
+// buildReplacementTx builds the unsigned replacement of origTx, its fees are bumped by bumpPercent at least,
+// and not less than the current network fees (gasPrice for legacy tx, maxFeePerGas and maxPriorityFeePerGas for eip1559 tx)
+func buildReplacementTx(origTx *types.Transaction, from common.Address, cancel bool, bumpPercent uint64,
+	gasPrice, maxFeePerGas, maxPriorityFeePerGas *big.Int,
+) (*types.Transaction, error) {
+	var to = origTx.To()
+	var value = origTx.Value()
+	var data = origTx.Data()
+	var gas = origTx.Gas()
+	if cancel {
+		to = &from
+		value = big.NewInt(0)
+		data = nil
+		gas = gasUsedByTransferEth
+		if globalOptGasLimit > 0 {
+			gas = globalOptGasLimit
+		}
+	}
+
+	switch origTx.Type() {
+	case types.LegacyTxType:
+		return types.NewTx(&types.LegacyTx{
+			Nonce:    origTx.Nonce(),
+			To:       to,
+			Value:    value,
+			Gas:      gas,
+			GasPrice: bumpFee(origTx.GasPrice(), bumpPercent, gasPrice),
+			Data:     data,
+		}), nil
+	case types.AccessListTxType:
+		return types.NewTx(&types.AccessListTx{
+			ChainID:    origTx.ChainId(),
+			Nonce:      origTx.Nonce(),
+			To:         to,
+			Value:      value,
+			Gas:        gas,
+			GasPrice:   bumpFee(origTx.GasPrice(), bumpPercent, gasPrice),
+			Data:       data,
+			AccessList: origTx.AccessList(),
+		}), nil
+	case types.DynamicFeeTxType:
+		tip := bumpFee(origTx.GasTipCap(), bumpPercent, maxPriorityFeePerGas)
+		feeCap := bumpFee(origTx.GasFeeCap(), bumpPercent, maxFeePerGas)
+		if feeCap.Cmp(tip) < 0 {
+			feeCap = new(big.Int).Set(tip)
+		}
+		var accessList types.AccessList
+		if !cancel {
+			accessList = origTx.AccessList()
+		}
+		return types.NewTx(&types.DynamicFeeTx{
+			ChainID:    origTx.ChainId(),
+			Nonce:      origTx.Nonce(),
+			To:         to,
+			Value:      value,
+			Gas:        gas,
+			GasTipCap:  tip,
+			GasFeeCap:  feeCap,
+			Data:       data,
+			AccessList: accessList,
+		}), nil
+	default:
+		return nil, fmt.Errorf("tx type %d is not supported", origTx.Type())
+	}
+}
+
+// waitNonceMined waits until a tx with nonce of from is mined, the receipt is returned if it's one of txHashes.
+// nil receipt means the nonce is used by another tx.
+func waitNonceMined(client *ethclient.Client, from common.Address, nonce uint64, txHashes []common.Hash) (*types.Receipt, error) {
+	for {
+		for _, txHash := range txHashes {
+			receipt, err := client.TransactionReceipt(context.Background(), txHash)
+			if err == nil {
+				return receipt, nil
+			}
+			if !errors.Is(err, ethereum.NotFound) {
+				return nil, fmt.Errorf("TransactionReceipt fail: %w", err)
+			}
+		}
+
+		minedNonce, err := client.NonceAt(context.Background(), from, nil)
+		if err != nil {
+			return nil, fmt.Errorf("NonceAt fail: %w", err)
+		}
+		if minedNonce > nonce {
+			// The receipt may be not available immediately, check again before giving up
+			for _, txHash := range txHashes {
+				if receipt, err := client.TransactionReceipt(context.Background(), txHash); err == nil {
+					return receipt, nil
+				}
+			}
+			return nil, nil
+		}
+
+		log.Printf("nonce %d of %s is not mined, re-check after 5 seconds", nonce, from.Hex())
+		time.Sleep(time.Second * 5)
+	}
+}
@@ -0,0 +1,75 @@
+package cmd
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestBumpFee(t *testing.T) {
+	tests := []struct {
+		fee      int64
+		percent  uint64
+		minFee   *big.Int
+		expected int64
+	}{
+		{1000000000, 10, nil, 1100000000},
+		{1000000001, 10, nil, 1100000002}, // rounded up
+		{1000000000, 10, big.NewInt(3000000000), 3000000000},
+		{1000000000, 25, big.NewInt(1000), 1250000000},
+		{0, 10, nil, 0},
+	}
+
+	for i, tc := range tests {
+		got := bumpFee(big.NewInt(tc.fee), tc.percent, tc.minFee)
+		if got.Int64() != tc.expected {
+			t.Fatalf("test %d: expected: %v, got: %v", i+1, tc.expected, got)
+		}
+	}
+}
+
+func TestBuildReplacementTx(t *testing.T) {
+	from := common.HexToAddress("0x4f9c5b2efd6136Ef4632a09572ec7cAc430BaBBD")
+	to := common.HexToAddress("0xdAC17F958D2ee523a2206206994597C13D831ec7")
+	chainId := big.NewInt(1)
+	data := common.FromHex("0xa9059cbb0000000000000000000000008f36975cdea2e6e64f85719788c8efbbe89dfbbb00000000000000000000000000000000000000000000000000000000000f4240")
+
+	legacyTx := types.NewTx(&types.LegacyTx{Nonce: 7, To: &to, Value: big.NewInt(0), Gas: 60000, GasPrice: big.NewInt(2e9), Data: data})
+	dynamicTx := types.NewTx(&types.DynamicFeeTx{ChainID: chainId, Nonce: 8, To: &to, Value: big.NewInt(0), Gas: 60000, GasTipCap: big.NewInt(1e9), GasFeeCap: big.NewInt(30e9), Data: data})
+
+	tests := []struct {
+		origTx          *types.Transaction
+		cancel          bool
+		gasPrice        *big.Int
+		maxFeePerGas    *big.Int
+		maxPriorityFee  *big.Int
+		expectedTo      common.Address
+		expectedGas     uint64
+		expectedPrice   int64 // gas price or max fee per gas
+		expectedTipCap  int64
+		expectedDataLen int
+	}{
+		{legacyTx, false, big.NewInt(1e9), nil, nil, to, 60000, 2.2e9, 2.2e9, len(data)},
+		{legacyTx, true, big.NewInt(5e9), nil, nil, from, 21000, 5e9, 5e9, 0},
+		{dynamicTx, false, nil, big.NewInt(20e9), big.NewInt(2e9), to, 60000, 33e9, 2e9, len(data)},
+		{dynamicTx, true, nil, big.NewInt(40e9), big.NewInt(5e8), from, 21000, 40e9, 1.1e9, 0},
+	}
+
+	for i, tc := range tests {
+		tx, err := buildReplacementTx(tc.origTx, from, tc.cancel, 10, tc.gasPrice, tc.maxFeePerGas, tc.maxPriorityFee)
+		if err != nil {
+			t.Fatalf("test %d: buildReplacementTx failed: %v", i+1, err)
+		}
+		if tx.Type() != tc.origTx.Type() || tx.Nonce() != tc.origTx.Nonce() {
+			t.Fatalf("test %d: expected: type %v nonce %v, got: type %v nonce %v", i+1, tc.origTx.Type(), tc.origTx.Nonce(), tx.Type(), tx.Nonce())
+		}
+		if *tx.To() != tc.expectedTo || tx.Gas() != tc.expectedGas || len(tx.Data()) != tc.expectedDataLen {
+			t.Fatalf("test %d: expected: %v %v %v, got: %v %v %v", i+1, tc.expectedTo, tc.expectedGas, tc.expectedDataLen, tx.To(), tx.Gas(), len(tx.Data()))
+		}
+		if tx.GasFeeCap().Int64() != tc.expectedPrice || tx.GasTipCap().Int64() != tc.expectedTipCap {
+			t.Fatalf("test %d: expected: %v %v, got: %v %v", i+1, tc.expectedPrice, tc.expectedTipCap, tx.GasFeeCap(), tx.GasTipCap())
+		}
+	}
+}
@@ -0,0 +1,337 @@
+package cmd
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/spf13/cobra"
+)
+
+const verifyCodeFormatSingleFile = "solidity-single-file"
+const verifyCodeFormatStandardJson = "solidity-standard-json-input"
+
+// verifyPollInterval is the interval of polling checkverifystatus, and of retrying submission if the contract is
+// not indexed by block explorer yet
+var verifyPollInterval = 5 * time.Second
+
+const verifyMaxPolls = 30
+
+var verifySrcFile string
+var verifyStandardJsonFile string
+var verifyContractName string
+var verifyCompilerVersion string
+var verifyConstructorArgs string
+
+func init() {
+	verifyCmd.Flags().StringVarP(&verifySrcFile, "src-file", "", "", "the path of flattened source file of contract")
+	verifyCmd.Flags().StringVarP(&verifyStandardJsonFile, "standard-json", "", "", "the path of solc standard json input file, the compiler options override its settings")
+	verifyCmd.Flags().StringVarP(&verifyContractName, "contract-name", "", "", "the contract name, format: Name for --src-file, file.sol:Name for --standard-json")
+	verifyCmd.Flags().StringVarP(&verifyCompilerVersion, "compiler-version", "", "", "the compiler version, e.g. v0.8.28+commit.7893614a. If it's not specified, the version of --solc (or solcjs) is used")
+	verifyCmd.Flags().StringVarP(&verifyConstructorArgs, "constructor-args", "", "", "the abi encoded constructor args in hex, e.g. output of encode-param")
+	addCompilerFlags(verifyCmd)
+}
+
+// VerifyOutput is the json output (--output json) of verify command
+type VerifyOutput struct {
+	Address string `json:"address"`
+	Guid    string `json:"guid,omitempty"`
+	Status  string `json:"status"` // verified | already-verified | failed (only for deploy --verify)
+	Message string `json:"message"`
+}
+
+// verifyRequest is the parameters of verifysourcecode api of Etherscan-style block explorer
+type verifyRequest struct {
+	address         string
+	codeFormat      string // solidity-single-file | solidity-standard-json-input
+	sourceCode      string
+	contractName    string // Name for single file, file.sol:Name for standard json input
+	compilerVersion string
+	optimize        bool   // only for single file
+	optimizeRuns    int    // only for single file
+	evmVersion      string // only for single file
+	constructorArgs []byte
+}
+
+var verifyCmd = &cobra.Command{
+	Use:   "verify <address>",
+	Short: "Verify source code of contract on block explorer, eg. etherscan",
+	Args: func(cmd *cobra.Command, args []string) error {
+		if len(args) != 1 {
+			return fmt.Errorf("requires address")
+		}
+		if !isValidEthAddress(args[0]) {
+			return fmt.Errorf("%v is not a valid eth address", args[0])
+		}
+		return nil
+	},
+	Run: func(cmd *cobra.Command, args []string) {
+		if (verifySrcFile == "") == (verifyStandardJsonFile == "") {
+			log.Fatalf("must specify one of --src-file and --standard-json")
+		}
+		if verifyContractName == "" {
+			log.Fatalf("--contract-name is required")
+		}
+		constructorArgs, err := hexutil.Decode("0x" + remove0xPrefix(verifyConstructorArgs))
+		if err != nil {
+			log.Fatalf("--constructor-args must be hex string")
+		}
+
+		var req = verifyRequest{
+			address:         args[0],
+			contractName:    verifyContractName,
+			compilerVersion: verifyCompilerVersion,
+			constructorArgs: constructorArgs,
+		}
+		if req.compilerVersion == "" {
+			req.compilerVersion, err = solcVersion(compilerSolc)
+			checkErr(err)
+			log.Printf("compiler version %v", req.compilerVersion)
+		}
+
+		if verifySrcFile != "" {
+			if cmd.Flags().Changed("via-ir") || cmd.Flags().Changed("remapping") {
+				log.Fatalf("--via-ir and --remapping are not supported by flattened source, use --standard-json")
+			}
+			content, err := os.ReadFile(verifySrcFile)
+			checkErr(err)
+			req.codeFormat = verifyCodeFormatSingleFile
+			req.sourceCode = string(content)
+			req.optimize = compilerOptimize || cmd.Flags().Changed("optimize-runs")
+			req.optimizeRuns = compilerOptimizeRuns
+			req.evmVersion = compilerEvmVersion
+		} else {
+			if !strings.Contains(verifyContractName, ":") {
+				log.Fatalf("--contract-name must be file.sol:Name for --standard-json")
+			}
+			input, err := loadStandardJsonInput(verifyStandardJsonFile, compilerSettings(cmd))
+			checkErr(err)
+			req.codeFormat = verifyCodeFormatStandardJson
+			req.sourceCode = string(input)
+		}
+
+		out, err := verifyContract(req)
+		checkErr(err)
+		printVerifyOutput(out)
+	},
+}
+
+// printVerifyOutput prints the result of verification
+func printVerifyOutput(out *VerifyOutput) {
+	if isJsonOutput() {
+		printJson(out)
+		return
+	}
+	log.Printf("contract %v %s: %s", out.Address, out.Status, out.Message)
+}
+
+// verifyContract submits source code to block explorer and waits for the result of verification
+func verifyContract(req verifyRequest) (*VerifyOutput, error) {
+	var out = &VerifyOutput{Address: req.address}
+
+	var guid string
+	for i := 0; ; i++ {
+		status, result, err := submitVerification(req)
+		if err != nil {
+			return nil, err
+		}
+		if status == "1" {
+			guid = result
+			break
+		}
+		if strings.Contains(strings.ToLower(result), "already verified") {
+			out.Status = "already-verified"
+			out.Message = result
+			return out, nil
+		}
+		// the contract deployed just now may be not indexed by block explorer yet
+		if !strings.Contains(result, "Unable to locate ContractCode") || i >= verifyMaxPolls {
+			return nil, fmt.Errorf("submit source code to block explorer fail: %s", result)
+		}
+		log.Printf("contract %v is not found by block explorer, retry later", req.address)
+		time.Sleep(verifyPollInterval)
+	}
+	log.Printf("source code submitted, guid %v", guid)
+	out.Guid = guid
+
+	for i := 0; i < verifyMaxPolls; i++ {
+		time.Sleep(verifyPollInterval)
+		status, result, err := checkVerifyStatus(guid)
+		if err != nil {
+			return nil, err
+		}
+		if strings.Contains(strings.ToLower(result), "pending") {
+			log.Printf("verification is pending, check it later")
+			continue
+		}
+		if strings.Contains(strings.ToLower(result), "already verified") {
+			out.Status = "already-verified"
+			out.Message = result
+			return out, nil
+		}
+		if status != "1" {
+			return nil, fmt.Errorf("verify contract %s fail: %s", req.address, result)
+		}
+		out.Status = "verified"
+		out.Message = result
+		return out, nil
+	}
+	return nil, fmt.Errorf("verification of contract %s is still pending, check it by guid %s later", req.address, guid)
+}
+
+// verifyApiResponse is the response of verifysourcecode and checkverifystatus api
+type verifyApiResponse struct {
+	Status  string `json:"status"`
+	Message string `json:"message"`
+	Result  string `json:"result"` // the guid of submission, or the status of verification, or error message
+}
+
+// submitVerification submits source code by verifysourcecode api, returns the status and result of response
+func submitVerification(req verifyRequest) (string, string, error) {
+	requestUrl, err := explorerApiUrl(url.Values{
+		"module": {"contract"},
+		"action": {"verifysourcecode"},
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	var form = url.Values{
+		"contractaddress":       {req.address},
+		"sourceCode":            {req.sourceCode},
+		"codeformat":            {req.codeFormat},
+		"contractname":          {req.contractName},
+		"compilerversion":       {req.compilerVersion},
+		"constructorArguements": {hexutil.Encode(req.constructorArgs)[2:]}, // the misspelling is required by the api
+	}
+	if req.codeFormat == verifyCodeFormatSingleFile {
+		if req.optimize {
+			form.Set("optimizationUsed", "1")
+			form.Set("runs", fmt.Sprint(req.optimizeRuns))
+		} else {
+			form.Set("optimizationUsed", "0")
+		}
+		if req.evmVersion != "" {
+			form.Set("evmversion", req.evmVersion)
+		}
+	}
+
+	resp, err := http.PostForm(requestUrl, form)
+	if err != nil {
+		return "", "", err
+	}
+	return parseVerifyApiResponse(resp)
+}
+
+// checkVerifyStatus checks the status of verification by checkverifystatus api
+func checkVerifyStatus(guid string) (string, string, error) {
+	requestUrl, err := explorerApiUrl(url.Values{
+		"module": {"contract"},
+		"action": {"checkverifystatus"},
+		"guid":   {guid},
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	resp, err := http.Get(requestUrl)
+	if err != nil {
+		return "", "", err
+	}
+	return parseVerifyApiResponse(resp)
+}
+
+func parseVerifyApiResponse(resp *http.Response) (string, string, error) {
+	defer resp.Body.Close()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", "", err
+	}
+	var data verifyApiResponse
+	if err := json.Unmarshal(body, &data); err != nil {
+		return "", "", fmt.Errorf("unexpected response of block explorer: %s", body)
+	}
+	return data.Status, data.Result, nil
+}
+
+var solcVersionRegex = regexp.MustCompile(`\d+\.\d+\.\d+\+commit\.[0-9a-f]+`)
+
+// solcVersion returns the version of native solc, or solcjs if solc is empty, in the format of block explorer,
+// e.g. v0.8.28+commit.7893614a
+func solcVersion(solc string) (string, error) {
+	var compiler = solc
+	if compiler == "" {
+		compiler = "solcjs"
+	}
+	var stdout bytes.Buffer
+	cmd := exec.Command(compiler, "--version")
+	cmd.Stdout = &stdout
+	if err := cmd.Run(); err != nil {
+		return "", fmt.Errorf("get version of %s fail: %w", compiler, err)
+	}
+	return parseSolcVersion(stdout.String())
+}
+
+// parseSolcVersion parses the output of solc --version or solcjs --version
+func parseSolcVersion(output string) (string, error) {
+	version := solcVersionRegex.FindString(output)
+	if version == "" {
+		return "", fmt.Errorf("unknown compiler version: %s", strings.TrimSpace(output))
+	}
+	return "v" + version, nil
+}
+
+// verificationInput returns the standard json input for verification, the sources loaded by compiler from file
+// system (the imports) are added, because block explorer can only access the sources in input. The libraries linked
+// after compilation are added to settings.
+func verificationInput(input []byte, output *solcOutput, libraries map[string]map[string]string) ([]byte, error) {
+	var inputJson map[string]interface{}
+	if err := json.Unmarshal(input, &inputJson); err != nil {
+		return nil, err
+	}
+	if len(libraries) > 0 {
+		settings, ok := inputJson["settings"].(map[string]interface{})
+		if !ok {
+			settings = make(map[string]interface{})
+			inputJson["settings"] = settings
+		}
+		settings["libraries"] = libraries
+	}
+	sources, ok := inputJson["sources"].(map[string]interface{})
+	if !ok {
+		sources = make(map[string]interface{})
+		inputJson["sources"] = sources
+	}
+	for name := range output.Sources {
+		if source, ok := sources[name].(map[string]interface{}); ok && source["content"] != nil {
+			continue
+		}
+		content, err := readSourceUnit(name)
+		if err != nil {
+			return nil, err
+		}
+		sources[name] = map[string]interface{}{"content": string(content)}
+	}
+	return json.Marshal(inputJson)
+}
+
+// readSourceUnit reads the source by its source unit name, it's resolved as compileStandardJson does
+func readSourceUnit(name string) ([]byte, error) {
+	for _, dir := range []string{".", "node_modules"} {
+		if content, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(name))); err == nil {
+			return content, nil
+		}
+	}
+	return nil, fmt.Errorf("source %s is not found in current directory and ./node_modules", name)
+}
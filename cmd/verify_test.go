@@ -0,0 +1,181 @@
+package cmd
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// stubExplorer is a local stand-in of verifysourcecode and checkverifystatus api of Etherscan-style block explorer
+type stubExplorer struct {
+	t            *testing.T
+	submitResult verifyApiResponse
+	statuses     []verifyApiResponse // the responses of checkverifystatus in order
+	form         map[string]string   // the form of the last submission
+}
+
+func (s *stubExplorer) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	var resp verifyApiResponse
+	switch r.URL.Query().Get("action") {
+	case "verifysourcecode":
+		if r.Method != http.MethodPost {
+			s.t.Fatalf("expected: POST, got: %v", r.Method)
+		}
+		if err := r.ParseForm(); err != nil {
+			s.t.Fatalf("ParseForm failed: %v", err)
+		}
+		s.form = make(map[string]string)
+		for key := range r.PostForm {
+			s.form[key] = r.PostForm.Get(key)
+		}
+		resp = s.submitResult
+	case "checkverifystatus":
+		if r.URL.Query().Get("guid") != "guid-1" || len(s.statuses) == 0 {
+			resp = verifyApiResponse{Status: "0", Message: "NOTOK", Result: "Unknown UID"}
+		} else {
+			resp, s.statuses = s.statuses[0], s.statuses[1:]
+		}
+	}
+	_ = json.NewEncoder(w).Encode(resp)
+}
+
+func TestVerifyContract(t *testing.T) {
+	savedProfile := globalChainProfile
+	savedInterval := verifyPollInterval
+	defer func() {
+		globalChainProfile = savedProfile
+		verifyPollInterval = savedInterval
+	}()
+	verifyPollInterval = time.Millisecond
+
+	submitted := verifyApiResponse{Status: "1", Message: "OK", Result: "guid-1"}
+	pending := verifyApiResponse{Status: "0", Message: "NOTOK", Result: "Pending in queue"}
+
+	tests := []struct {
+		submitResult verifyApiResponse
+		statuses     []verifyApiResponse
+		expected     *VerifyOutput // nil if error is expected
+	}{
+		{submitted, []verifyApiResponse{pending, pending, {Status: "1", Message: "OK", Result: "Pass - Verified"}},
+			&VerifyOutput{Address: "0x8F36975cdeA2e6E64f85719788C8EFBBe89DFBbb", Guid: "guid-1", Status: "verified", Message: "Pass - Verified"}},
+		{submitted, []verifyApiResponse{{Status: "0", Message: "NOTOK", Result: "Already Verified"}},
+			&VerifyOutput{Address: "0x8F36975cdeA2e6E64f85719788C8EFBBe89DFBbb", Guid: "guid-1", Status: "already-verified", Message: "Already Verified"}},
+		{verifyApiResponse{Status: "0", Message: "NOTOK", Result: "Contract source code already verified"}, nil,
+			&VerifyOutput{Address: "0x8F36975cdeA2e6E64f85719788C8EFBBe89DFBbb", Status: "already-verified", Message: "Contract source code already verified"}},
+		{submitted, []verifyApiResponse{pending, {Status: "0", Message: "NOTOK", Result: "Fail - Unable to verify"}}, nil},
+		{verifyApiResponse{Status: "0", Message: "NOTOK", Result: "Invalid constructor arguments provided"}, nil, nil},
+	}
+
+	for i, tc := range tests {
+		stub := &stubExplorer{t: t, submitResult: tc.submitResult, statuses: tc.statuses}
+		server := httptest.NewServer(stub)
+		globalChainProfile = &ChainProfile{ApiUrl: server.URL + "?chainid=1", ApiKey: "key"}
+
+		got, err := verifyContract(verifyRequest{
+			address:         "0x8F36975cdeA2e6E64f85719788C8EFBBe89DFBbb",
+			codeFormat:      verifyCodeFormatSingleFile,
+			sourceCode:      "contract Counter { constructor(uint256 n) {} }",
+			contractName:    "Counter",
+			compilerVersion: "v0.8.28+commit.7893614a",
+			optimize:        true,
+			optimizeRuns:    1000,
+			constructorArgs: []byte{0x01},
+		})
+		server.Close()
+		if tc.expected == nil {
+			if err == nil {
+				t.Fatalf("test %d: expect error, got: %+v", i+1, got)
+			}
+			continue
+		}
+		if err != nil || !reflect.DeepEqual(got, tc.expected) {
+			t.Fatalf("test %d: expected: %+v, got: %+v (%v)", i+1, tc.expected, got, err)
+		}
+
+		expectedForm := map[string]string{
+			"contractaddress":       "0x8F36975cdeA2e6E64f85719788C8EFBBe89DFBbb",
+			"sourceCode":            "contract Counter { constructor(uint256 n) {} }",
+			"codeformat":            "solidity-single-file",
+			"contractname":          "Counter",
+			"compilerversion":       "v0.8.28+commit.7893614a",
+			"constructorArguements": "01",
+			"optimizationUsed":      "1",
+			"runs":                  "1000",
+		}
+		if !reflect.DeepEqual(stub.form, expectedForm) {
+			t.Fatalf("test %d: expected form: %v, got: %v", i+1, expectedForm, stub.form)
+		}
+	}
+}
+
+func TestVerificationInput(t *testing.T) {
+	t.Chdir(t.TempDir())
+	for name, content := range map[string]string{
+		"contracts/Token.sol":                     `import "@oz/ERC20.sol"; contract Token {}`,
+		"node_modules/@oz/ERC20.sol":              "contract ERC20 {}",
+		"node_modules/@oz/unused/NotImported.sol": "contract NotImported {}",
+	} {
+		if err := os.MkdirAll(filepath.Dir(name), 0755); err != nil {
+			t.Fatalf("MkdirAll failed: %v", err)
+		}
+		if err := os.WriteFile(name, []byte(content), 0644); err != nil {
+			t.Fatalf("WriteFile failed: %v", err)
+		}
+	}
+
+	input, err := buildStandardJsonInput("contracts/Token.sol", solcSettings{})
+	if err != nil {
+		t.Fatalf("buildStandardJsonInput failed: %v", err)
+	}
+	output := &solcOutput{Sources: map[string]struct{}{"contracts/Token.sol": {}, "@oz/ERC20.sol": {}}}
+	libraries := map[string]map[string]string{"contracts/Math.sol": {"Math": "0x8F36975cdeA2e6E64f85719788C8EFBBe89DFBbb"}}
+
+	got, err := verificationInput(input, output, libraries)
+	if err != nil {
+		t.Fatalf("verificationInput failed: %v", err)
+	}
+	var parsed struct {
+		Sources  map[string]map[string]string `json:"sources"`
+		Settings struct {
+			Libraries map[string]map[string]string `json:"libraries"`
+		} `json:"settings"`
+	}
+	if err := json.Unmarshal(got, &parsed); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	expectedSources := map[string]map[string]string{
+		"contracts/Token.sol": {"content": `import "@oz/ERC20.sol"; contract Token {}`},
+		"@oz/ERC20.sol":       {"content": "contract ERC20 {}"},
+	}
+	if !reflect.DeepEqual(parsed.Sources, expectedSources) || !reflect.DeepEqual(parsed.Settings.Libraries, libraries) {
+		t.Fatalf("unexpected verification input: %s", got)
+	}
+
+	output.Sources["@oz/Missing.sol"] = struct{}{}
+	if _, err := verificationInput(input, output, nil); err == nil {
+		t.Fatalf("expect error if source is not found")
+	}
+}
+
+func TestParseSolcVersion(t *testing.T) {
+	tests := []struct {
+		output   string
+		expected string
+	}{
+		{"solc, the solidity compiler commandline interface\nVersion: 0.8.28+commit.7893614a.Linux.g++\n", "v0.8.28+commit.7893614a"},
+		{"0.8.24+commit.e11b9ed9.Emscripten.clang\n", "v0.8.24+commit.e11b9ed9"},
+		{"unknown", ""},
+	}
+
+	for i, tc := range tests {
+		got, err := parseSolcVersion(tc.output)
+		if (tc.expected == "") != (err != nil) || got != tc.expected {
+			t.Fatalf("test %d: expected: %v, got: %v (%v)", i+1, tc.expected, got, err)
+		}
+	}
+}